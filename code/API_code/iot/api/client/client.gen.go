@@ -25,9 +25,8 @@ import (
 	"net/url"
 	"strings"
 
-	"github.com/oapi-codegen/runtime"
-
 	. "github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/oapi-codegen/runtime"
 )
 
 // RequestEditorFn  is the function signature for the RequestEditor callback function
@@ -108,6 +107,12 @@ type ClientInterface interface {
 
 	ActivatePowerSaving(ctx context.Context, params *ActivatePowerSavingParams, body ActivatePowerSavingJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
 
+	// ListPowerSaving request
+	ListPowerSaving(ctx context.Context, params *ListPowerSavingParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CancelPowerSaving request
+	CancelPowerSaving(ctx context.Context, transactionId TransactionId, params *CancelPowerSavingParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// GetPowerSaving request
 	GetPowerSaving(ctx context.Context, transactionId TransactionId, params *GetPowerSavingParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 }
@@ -136,6 +141,30 @@ func (c *Client) ActivatePowerSaving(ctx context.Context, params *ActivatePowerS
 	return c.Client.Do(req)
 }
 
+func (c *Client) ListPowerSaving(ctx context.Context, params *ListPowerSavingParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListPowerSavingRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CancelPowerSaving(ctx context.Context, transactionId TransactionId, params *CancelPowerSavingParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCancelPowerSavingRequest(c.Server, transactionId, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
 func (c *Client) GetPowerSaving(ctx context.Context, transactionId TransactionId, params *GetPowerSavingParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
 	req, err := NewGetPowerSavingRequest(c.Server, transactionId, params)
 	if err != nil {
@@ -185,6 +214,290 @@ func NewActivatePowerSavingRequestWithBody(server string, params *ActivatePowerS
 
 	req.Header.Add("Content-Type", contentType)
 
+	if params != nil {
+
+		if params.XCorrelator != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "x-correlator", runtime.ParamLocationHeader, *params.XCorrelator)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("x-correlator", headerParam0)
+		}
+
+		if params.IdempotencyKey != nil {
+			var headerParam1 string
+
+			headerParam1, err = runtime.StyleParamWithLocation("simple", false, "Idempotency-Key", runtime.ParamLocationHeader, *params.IdempotencyKey)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Idempotency-Key", headerParam1)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewListPowerSavingRequest generates requests for ListPowerSaving
+func NewListPowerSavingRequest(server string, params *ListPowerSavingParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/features/power-saving/transactions")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Status != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "status", runtime.ParamLocationQuery, *params.Status); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.DeviceId != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "deviceId", runtime.ParamLocationQuery, *params.DeviceId); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.ParentTransactionId != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "parentTransactionId", runtime.ParamLocationQuery, *params.ParentTransactionId); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.CreatedAfter != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "createdAfter", runtime.ParamLocationQuery, *params.CreatedAfter); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.CreatedBefore != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "createdBefore", runtime.ParamLocationQuery, *params.CreatedBefore); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.StartAfter != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startAfter", runtime.ParamLocationQuery, *params.StartAfter); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.StartBefore != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startBefore", runtime.ParamLocationQuery, *params.StartBefore); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.EndAfter != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endAfter", runtime.ParamLocationQuery, *params.EndAfter); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.EndBefore != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endBefore", runtime.ParamLocationQuery, *params.EndBefore); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Cursor != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cursor", runtime.ParamLocationQuery, *params.Cursor); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+
+		if params.XCorrelator != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "x-correlator", runtime.ParamLocationHeader, *params.XCorrelator)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("x-correlator", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewCancelPowerSavingRequest generates requests for CancelPowerSaving
+func NewCancelPowerSavingRequest(server string, transactionId TransactionId, params *CancelPowerSavingParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "transactionId", runtime.ParamLocationPath, transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/features/power-saving/transactions/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
 	if params != nil {
 
 		if params.XCorrelator != nil {
@@ -300,6 +613,12 @@ type ClientWithResponsesInterface interface {
 
 	ActivatePowerSavingWithResponse(ctx context.Context, params *ActivatePowerSavingParams, body ActivatePowerSavingJSONRequestBody, reqEditors ...RequestEditorFn) (*ActivatePowerSavingResponse, error)
 
+	// ListPowerSavingWithResponse request
+	ListPowerSavingWithResponse(ctx context.Context, params *ListPowerSavingParams, reqEditors ...RequestEditorFn) (*ListPowerSavingResponse, error)
+
+	// CancelPowerSavingWithResponse request
+	CancelPowerSavingWithResponse(ctx context.Context, transactionId TransactionId, params *CancelPowerSavingParams, reqEditors ...RequestEditorFn) (*CancelPowerSavingResponse, error)
+
 	// GetPowerSavingWithResponse request
 	GetPowerSavingWithResponse(ctx context.Context, transactionId TransactionId, params *GetPowerSavingParams, reqEditors ...RequestEditorFn) (*GetPowerSavingResponse, error)
 }
@@ -313,6 +632,7 @@ type ActivatePowerSavingResponse struct {
 	JSON403      *Generic403
 	JSON404      *Generic404
 	JSON409      *Generic409
+	JSON422      *Generic422
 	JSON501      *Generic501
 }
 
@@ -332,6 +652,60 @@ func (r ActivatePowerSavingResponse) StatusCode() int {
 	return 0
 }
 
+type ListPowerSavingResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *TransactionList
+	JSON400      *Generic400
+	JSON401      *Generic401
+	JSON403      *Generic403
+	JSON501      *Generic501
+}
+
+// Status returns HTTPResponse.Status
+func (r ListPowerSavingResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListPowerSavingResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CancelPowerSavingResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON202      *PowerSavingResponse
+	JSON400      *Generic400
+	JSON401      *Generic401
+	JSON403      *Generic403
+	JSON404      *Generic404
+	JSON409      *Generic409
+	JSON501      *Generic501
+}
+
+// Status returns HTTPResponse.Status
+func (r CancelPowerSavingResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CancelPowerSavingResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
 type GetPowerSavingResponse struct {
 	Body         []byte
 	HTTPResponse *http.Response
@@ -376,6 +750,24 @@ func (c *ClientWithResponses) ActivatePowerSavingWithResponse(ctx context.Contex
 	return ParseActivatePowerSavingResponse(rsp)
 }
 
+// ListPowerSavingWithResponse request returning *ListPowerSavingResponse
+func (c *ClientWithResponses) ListPowerSavingWithResponse(ctx context.Context, params *ListPowerSavingParams, reqEditors ...RequestEditorFn) (*ListPowerSavingResponse, error) {
+	rsp, err := c.ListPowerSaving(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListPowerSavingResponse(rsp)
+}
+
+// CancelPowerSavingWithResponse request returning *CancelPowerSavingResponse
+func (c *ClientWithResponses) CancelPowerSavingWithResponse(ctx context.Context, transactionId TransactionId, params *CancelPowerSavingParams, reqEditors ...RequestEditorFn) (*CancelPowerSavingResponse, error) {
+	rsp, err := c.CancelPowerSaving(ctx, transactionId, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCancelPowerSavingResponse(rsp)
+}
+
 // GetPowerSavingWithResponse request returning *GetPowerSavingResponse
 func (c *ClientWithResponses) GetPowerSavingWithResponse(ctx context.Context, transactionId TransactionId, params *GetPowerSavingParams, reqEditors ...RequestEditorFn) (*GetPowerSavingResponse, error) {
 	rsp, err := c.GetPowerSaving(ctx, transactionId, params, reqEditors...)
@@ -398,6 +790,135 @@ func ParseActivatePowerSavingResponse(rsp *http.Response) (*ActivatePowerSavingR
 		HTTPResponse: rsp,
 	}
 
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest PowerSavingResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Generic400
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Generic401
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 403:
+		var dest Generic403
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON403 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest Generic404
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 409:
+		var dest Generic409
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON409 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 422:
+		var dest Generic422
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON422 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 501:
+		var dest Generic501
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON501 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListPowerSavingResponse parses an HTTP response from a ListPowerSavingWithResponse call
+func ParseListPowerSavingResponse(rsp *http.Response) (*ListPowerSavingResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListPowerSavingResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest TransactionList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Generic400
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Generic401
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 403:
+		var dest Generic403
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON403 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 501:
+		var dest Generic501
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON501 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCancelPowerSavingResponse parses an HTTP response from a CancelPowerSavingWithResponse call
+func ParseCancelPowerSavingResponse(rsp *http.Response) (*CancelPowerSavingResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CancelPowerSavingResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
 		var dest PowerSavingResponse
@@ -50,14 +50,35 @@ const (
 	N10 CloudEventSpecversion = "1.0"
 )
 
+// Defines values for DeviceErrorCode.
+const (
+	ErrorCodeActionTimeout        DeviceErrorCode = "ACTION_TIMEOUT"
+	ErrorCodeBackendError         DeviceErrorCode = "BACKEND_ERROR"
+	ErrorCodeBackendUnavailable   DeviceErrorCode = "BACKEND_UNAVAILABLE"
+	ErrorCodeDeviceNotFound       DeviceErrorCode = "DEVICE_NOT_FOUND"
+	ErrorCodeDriftDetected        DeviceErrorCode = "DRIFT_DETECTED"
+	ErrorCodeInternal             DeviceErrorCode = "INTERNAL"
+	ErrorCodeOriginalStateMissing DeviceErrorCode = "ORIGINAL_STATE_MISSING"
+	ErrorCodeProfileNotFound      DeviceErrorCode = "PROFILE_NOT_FOUND"
+	ErrorCodeVerificationFailed   DeviceErrorCode = "VERIFICATION_FAILED"
+)
+
 // Defines values for DeviceStatusStatus.
 const (
+	Cancelled  DeviceStatusStatus = "cancelled"
 	Failed     DeviceStatusStatus = "failed"
 	InProgress DeviceStatusStatus = "in-progress"
 	Pending    DeviceStatusStatus = "pending"
 	Success    DeviceStatusStatus = "success"
 )
 
+// Defines values for DriftDecision.
+const (
+	DriftFailed   DriftDecision = "failed"
+	DriftRestored DriftDecision = "restored"
+	DriftSkipped  DriftDecision = "skipped"
+)
+
 // Defines values for EventTypeNotification.
 const (
 	EventTypeNotificationOrgCamaraprojectIotNetworkOptimizationNotificationV1PowerSaving      EventTypeNotification = "org.camaraproject.iot-network-optimization-notification.v1.power-saving"
@@ -104,6 +125,19 @@ const (
 	SubscriptionEventTypeOrgCamaraprojectIotNetworkOptimizationNotificationV1PowerSavingError SubscriptionEventType = "org.camaraproject.iot-network-optimization-notification.v1.power-saving.error"
 )
 
+// Defines values for TransactionStatus.
+const (
+	TransactionActive          TransactionStatus = "active"
+	TransactionCancelled       TransactionStatus = "cancelled"
+	TransactionCompleted       TransactionStatus = "completed"
+	TransactionEnding          TransactionStatus = "ending"
+	TransactionFailed          TransactionStatus = "failed"
+	TransactionPartiallyFailed TransactionStatus = "partially-failed"
+	TransactionRolledBack      TransactionStatus = "rolled-back"
+	TransactionScheduled       TransactionStatus = "scheduled"
+	TransactionStarting        TransactionStatus = "starting"
+)
+
 // AccessTokenCredential defines model for AccessTokenCredential.
 type AccessTokenCredential struct {
 	// AccessToken REQUIRED. An access token is a previously acquired token granting access to the target resource.
@@ -130,6 +164,20 @@ type AccessTokenCredentialAccessTokenType string
 // Note: Type of the credential - MUST be set to ACCESSTOKEN for now
 type AccessTokenCredentialCredentialType string
 
+// Canary Activates power-saving on a share of the devices first. The other
+// devices are actuated only when the failure rate of these canary
+// devices stays within `maxFailurePercentage`. Otherwise the transaction
+// is aborted: it moves to the `failed` status, the canary devices are
+// restored, and a power-saving error notification with code
+// `CANARY_ABORTED` is sent.
+type Canary struct {
+	// MaxFailurePercentage Maximum share of canary devices that may fail, in percent, for the other devices to be actuated
+	MaxFailurePercentage *float64 `json:"maxFailurePercentage,omitempty"`
+
+	// Percentage Share of the devices actuated first, in percent. At least one device is a canary; when the canary would include all devices, no canary phase takes place.
+	Percentage int `json:"percentage"`
+}
+
 // CloudEvent The notification callback
 type CloudEvent struct {
 	// Data Event details payload described in each CAMARA API and referenced by its type
@@ -253,6 +301,28 @@ type Device struct {
 	PhoneNumber *PhoneNumber `json:"phoneNumber,omitempty"`
 }
 
+// DeviceErrorCode Reason of the failure of a device, when the status is `failed`, or of
+// the last attempt of a `pending` device waiting to be retried:
+//   - `DEVICE_NOT_FOUND`: the network does not know the device.
+//   - `BACKEND_UNAVAILABLE`: the network could not be reached, timed out
+//     or was overloaded, or was not called while it is deemed unavailable.
+//     Retrying later may succeed.
+//   - `BACKEND_ERROR`: the network rejected the change or answered
+//     unexpectedly.
+//   - `ORIGINAL_STATE_MISSING`: the configuration of the device before
+//     power-saving was not found, so it could not be restored.
+//   - `PROFILE_NOT_FOUND`: the power-saving profile of the transaction is
+//     no longer configured.
+//   - `ACTION_TIMEOUT`: no result was received for the device, after
+//     the configured number of attempts.
+//   - `VERIFICATION_FAILED`: the network accepted the change, but the
+//     configuration read back from the device does not match it.
+//   - `DRIFT_DETECTED`: the configuration of the device was changed
+//     outside the service since power-saving was applied, so it was not
+//     restored.
+//   - `INTERNAL`: an internal error occurred.
+type DeviceErrorCode string
+
 // DeviceIpv4Addr The device should be identified by either the public (observed) IP
 //
 //	address and port as seen by the application server, or the private
@@ -319,13 +389,53 @@ type DeviceStatus struct {
 	// After the CAMARA meta-release work is concluded and the relevant
 	// issues are resolved, its use will need to be explicitly documented
 	// in the guidelines.
-	Device *Device             `json:"device,omitempty"`
-	Status *DeviceStatusStatus `json:"status,omitempty"`
+	Device *Device `json:"device,omitempty"`
+
+	// Drift Decision taken when the configuration of the device was changed
+	// outside the service between power-saving being applied and its
+	// restore: the device was restored anyway, the restore was skipped, or
+	// the device failed with `DRIFT_DETECTED`. Absent when no change was
+	// found.
+	Drift *DriftDecision `json:"drift,omitempty"`
+
+	// ErrorCode Reason of the failure of a device, when the status is `failed`, or of
+	// the last attempt of a `pending` device waiting to be retried:
+	// - `DEVICE_NOT_FOUND`: the network does not know the device.
+	// - `BACKEND_UNAVAILABLE`: the network could not be reached, timed out
+	//   or was overloaded, or was not called while it is deemed unavailable.
+	//   Retrying later may succeed.
+	// - `BACKEND_ERROR`: the network rejected the change or answered
+	//   unexpectedly.
+	// - `ORIGINAL_STATE_MISSING`: the configuration of the device before
+	//   power-saving was not found, so it could not be restored.
+	// - `PROFILE_NOT_FOUND`: the power-saving profile of the transaction is
+	//   no longer configured.
+	// - `ACTION_TIMEOUT`: no result was received for the device, after
+	//   the configured number of attempts.
+	// - `VERIFICATION_FAILED`: the network accepted the change, but the
+	//   configuration read back from the device does not match it.
+	// - `DRIFT_DETECTED`: the configuration of the device was changed
+	//   outside the service since power-saving was applied, so it was not
+	//   restored.
+	// - `INTERNAL`: an internal error occurred.
+	ErrorCode *DeviceErrorCode `json:"errorCode,omitempty"`
+
+	// ErrorMessage Details of the failure, when the status is `failed`, or of the last
+	// attempt of a `pending` device waiting to be retried
+	ErrorMessage *string             `json:"errorMessage,omitempty"`
+	Status       *DeviceStatusStatus `json:"status,omitempty"`
 }
 
 // DeviceStatusStatus defines model for DeviceStatus.Status.
 type DeviceStatusStatus string
 
+// DriftDecision Decision taken when the configuration of the device was changed
+// outside the service between power-saving being applied and its
+// restore: the device was restored anyway, the restore was skipped, or
+// the device failed with `DRIFT_DETECTED`. Absent when no change was
+// found.
+type DriftDecision string
+
 // ErrorInfo error information
 type ErrorInfo struct {
 	// Code Code given to this error
@@ -341,6 +451,17 @@ type ErrorInfo struct {
 // EventTypeNotification Event triggered when an event-type event occurred.
 type EventTypeNotification string
 
+// FanOut Paces the actuation of the devices when the power-saving starts and
+// ends, to avoid overloading the network with a large number of devices.
+// The operator may enforce lower rates, globally and per network.
+type FanOut struct {
+	// EventsPerSecond Maximum number of devices actuated per second. Cannot exceed the rate configured by the operator.
+	EventsPerSecond *float64 `json:"eventsPerSecond,omitempty"`
+
+	// JitterWindowSeconds Spreads the actuation of the devices randomly over this window, in seconds, from the start or end of the power-saving.
+	JitterWindowSeconds *int `json:"jitterWindowSeconds,omitempty"`
+}
+
 // HTTPSettings defines model for HTTPSettings.
 type HTTPSettings struct {
 	// Headers A set of key/value pairs that is copied into the HTTP request as custom headers.
@@ -383,10 +504,42 @@ type Port = int
 
 // PowerSavingRequest defines model for PowerSavingRequest.
 type PowerSavingRequest struct {
+	// Atomic Activates power-saving on all the devices or on none of them. When START fails on any device, the devices already moved to power-saving are restored, the transaction moves to the `rolled-back` status, and a single power-saving error notification with code `ROLLED_BACK` lists the failing devices. With a canary rollout, any failing canary device aborts the transaction, whatever `maxFailurePercentage`.
+	Atomic *bool `json:"atomic,omitempty"`
+
+	// Canary Activates power-saving on a share of the devices first. The other
+	// devices are actuated only when the failure rate of these canary
+	// devices stays within `maxFailurePercentage`. Otherwise the transaction
+	// is aborted: it moves to the `failed` status, the canary devices are
+	// restored, and a power-saving error notification with code
+	// `CANARY_ABORTED` is sent.
+	Canary *Canary `json:"canary,omitempty"`
+
 	// Devices Device IDs or group identifiers
 	Devices []Device `json:"devices"`
 	Enabled bool     `json:"enabled"`
 
+	// FanOut Paces the actuation of the devices when the power-saving starts and
+	// ends, to avoid overloading the network with a large number of devices.
+	// The operator may enforce lower rates, globally and per network.
+	FanOut *FanOut `json:"fanOut,omitempty"`
+
+	// Profile Name of the power-saving profile applied to the devices, among the profiles configured by the operator (e.g. light, deep, ultra). When omitted, the default profile is applied.
+	Profile *string `json:"profile,omitempty"`
+
+	// Recurrence Repeats power-saving on a schedule. Each occurrence starts at a time
+	// matching `schedule` in `timeZone` and lasts `durationMinutes` on the
+	// wall clock of that time zone, so that a 22:00 to 06:00 window keeps
+	// its local times across daylight saving changes.
+	//
+	// Occurrences are created within `timePeriod`, when provided, and an
+	// occurrence starting before the previous one has ended is skipped.
+	// Each occurrence is a transaction of its own, with its own
+	// notifications, reporting the recurring transaction as
+	// `parentTransactionId`. Cancelling the recurring transaction also
+	// cancels its running occurrence.
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+
 	// SubscriptionRequest The request for creating a event-type event subscription
 	SubscriptionRequest SubscriptionRequest `json:"subscriptionRequest"`
 	TimePeriod          *struct {
@@ -401,12 +554,61 @@ type PowerSavingRequest struct {
 // PowerSavingResponse defines model for PowerSavingResponse.
 type PowerSavingResponse struct {
 	ActivationStatus *[]DeviceStatus `json:"activationStatus,omitempty"`
-	TransactionId    *string         `json:"transactionId,omitempty"`
+
+	// ParentTransactionId Recurring transaction this transaction is an occurrence of
+	ParentTransactionId *string `json:"parentTransactionId,omitempty"`
+
+	// Profile Power-saving profile applied to the devices
+	Profile *string `json:"profile,omitempty"`
+
+	// Status Lifecycle status of a transaction:
+	// - `scheduled`: waiting for its start time
+	// - `starting`: power-saving is being applied to the devices
+	// - `active`: power-saving was applied, waiting for the end time
+	// - `ending`: the original configuration is being restored
+	// - `completed`: the last action succeeded on all devices
+	// - `partially-failed`: the last action failed on some devices
+	// - `failed`: the last action failed on all devices, or the
+	//   transaction could not run
+	// - `cancelled`: the transaction was cancelled by the API consumer
+	// - `rolled-back`: START failed on some devices of an atomic
+	//   transaction, and the other devices were restored
+	//
+	// A transaction without end time moves from `starting` to a final
+	// status. A recurring transaction stays `scheduled` until no
+	// occurrence is left.
+	Status *TransactionStatus `json:"status,omitempty"`
+
+	// StatusHistory Statuses the transaction went through, oldest first
+	StatusHistory *[]StatusTransition `json:"statusHistory,omitempty"`
+	TransactionId *string             `json:"transactionId,omitempty"`
 }
 
 // Protocol Identifier of a delivery protocol. Only HTTP is allowed for now
 type Protocol string
 
+// Recurrence Repeats power-saving on a schedule. Each occurrence starts at a time
+// matching `schedule` in `timeZone` and lasts `durationMinutes` on the
+// wall clock of that time zone, so that a 22:00 to 06:00 window keeps
+// its local times across daylight saving changes.
+//
+// Occurrences are created within `timePeriod`, when provided, and an
+// occurrence starting before the previous one has ended is skipped.
+// Each occurrence is a transaction of its own, with its own
+// notifications, reporting the recurring transaction as
+// `parentTransactionId`. Cancelling the recurring transaction also
+// cancels its running occurrence.
+type Recurrence struct {
+	// DurationMinutes Duration of each occurrence, in minutes
+	DurationMinutes int `json:"durationMinutes"`
+
+	// Schedule Start times of the occurrences, as a 5-field cron expression (minute, hour, day of month, month, day of week)
+	Schedule string `json:"schedule"`
+
+	// TimeZone IANA time zone the schedule is evaluated in
+	TimeZone string `json:"timeZone"`
+}
+
 // RefreshTokenCredential defines model for RefreshTokenCredential.
 type RefreshTokenCredential struct {
 	// AccessToken REQUIRED. An access token is a previously acquired token granting access to the target resource.
@@ -456,15 +658,134 @@ type SingleIpv4Addr = string
 //   - 1-555-123-4567
 type Source = string
 
+// StatusTransition defines model for StatusTransition.
+type StatusTransition struct {
+	// Status Lifecycle status of a transaction:
+	// - `scheduled`: waiting for its start time
+	// - `starting`: power-saving is being applied to the devices
+	// - `active`: power-saving was applied, waiting for the end time
+	// - `ending`: the original configuration is being restored
+	// - `completed`: the last action succeeded on all devices
+	// - `partially-failed`: the last action failed on some devices
+	// - `failed`: the last action failed on all devices, or the
+	//   transaction could not run
+	// - `cancelled`: the transaction was cancelled by the API consumer
+	// - `rolled-back`: START failed on some devices of an atomic
+	//   transaction, and the other devices were restored
+	//
+	// A transaction without end time moves from `starting` to a final
+	// status. A recurring transaction stays `scheduled` until no
+	// occurrence is left.
+	Status TransactionStatus `json:"status"`
+
+	// Timestamp Timestamp of when the occurrence happened. Must adhere to RFC 3339.
+	// WARN: This optional field in CloudEvents specification is required in
+	// CAMARA APIs implementation.
+	Timestamp DateTime `json:"timestamp"`
+}
+
 // SubscriptionEventType event-type that could be subscribed through this subscription. Several event-type could be defined.
 type SubscriptionEventType string
 
 // TransactionId Transaction identifier allocated for enabling/disabling IoT features
 type TransactionId = openapi_types.UUID
 
+// TransactionList defines model for TransactionList.
+type TransactionList struct {
+	// NextCursor Cursor to fetch the next page, absent on the last page
+	NextCursor   *string              `json:"nextCursor,omitempty"`
+	Transactions []TransactionSummary `json:"transactions"`
+}
+
+// TransactionStatus Lifecycle status of a transaction:
+//   - `scheduled`: waiting for its start time
+//   - `starting`: power-saving is being applied to the devices
+//   - `active`: power-saving was applied, waiting for the end time
+//   - `ending`: the original configuration is being restored
+//   - `completed`: the last action succeeded on all devices
+//   - `partially-failed`: the last action failed on some devices
+//   - `failed`: the last action failed on all devices, or the
+//     transaction could not run
+//   - `cancelled`: the transaction was cancelled by the API consumer
+//   - `rolled-back`: START failed on some devices of an atomic
+//     transaction, and the other devices were restored
+//
+// A transaction without end time moves from `starting` to a final
+// status. A recurring transaction stays `scheduled` until no
+// occurrence is left.
+type TransactionStatus string
+
+// TransactionSummary defines model for TransactionSummary.
+type TransactionSummary struct {
+	ActivationStatus *[]DeviceStatus `json:"activationStatus,omitempty"`
+
+	// CreatedAt Timestamp of when the occurrence happened. Must adhere to RFC 3339.
+	// WARN: This optional field in CloudEvents specification is required in
+	// CAMARA APIs implementation.
+	CreatedAt *DateTime `json:"createdAt,omitempty"`
+	Enabled   *bool     `json:"enabled,omitempty"`
+
+	// EndDate Timestamp of when the occurrence happened. Must adhere to RFC 3339.
+	// WARN: This optional field in CloudEvents specification is required in
+	// CAMARA APIs implementation.
+	EndDate *DateTime `json:"endDate,omitempty"`
+	Owner   *string   `json:"owner,omitempty"`
+
+	// ParentTransactionId Recurring transaction this transaction is an occurrence of
+	ParentTransactionId *string `json:"parentTransactionId,omitempty"`
+
+	// Profile Power-saving profile applied to the devices
+	Profile *string `json:"profile,omitempty"`
+
+	// Recurrence Repeats power-saving on a schedule. Each occurrence starts at a time
+	// matching `schedule` in `timeZone` and lasts `durationMinutes` on the
+	// wall clock of that time zone, so that a 22:00 to 06:00 window keeps
+	// its local times across daylight saving changes.
+	//
+	// Occurrences are created within `timePeriod`, when provided, and an
+	// occurrence starting before the previous one has ended is skipped.
+	// Each occurrence is a transaction of its own, with its own
+	// notifications, reporting the recurring transaction as
+	// `parentTransactionId`. Cancelling the recurring transaction also
+	// cancels its running occurrence.
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+
+	// StartDate Timestamp of when the occurrence happened. Must adhere to RFC 3339.
+	// WARN: This optional field in CloudEvents specification is required in
+	// CAMARA APIs implementation.
+	StartDate *DateTime `json:"startDate,omitempty"`
+
+	// Status Lifecycle status of a transaction:
+	// - `scheduled`: waiting for its start time
+	// - `starting`: power-saving is being applied to the devices
+	// - `active`: power-saving was applied, waiting for the end time
+	// - `ending`: the original configuration is being restored
+	// - `completed`: the last action succeeded on all devices
+	// - `partially-failed`: the last action failed on some devices
+	// - `failed`: the last action failed on all devices, or the
+	//   transaction could not run
+	// - `cancelled`: the transaction was cancelled by the API consumer
+	// - `rolled-back`: START failed on some devices of an atomic
+	//   transaction, and the other devices were restored
+	//
+	// A transaction without end time moves from `starting` to a final
+	// status. A recurring transaction stays `scheduled` until no
+	// occurrence is left.
+	Status        TransactionStatus `json:"status"`
+	TransactionId string            `json:"transactionId"`
+
+	// UpdatedAt Timestamp of when the occurrence happened. Must adhere to RFC 3339.
+	// WARN: This optional field in CloudEvents specification is required in
+	// CAMARA APIs implementation.
+	UpdatedAt *DateTime `json:"updatedAt,omitempty"`
+}
+
 // XCorrelator defines model for XCorrelator.
 type XCorrelator = string
 
+// IdempotencyKey defines model for Idempotency-Key.
+type IdempotencyKey = string
+
 // Generic400 defines model for Generic400.
 type Generic400 struct {
 	Code interface{} `json:"code"`
@@ -550,6 +871,60 @@ type Generic501 struct {
 type ActivatePowerSavingParams struct {
 	// XCorrelator Correlation id for the different services
 	XCorrelator *XCorrelator `json:"x-correlator,omitempty"`
+
+	// IdempotencyKey Client-generated key making the request safe to retry. A request
+	// repeating a key already used with the same body returns the original
+	// response and transactionId without creating a new transaction. While
+	// the first request with a key is still being processed, a repeated
+	// request is rejected with 409 CONFLICT and can be retried. Reusing a key
+	// with a different body is rejected with 422 IDEMPOTENCY_KEY_MISMATCH.
+	// Keys expire after a configurable period.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// ListPowerSavingParams defines parameters for ListPowerSaving.
+type ListPowerSavingParams struct {
+	// Status Only return transactions in this status
+	Status *TransactionStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// DeviceId Only return transactions including the device with this network access identifier
+	DeviceId *NetworkAccessIdentifier `form:"deviceId,omitempty" json:"deviceId,omitempty"`
+
+	// ParentTransactionId Only return the occurrences of this recurring transaction
+	ParentTransactionId *TransactionId `form:"parentTransactionId,omitempty" json:"parentTransactionId,omitempty"`
+
+	// CreatedAfter Only return transactions created at or after this time
+	CreatedAfter *DateTime `form:"createdAfter,omitempty" json:"createdAfter,omitempty"`
+
+	// CreatedBefore Only return transactions created before this time
+	CreatedBefore *DateTime `form:"createdBefore,omitempty" json:"createdBefore,omitempty"`
+
+	// StartAfter Only return transactions starting at or after this time
+	StartAfter *DateTime `form:"startAfter,omitempty" json:"startAfter,omitempty"`
+
+	// StartBefore Only return transactions starting before this time
+	StartBefore *DateTime `form:"startBefore,omitempty" json:"startBefore,omitempty"`
+
+	// EndAfter Only return transactions ending at or after this time
+	EndAfter *DateTime `form:"endAfter,omitempty" json:"endAfter,omitempty"`
+
+	// EndBefore Only return transactions ending before this time
+	EndBefore *DateTime `form:"endBefore,omitempty" json:"endBefore,omitempty"`
+
+	// Limit Maximum number of transactions to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor Opaque cursor returned as `nextCursor` by a previous call
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// XCorrelator Correlation id for the different services
+	XCorrelator *XCorrelator `json:"x-correlator,omitempty"`
+}
+
+// CancelPowerSavingParams defines parameters for CancelPowerSaving.
+type CancelPowerSavingParams struct {
+	// XCorrelator Correlation id for the different services
+	XCorrelator *XCorrelator `json:"x-correlator,omitempty"`
 }
 
 // GetPowerSavingParams defines parameters for GetPowerSaving.
@@ -27,12 +27,11 @@ import (
 	"path"
 	"strings"
 
+	. "github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
 	"github.com/oapi-codegen/runtime"
 	strictecho "github.com/oapi-codegen/runtime/strictmiddleware/echo"
-
-	. "github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 )
 
 // ServerInterface represents all server handlers.
@@ -40,6 +39,12 @@ type ServerInterface interface {
 	// Activate or de-activate power-saving features for IoT devices
 	// (POST /features/power-saving)
 	ActivatePowerSaving(ctx echo.Context, params ActivatePowerSavingParams) error
+	// List power-saving transactions
+	// (GET /features/power-saving/transactions)
+	ListPowerSaving(ctx echo.Context, params ListPowerSavingParams) error
+	// Cancel a power-saving transaction
+	// (DELETE /features/power-saving/transactions/{transactionId})
+	CancelPowerSaving(ctx echo.Context, transactionId TransactionId, params CancelPowerSavingParams) error
 	// Get status of a transaction for power-saving features
 	// (GET /features/power-saving/transactions/{transactionId})
 	GetPowerSaving(ctx echo.Context, transactionId TransactionId, params GetPowerSavingParams) error
@@ -75,12 +80,172 @@ func (w *ServerInterfaceWrapper) ActivatePowerSaving(ctx echo.Context) error {
 
 		params.XCorrelator = &XCorrelator
 	}
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
 
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.ActivatePowerSaving(ctx, params)
 	return err
 }
 
+// ListPowerSaving converts echo context to params.
+func (w *ServerInterfaceWrapper) ListPowerSaving(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(OAuth2Scopes, []string{"iot-management:power-saving:read"})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListPowerSavingParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "deviceId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "deviceId", ctx.QueryParams(), &params.DeviceId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter deviceId: %s", err))
+	}
+
+	// ------------- Optional query parameter "parentTransactionId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "parentTransactionId", ctx.QueryParams(), &params.ParentTransactionId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter parentTransactionId: %s", err))
+	}
+
+	// ------------- Optional query parameter "createdAfter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdAfter", ctx.QueryParams(), &params.CreatedAfter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter createdAfter: %s", err))
+	}
+
+	// ------------- Optional query parameter "createdBefore" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdBefore", ctx.QueryParams(), &params.CreatedBefore)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter createdBefore: %s", err))
+	}
+
+	// ------------- Optional query parameter "startAfter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "startAfter", ctx.QueryParams(), &params.StartAfter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter startAfter: %s", err))
+	}
+
+	// ------------- Optional query parameter "startBefore" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "startBefore", ctx.QueryParams(), &params.StartBefore)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter startBefore: %s", err))
+	}
+
+	// ------------- Optional query parameter "endAfter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "endAfter", ctx.QueryParams(), &params.EndAfter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter endAfter: %s", err))
+	}
+
+	// ------------- Optional query parameter "endBefore" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "endBefore", ctx.QueryParams(), &params.EndBefore)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter endBefore: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "x-correlator" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("x-correlator")]; found {
+		var XCorrelator XCorrelator
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for x-correlator, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "x-correlator", valueList[0], &XCorrelator, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter x-correlator: %s", err))
+		}
+
+		params.XCorrelator = &XCorrelator
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListPowerSaving(ctx, params)
+	return err
+}
+
+// CancelPowerSaving converts echo context to params.
+func (w *ServerInterfaceWrapper) CancelPowerSaving(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "transactionId" -------------
+	var transactionId TransactionId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "transactionId", ctx.Param("transactionId"), &transactionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter transactionId: %s", err))
+	}
+
+	ctx.Set(OAuth2Scopes, []string{"iot-management:power-saving:write"})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CancelPowerSavingParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "x-correlator" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("x-correlator")]; found {
+		var XCorrelator XCorrelator
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for x-correlator, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "x-correlator", valueList[0], &XCorrelator, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter x-correlator: %s", err))
+		}
+
+		params.XCorrelator = &XCorrelator
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CancelPowerSaving(ctx, transactionId, params)
+	return err
+}
+
 // GetPowerSaving converts echo context to params.
 func (w *ServerInterfaceWrapper) GetPowerSaving(ctx echo.Context) error {
 	var err error
@@ -148,6 +313,8 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	}
 
 	router.POST(baseURL+"/features/power-saving", wrapper.ActivatePowerSaving)
+	router.GET(baseURL+"/features/power-saving/transactions", wrapper.ListPowerSaving)
+	router.DELETE(baseURL+"/features/power-saving/transactions/:transactionId", wrapper.CancelPowerSaving)
 	router.GET(baseURL+"/features/power-saving/transactions/:transactionId", wrapper.GetPowerSaving)
 
 }
@@ -242,6 +409,21 @@ type Generic410JSONResponse struct {
 	Headers Generic410ResponseHeaders
 }
 
+type Generic422ResponseHeaders struct {
+	XCorrelator XCorrelator
+}
+type Generic422JSONResponse struct {
+	Body struct {
+		Code interface{} `json:"code"`
+
+		// Message Detailed error description
+		Message string      `json:"message"`
+		Status  interface{} `json:"status"`
+	}
+
+	Headers Generic422ResponseHeaders
+}
+
 type Generic429ResponseHeaders struct {
 	XCorrelator XCorrelator
 }
@@ -348,6 +530,16 @@ func (response ActivatePowerSaving409JSONResponse) VisitActivatePowerSavingRespo
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
+type ActivatePowerSaving422JSONResponse struct{ Generic422JSONResponse }
+
+func (response ActivatePowerSaving422JSONResponse) VisitActivatePowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(422)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 type ActivatePowerSaving501JSONResponse struct{ Generic501JSONResponse }
 
 func (response ActivatePowerSaving501JSONResponse) VisitActivatePowerSavingResponse(w http.ResponseWriter) error {
@@ -358,6 +550,157 @@ func (response ActivatePowerSaving501JSONResponse) VisitActivatePowerSavingRespo
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
+type ListPowerSavingRequestObject struct {
+	Params ListPowerSavingParams
+}
+
+type ListPowerSavingResponseObject interface {
+	VisitListPowerSavingResponse(w http.ResponseWriter) error
+}
+
+type ListPowerSaving200ResponseHeaders struct {
+	XCorrelator XCorrelator
+}
+
+type ListPowerSaving200JSONResponse struct {
+	Body    TransactionList
+	Headers ListPowerSaving200ResponseHeaders
+}
+
+func (response ListPowerSaving200JSONResponse) VisitListPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ListPowerSaving400JSONResponse struct{ Generic400JSONResponse }
+
+func (response ListPowerSaving400JSONResponse) VisitListPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ListPowerSaving401JSONResponse struct{ Generic401JSONResponse }
+
+func (response ListPowerSaving401JSONResponse) VisitListPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ListPowerSaving403JSONResponse struct{ Generic403JSONResponse }
+
+func (response ListPowerSaving403JSONResponse) VisitListPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ListPowerSaving501JSONResponse struct{ Generic501JSONResponse }
+
+func (response ListPowerSaving501JSONResponse) VisitListPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CancelPowerSavingRequestObject struct {
+	TransactionId TransactionId `json:"transactionId"`
+	Params        CancelPowerSavingParams
+}
+
+type CancelPowerSavingResponseObject interface {
+	VisitCancelPowerSavingResponse(w http.ResponseWriter) error
+}
+
+type CancelPowerSaving202ResponseHeaders struct {
+	XCorrelator XCorrelator
+}
+
+type CancelPowerSaving202JSONResponse struct {
+	Body    PowerSavingResponse
+	Headers CancelPowerSaving202ResponseHeaders
+}
+
+func (response CancelPowerSaving202JSONResponse) VisitCancelPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(202)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CancelPowerSaving400JSONResponse struct{ Generic400JSONResponse }
+
+func (response CancelPowerSaving400JSONResponse) VisitCancelPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CancelPowerSaving401JSONResponse struct{ Generic401JSONResponse }
+
+func (response CancelPowerSaving401JSONResponse) VisitCancelPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CancelPowerSaving403JSONResponse struct{ Generic403JSONResponse }
+
+func (response CancelPowerSaving403JSONResponse) VisitCancelPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CancelPowerSaving404JSONResponse struct{ Generic404JSONResponse }
+
+func (response CancelPowerSaving404JSONResponse) VisitCancelPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CancelPowerSaving409JSONResponse struct{ Generic409JSONResponse }
+
+func (response CancelPowerSaving409JSONResponse) VisitCancelPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CancelPowerSaving501JSONResponse struct{ Generic501JSONResponse }
+
+func (response CancelPowerSaving501JSONResponse) VisitCancelPowerSavingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 type GetPowerSavingRequestObject struct {
 	TransactionId TransactionId `json:"transactionId"`
 	Params        GetPowerSavingParams
@@ -367,13 +710,21 @@ type GetPowerSavingResponseObject interface {
 	VisitGetPowerSavingResponse(w http.ResponseWriter) error
 }
 
-type GetPowerSaving200JSONResponse PowerSavingResponse
+type GetPowerSaving200ResponseHeaders struct {
+	XCorrelator XCorrelator
+}
+
+type GetPowerSaving200JSONResponse struct {
+	Body    PowerSavingResponse
+	Headers GetPowerSaving200ResponseHeaders
+}
 
 func (response GetPowerSaving200JSONResponse) VisitGetPowerSavingResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-correlator", fmt.Sprint(response.Headers.XCorrelator))
 	w.WriteHeader(200)
 
-	return json.NewEncoder(w).Encode(response)
+	return json.NewEncoder(w).Encode(response.Body)
 }
 
 type GetPowerSaving400JSONResponse struct{ Generic400JSONResponse }
@@ -431,6 +782,12 @@ type StrictServerInterface interface {
 	// Activate or de-activate power-saving features for IoT devices
 	// (POST /features/power-saving)
 	ActivatePowerSaving(ctx context.Context, request ActivatePowerSavingRequestObject) (ActivatePowerSavingResponseObject, error)
+	// List power-saving transactions
+	// (GET /features/power-saving/transactions)
+	ListPowerSaving(ctx context.Context, request ListPowerSavingRequestObject) (ListPowerSavingResponseObject, error)
+	// Cancel a power-saving transaction
+	// (DELETE /features/power-saving/transactions/{transactionId})
+	CancelPowerSaving(ctx context.Context, request CancelPowerSavingRequestObject) (CancelPowerSavingResponseObject, error)
 	// Get status of a transaction for power-saving features
 	// (GET /features/power-saving/transactions/{transactionId})
 	GetPowerSaving(ctx context.Context, request GetPowerSavingRequestObject) (GetPowerSavingResponseObject, error)
@@ -479,6 +836,57 @@ func (sh *strictHandler) ActivatePowerSaving(ctx echo.Context, params ActivatePo
 	return nil
 }
 
+// ListPowerSaving operation middleware
+func (sh *strictHandler) ListPowerSaving(ctx echo.Context, params ListPowerSavingParams) error {
+	var request ListPowerSavingRequestObject
+
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListPowerSaving(ctx.Request().Context(), request.(ListPowerSavingRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListPowerSaving")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListPowerSavingResponseObject); ok {
+		return validResponse.VisitListPowerSavingResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CancelPowerSaving operation middleware
+func (sh *strictHandler) CancelPowerSaving(ctx echo.Context, transactionId TransactionId, params CancelPowerSavingParams) error {
+	var request CancelPowerSavingRequestObject
+
+	request.TransactionId = transactionId
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CancelPowerSaving(ctx.Request().Context(), request.(CancelPowerSavingRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CancelPowerSaving")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(CancelPowerSavingResponseObject); ok {
+		return validResponse.VisitCancelPowerSavingResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
 // GetPowerSaving operation middleware
 func (sh *strictHandler) GetPowerSaving(ctx echo.Context, transactionId TransactionId, params GetPowerSavingParams) error {
 	var request GetPowerSavingRequestObject
@@ -508,144 +916,202 @@ func (sh *strictHandler) GetPowerSaving(ctx echo.Context, transactionId Transact
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/9x9+24bOdbnqxCVASZO6+pbx1osMGrbyQjdsd22/F0myjpU1ZHEcYmsJlly1IaBfY19",
-	"vX2SxTkk6yKVLwmSwbffH4PpqKrIw8Nz+Z0L6fsoVstMSZDWRIP7KOZpOuXxLf1DybHm0vDYCiWP1TJL",
-	"wUKCT+7/ouGPHIztTFWyftU1+dTEWmT44qV70jVC3j7gy5kyFv8/geKdaBApGQOzC2BmbSws2YIb5gdl",
-	"VjGQfJoCy9Qd6LYBa4WcGzZTmvE0nUj8MIGViMEwYVms0hRia2hADSZPrWFcJizTaiUS9xKui1nlPh5e",
-	"jNixkiZfgp7IqBWpDDRH2kZJNIhGanwG9k7p2/PMiqX4kx6dKStmIqb/jlpRxjVfggVtosHH++gvGmbR",
-	"IHrVLVnaLV/pfmnHSmtIuVU6evjUivxqf1HJmlivpAVJnOJZlvppunGq8gRWONpP/zTIuvvIxAtYcnoz",
-	"Tc9nj87u3jPdYxzjFMegieELx72kPeHWjRNbsaIJryy3uVuQYzA+Ftlqf5gkGgwJRpZPUxEXP0Rv9zv9",
-	"3YPO0V6n30O+0OMLpW00ODj6+fDgoYUjHJYf7PZ6/UEyfTt4e8D3Bm+TvUF/r380eMt3YbD3c2/w897+",
-	"ftSKpNuCYRyDMaMEJLIfdDSI+rt7+weHP789+luillzITqyWOPNCSTjLl1N66afireihFRm/sCgDmQg5",
-	"J1bYUsBp343V9KhFnPFbYtcZRIPartBOtCJR+aYVGZVr5Fe0sDYzg25XVuTlCmRyBXoFur/b8TvgqTYZ",
-	"xCvQxilGv4M8tGIJxKj+23Zvv907GPd/Huz1B73eP/Cpo0jpeSfmS655ptU/IbYdoWzbc62tKpLbrpLS",
-	"WfU7XrH4ipb78IArrulnxtep4glDHnAhhZyTchVqEtQscoIsNFoGq3N4wB9MpqQBEpbd3u629v+nyjUz",
-	"xA4mkBVLkNaNaxYqTxOmweZaMrsQhv19PL5gbv9YrBJgYkbE4B6xOzIcMYgVJMzkJCuzPE3XUStaAE9I",
-	"Pe+jmvoNmvXFv76hqsib3d7+04t4IdVSsVTJOa5aWtBgLCRMSDbLtV2AZnmWcAvme5K+3+s99lGxT933",
-	"IEGLGN+lT/pf8UnffbL3FZ/s0Sf9ryCs7wjbPXr5J7tHEa7fQJxrYddk0qpqYH4BrkEPc7uIBh8/oTkw",
-	"+XLJ9ToaRBfBcTivUbgVppzokX9CpSgUwng1emLj6uJz7J+h0IuEfBsJtZjNQIO0JFro4dBEFBb/KTv/",
-	"H8cbe1/1UN+THIEfuIWimeZkq2rDfzPJNdtREcvHPWTwit6oug9Pz04vR8c3+73ezejs34a/jU5uhpfv",
-	"rz+cno23lz6SK56KhA31PEdD1GF+Yna1lpZ/YadfYsi811/xNAdHToLL3hq+FS3BGD7Hh8epINZlEKPr",
-	"ShiXTPjZuJ+tVeAeBFNMafZHDnrNaPM6Uem59ns95FB1befX45vzdzeXw7P3p9vrOs9JXi+5nEOHXTki",
-	"thfFcgMJu1uAZJzNxQokmwlIE8Jk+D8uWS5NnmVKo70iDnQaWFGj5qVs0ETd5jIfWl+Nck61VnokZyp6",
-	"aN1HmUbNtMLJgyPwPgKZL6PBx6ZNqxH/qQIYiq/2e71PSFjwvlN0udHDpwbv+QtPmAfC39OWV2zut+pD",
-	"/+b6bHg9/vvp2Xh0PByfnmyLjSecxVxKZdkUGM/tAtFXzC1tXsI4k3BX/Z3MhpnIAAYmTbqyOXVVRsKs",
-	"OGV9viQHDAmWwhgh560gOS1UFfiSudliDQQQeWo6bNhMHQvEdYi6UuD6P1zgNlf+iID1Xypg1xJXp7T4",
-	"E5IfImF73yxhezcXp5cfRldXo/Ozm5PTs1GTjF2Apv1UkiUgBSQddo6eeJdZdYuGiHAcSxQYkogFX4F3",
-	"w24LmYlVBigCZLjwUW5AsxkXqSm9Mk9ZAQC25XGb0AarVafB5LOZiOlBVqzBoHxmoGdKLx34czFF3azt",
-	"/XAp217PI3K291I5e6f0VCQJyB8iZPvfLGT7N6MT1KZ3o9PLm7Pz8c278+uzBjkbliOyMoJkubyV6q7Z",
-	"RP16dv7vZzfDi4vfUFeRl+VUNflAmbNcz8GyCuFoZ/zw9e3frzvv/afIvgQXTOJgKHozlcukgdpyiCph",
-	"4wVUfK1uGmuLtB8smVVCn2HxIyK7/1KRPavw6/uL7NE3i+zRzfH52bvfRscNCPSEUi2Mpxp4ssaIMNg6",
-	"FyIiP8jYxUrOUhHbWuiB72dazSm7si0kxbSbMuISPCgXj05czROUVPDH6DBVUuoydvTDZaxY5yMSdPRS",
-	"CTr2i/sRAtT/5lCm37t5f37WAPOvDSDba7Etm6XqDr0ST/E/qulO/FXIhMAVswtumbCGhcyvsxIhS8FX",
-	"XKR8mkKDWBExVZFyeTocvmIZ69Zna9yajPR/PPAnopvlo/9idP9eSfgRsrH7zcZl9+jm9+vz8fDm9D+O",
-	"T09PnkL1BINxbSWyhi8xQIKqzNk0N0LiNv6RK8tZKpbCNmz+xmxVMfBRZ7HxNFBtn3ePaq5w9+hmfH5+",
-	"82F49p83l6e/X59eja8aPHlNulCgMTSdAoI/WGZKcy3SNZumKr4tl6a9xTKZuAXGtUYW0KLwW1yyBh4v",
-	"oMm5bhNVi1dwZBopDLG1xh8sy1t7sE1ws6TvvtgSjpViH7hch2DW/ACxP/jmaPag1ycQNfpw8dsphvFN",
-	"cn/l0lYEfYpUM8YbYxcyuIye8Dlan8fFmHelRILRrmEzrvH/MmWMmKYkV2jaKB9OzBMrJ2woB4ynYi4h",
-	"2chrm0fQW5X2un8Whs1yGbsoRth1wG+VRbA12KrUHfwLItlNohtF7ODlkSzCtVG5pO8nYAUfaKRKDWxL",
-	"RFASqt6zcIZRK0oEvrkUMpCw5Fkm5JwKpN+p/vJs2e4C375yL7e+17QdwF1/fnISjuihFeRifebyvbS3",
-	"5c9eXEJFsc5hGoglYCk6D7Ul987UlUBId46HH4aXQ7LuXGLoQrnnGBI2XRNMoUm35KqhXLdJwRISwdv4",
-	"zGGeMLerGVOBtaALZKwSSjUtc0MJsMmWTZpElCIvCUYFDzqyZcA+FSSXFUaRbFMpyhA1mCUireWIDuTk",
-	"UvyRQ0Dr3s/S8r+QPVgK+RvIuV1Eg37DzKFQ+bRRuHJvPWwUKDdJ/jf3IBRGfNzpFckqdrcQ8aKyFAod",
-	"lF4a9josp9/pMTFzhXz/zCpWqXzjG51d5mnYqXC63+k1MtfVT59e4Am3MMb3CkP1jJVEWsbrDGp9AK5o",
-	"ESqgH3FbCw7XWedn8cSh3cRAQ8wbChI119EOPCX2iHnuo62yusMkQOKUhPag0o7BllxyhNzUOxEjnYh6",
-	"HF87EzmSQYbvwGW4Mg0JzAS6MG6tFtPcgmEp4qfP1ZFPKfWKDPzcqj/5wL8Qrww+EFJYwVP64fNEFlUI",
-	"JwykkJVpgkgECoSsD31CJuTzRJ4pCwOUGl6UT4QJOThw9SsDK9A8rUzVwngo8AcNj3t0J9KUsIDhS2Cf",
-	"HZs/VxjsEsZ1S1ddWBPsoD6Wz1bn8Bk3Bm1aHEIwMSv/+46jhHtMwaUniRtmlJL4/1tbimBFQ5GLj3Pt",
-	"CnTC5qE2P3P9MG6s0KwzkacORQ3KYDAw71LxZSEYHTaqEGjAYlxfrpZAuM6BZi+TCEIy7UcpSGmVKxKG",
-	"WS3mc6B8/XWGoyBTvBNjCcTCeKNxC5AxYR3bvXJPlUqBSzJIWxLxnO4eE7+utr/bGK0U6maYUNsHKj54",
-	"QyeWwF4LyRJuoU3/QkHkdqeSTPHqWZWEDht5sz5TFKl/vHx3zPb29o4+vQ5tJOjbrObxLeiOADvrKD3v",
-	"JiruLuwy7epZjK+/MkBQsX3QOdyhjaFRXd4cyflTSeiwl7E9qjQJRbu93b12r9/u/zzu7w36bwe7e53D",
-	"t7v/iFqRW2I0iIpVR03upsk0NBi94PucxC/5F7HMl0xSOw+6lyDMmdJOYabA5hhDkCa8nuS93h78z/4z",
-	"HGdtdu56zxBPu8EpJqYYrrWtbSAT8y2MOyA/jGuoemEhLcxBb7mNBpH+1IBzHpXjRml1gCu45pItjpPV",
-	"KTtNoKpwkNuDiyUYy5cZjl0UYFTsTFGMniTLQGKY9QHFkCcL0BQjBfHuTOS/Dy/PBowCHZX5Yo0rPAvJ",
-	"SvxpNjBFpY7HhJzICgTbiLmc+ahKcmM31cuk+KTohtuAtjJpU+UJacqWZLt9kBgrKVFSrGKcLdVUpMA8",
-	"TO8wb4oNU7OJLHoZZZzmGIYuubbUzWaY0mykxsyANEqbLo/RtCryST6JCyn6JRZzyeKFUoYm962PtDFT",
-	"QNNSlgTcdBNZwk0zmMg37HOl0e9z+OGw9kOlxc798EiTHrro8/Fpf0AEfDg7Z0sxX6DQURsBUzJdM04y",
-	"CKGrBoNxYmtYG6qbkCt161cX1rTMUyuyFFhlAd4goO3nFsP0ieSxVsZUWlg+nJ2bDhv5Bq2YG8ee6igf",
-	"rq/GxC85L5pFCRU4nnXcsnYHlBegHhlh2LFaLl2UjtZLQwrcQAsRFdfAADFt7DroOKUNJvIRrqFwE2cy",
-	"roteI+fDcLaJnOU219DOtFIzilDQ0nsNKAqVHhAgg8mnYOCUG6R9OLPg+nr8N0uwvO0JZkgRgQvlxNDB",
-	"C2c6UlhxaSdSGJODoXVpMCpdoc30EzgchVjUbwZ8wThIICWJinMX4U+kj1vmuUggFRI8vFoKeVFBWP0t",
-	"wFXvQn0S2tNmjfwH0WYD6gs/Lj54eKIX9emxzh757GGjWfXpUS4qrzakVYJxKtY7uI+4XPvET9XL1Dt3",
-	"kcFixS2EHz49tJ55v2ztpYa5JpdDcLDMoxXKRd4YBPU54va7sdhrNaUeymSHjS4mkjHuZnP922gqEAoD",
-	"yODMq7VW131JnSA0pFsOjvI6VTFPHRLict04W5iKDC8JsJ8i2EfGXpN+C+l8hMsNSVyWmlpOEcp0zVZc",
-	"C5UbtgQuTYvMgnc6bKbVEscxagns5OzKU2x2MPRy6o06WjbWBPrq5KG6FZbotehAh50Nxz4jiOM7+nfw",
-	"JcnKVwMvy+0onQCSOVV2gZ/X9tl3zlclw1F7Nhwf7nukn6OBs89vOJqG2ui0xThBIUq0fQYw2rKQrsNX",
-	"JV9GF6vDYimvCSLQmksNDabS11GdYuxQdIsBH1p609pYZQg1C45gcIhm/07YBeOWoVFER0WCgPjTyW6d",
-	"M0h7dSkJZCCRAyzPlPTZD2GYawjAkUbSo9a0FUBBcP9lx1WdiXYBIkyD3Niv6UmKyL4Odb6pR79ubTdM",
-	"w3PJIiHnac3gbhDwbZ87Ap8xjvjO01axavm/2mS5NH+hlaUoktxO12heSMVpDXVhRfUneQqpunwqMcov",
-	"RNuXsyoGBzrzDn6PeGEw6IU3uof7LNMwE192tmHti05TFDAX9eZxhHtV5PI3ErsF/n3ed0ZNNYHi4EUr",
-	"ErJd6VfwTftIIhcpJA0JvabdLYsWW7tKme2q1d5K3oSqxmbhPwHfAUtsF4a5JHkDt4pSzXYvh6VluE9Z",
-	"9WFTcFywqT7K1ukBd7QAvURppEoKWeibjp6PNN2MLceDciFN4WZzyvOR9H6R3fG9xD7x45LuLuT0ESKV",
-	"SoNYfK8qyvcujDSllc8eR4Eb1epgrSsZfW8UXJ29ntCTDQHiSLK99xcXzIJeCqlSNV+3XI5cu81Oimr4",
-	"+4urkc83oVHxsgHs9IsFjWF1JcJ4ff8bIqPKTw9/uz+hU1TV33Y67FpS0peaOSAFwqs+Y+Jcvyc1NGuK",
-	"WhiDjszkU99pgAGp0pZPBZUydU6HJ4REqmNwYQwBi0SsRJLzNF37zLWDjTxe+D4jpevpqUeOgm1t3UUd",
-	"bn/ldpUMqGVNcJPq+2Ye2aIPV6OrkzP2+oN7+8qfdhpJ2iGf/bgKiWnNToSG2Cq9Zo7mHZpL6cQ18EyB",
-	"zVM1JT6FapCljgj3sBQGId1Zo2IS96iFu6Y0uRmr2Gmnf7iP9kYmXCct72qCLP31p7/WmV45WteKMpxI",
-	"Ixv/12Ty08d+++jTx1776NP9fqu///CXxs3wfn3DGR9foEu9PrlwwN/JGpoolwuMBocHB3vVvFpv29rh",
-	"4EW1NPTgP+LNzKOdeKMTQnZzrfKsmh9A52VhaV7uB8PpQa35Gv/tTrZS7e/pxHaF9iehU8MnvgB2AVqo",
-	"ZHvxIJMTbhtc1xBlBYXA5R7EElrMOe0AsMfFsFQjoH4EnyxoueCD4jB6g8RRqjBCwi21e70waWy5ti+n",
-	"kl5vpvOFU257ST//p81HQXbKvWzeuCaHWpNN77G39mf7NO7910id/6hB9jaOu96/BGZdaGVVrNInEvaU",
-	"necsgVSs6OSS/6TDzmW6dgchhQvo7nzgKdVdBQLgG1Er+vD7eLzn//8gakXDD7/jz2dDar36dfju12FU",
-	"Pb8cvtsSno1wosHcl2B9vwDrZOqkCiB9yc1tzehthFBVPL3/CBW3x8WxlEeouGXlyZXyuPrG0RWlWTju",
-	"ERp+y4SEBPS+XK8r5+aLrQhlE+OS0K4ps/NMX8vw+Pj06mp8/uvp2WOS5hDQWN2CrCyxFV38Nhw9+tFF",
-	"ykX99cvTd5enV39/cqpLmGkwi825thtSSkaOfWtKJR7eeDioLXIr+N18uylsJFjrzU35fieUpseNj1k7",
-	"ZJiZcRXiCh1BM1x8F850EEdbNXo3GPfpOWO2sZwmw3RV9IQ8WZXzTSaIKlxqoyjvhooPa1P3HJNKtmGZ",
-	"2fVEfr6+HLWLXp7P1EswmMg2u74c+WwL5ca8jNv1ACPhNywUQOfCLvIpArvqdQjunSUXqVWDWMaz9t28",
-	"7dJrKRjzt1QYazr4oCMUzSZRJwxiprbHTNeXZ4GA6+vRiZ8313KQ5yIZHMLbaby/12sfxXu83e8nR+2j",
-	"w8Ojdu9tr7fb68VH/PAQR66cMimbNUrU4IetEt/F17pZnqbd/u6ee95vHxwctPu7e22EVhtB/rN3Cpgc",
-	"5RfSWHkEXNinXIuS+893B1UBRRH/NcTYZWxHZY24yDCW3RV2oVU+93FqHThfVXsz3DDFCL75o1PTg/+f",
-	"wsSrZhi3bUNC9wUqPrV0uGhjK26u8u4Z400+8RFTis8ewYsbtjS48AbTWPQsPdlv4d5yXxf44ck0XmVG",
-	"9IvNDAu+umgs897NLHiaOtkhzwcJy024tMJA6qLQApg0qhbIJFNC2ur1HHSNzYYy1UMe9/Vk0p1Mup2f",
-	"GqMdswUEnsmF3tb9HA7YEKockwyz06K7yTBIxTy0KNeYQXnMTSUMruqpdgs3bga6nrGgSh/NELsuaVvr",
-	"jUhhBSmp74twa7PJeaDAb+QG2CW75f/R34S2m+DdbVoheq0gtYGXTR5wvAmON6SvfFzLEhSpXCr7+Gsh",
-	"uokw/oKIkRqzGXCbawoZSkHKqVNwS1iqNyKUfe8YJu7v7e3F+4ft/aO4196fHe623/aSn9uzHsyO9nqz",
-	"frx/WBfNj7z957D9j177qH0z+B8dlNG819uLXafM/cOn+15r9+CwKUSvXJxxhZvk5O/R6zPuoyn9611Y",
-	"3dYNUTWh7tQByUO4LoKWSQOVFKF+IV8UHQ9usgrCkOzlBow7RMx22ccPSgPB5LKPimeiptqJik0XMccO",
-	"7kuq7px9o1O/Ja3G3bikvAqiq3CJqSVIO6g6hIEGnkSD6L2mjj5eHFjB39tU1qcba3wtgEJB5mLB4rQM",
-	"JRiemOJOCwvlHPTPykxPjIt2BCH0tU6rNq/CD4W86NJLtVCQE+OpoR58QvFExQ0G6UKrJI9tUet38Qm3",
-	"zFmqqBXltcmruK7qj7vN129RGb8x3//qFTtfgV4JuHONI6h0fgRWHSIYKRdlbV4EViROJzLmmctVCurQ",
-	"YSEkdwpdgryg2a69ls1ScN0sPkfQmchXr9hIWscZoaSjz8QguRYYkoXzKf7GGe3QVMa1laBNQKdjBHbs",
-	"3GdAqTMlgSxVa1pqqB9S8agVWoVazHcNmh3mW2EoNYJD/d///X8McynBO5HggiFN85TrIv88kWMMJ02u",
-	"ATlGB/eKbs8pGZk1S8XMdYFWD9yDPyEfr1uOmVtLNAC3LiJ1bIX6OdNam7NjrbAGVzqRjsNJTl3MLs9E",
-	"G5SqOzeasHSHypQbSJiS1XZmu8BAUqVJ6C/aJIy4UqpeuJvOtSA57lUkayK3RMsqlqwlX4qYMrM8+Wdu",
-	"bJEn9/1gjsZac/HYdRlQIs11wy3Fnz5pxhmlbcJ1CZTMXfHUtJiGJI9du+1c8nQijdUE7AN/RJICyxau",
-	"+o3bhFY2cbl3kwJkLF7HqZdeL0QTiSKncssSYXSekczHWlhcU7gEqH4yyZ2lwvXDiqc5tzhKZdGJoI1s",
-	"Bekhj+l+ofaMSkOH9+LLImWRZel6IkGCnq/bUNy9UFwJeLcQKaIXUVxTNs85GkeAhP2RE31tNWt7wieS",
-	"oInpsF/WhFI0nweYOLwYubpGIadO/E1Nx3wf6URSEpqnTnQDxws9aNGmkLekTiy0nC4PWtUdK5bIK3fW",
-	"xW+mWyuLFYavxNlqSEJp+xy5E/o3PGVtj7n8506VDCuGRm1YgV4AJ7XYsAR+s4ScaW6szmO0ahPpDUpK",
-	"S7wcnrHcijRQ4pdcFaSdDnO3Zhg2BQkzYX0HTC5JblGeICmkKGTcllzmKL9OttHvUud4ihzEGXKDmxuK",
-	"R3PFU28Yq2ZHQyr8K53JZCLxf2/e+DZL6u6hhj9kCDr7wZs34a2Pb954S/DmTQkXHvVO+Emzh+pOUzXt",
-	"ojB2az6wO7wY3dR/8YPc+FFuqsPcXBvQV1bpNf7XMTdw0+8sk51yVeOFk3kGqdcVn2l60vFxDZVVv3mz",
-	"XSjBx7K4+6xy1ta5dt+5JeiclT/0wyt3oWlW4l5Si4n0Jn3DTxb+0zd/VhSs8wh9Lln+KIHl6XZ0r65u",
-	"7nlS8y2ekIksk+ylr/Ctjq4zo8T7RNIrNqylYMly1dK0zqdMQlx25fEzvUl1PpJWL8QXWs1ECpOohCPh",
-	"eJySbKHu0CrUG9c9oqb7uArIdwuywy5c/yYleZApPmlHU08ksoa6XwvHNpHPS7kbw66HMvEDlN93dyYy",
-	"XBDnm0eTcDqr4LpbYLGdlUtJ4o1sNmFvH7K6u8h8dMnnGs04dVJ5a0OAQsmp4q52mWkVUy+Oyz+SgQV7",
-	"B74SVWNg6GYdXowm0rNdt5jlt64YTYCErKtvyolTjsFzlutMGfBHqGJfFA4uYyLRBxnLeGqUP1TuC99B",
-	"QDMNKy5ILVKY85TN0PeTkiYittwdVZhI6lnEV4RJeSF5ZCqka2Jjd9RIn4E2ZPRcQGECG+pHHnC9uUEU",
-	"GXM5kfAFdCxcz7PQTIv5gq7MdTm6JcQLLoVZGmbyeME44ZC2IEnvKk3/UrltuZ7o4uS0BnQ9cwSHVaE0",
-	"SNWSywTxKiEEYQp/ihC3vIdXA5oy6iqM1TJLBZfBN1ATWrxmGuZ5GjBDniGGK4Qh00LGwrfSO7XNuEb4",
-	"WTCgHYO0WsRhvPZ03U4AHbQD6EjFSdU806+ukcd874hiXQO+dFKHFKseWASP3BRgTKSaBTBZYHJTQWFU",
-	"BCpwZLjGxStC5hZYA5XTGhgKqVBCFJtoHHmitM93ECR3gcjSHygs2UUimJJuc0m/bMc6T+HliayccLSq",
-	"WE6Akf6wH0lLCXNqmCAgnk6dqmJ7Xtt15ucO3nOLRuK7XQidtDEqW0/kVoSwUwsRcAba9+q5gmAu/bUo",
-	"nnEbG0cFdILDYDaO7FQxoGkVKhp8L9qOu7bb5qVKvLx4qNl2LCHgRZEhbdMv60qK9Cnxbm3xhAyK12bS",
-	"Y6G9Cy8kwW8DYPifAb+tRIUVLFwPJyeyiCfFknQKQQXVCcrIowbz5AuUUxh33SRiFu/qhxejTumVnvza",
-	"n1dx2+HO6FESgIajIpb7z/6AjdgdVTBc19RjwW1NoZfrmiQEJ8crbdouxi2WKmSW21Kc+FStAiAjfEcU",
-	"bacEwinNikQSmaML6nO5Gn1g1f6uHRymRjaaS+1vosoNPnaHHDV1pPu8g+XadgG9bDgfZnYC4Sq3WW6f",
-	"JryCyTyEe+2bQ7szLtLceb6wmNCfQ8RW4BobnbhpNI9vSTccBQHZhTw/E6aygQPWDVvTra584pzBMHFR",
-	"LE/D0Zl636V3E6kwBGjdw5g0UfjzRiROm0fZpLIMvix4bqxYgbNTGmbKN/xvf7Pka/oohBPuaIFUsl14",
-	"Wzf7RDoegkFDEW58ckeqK/csnJAfZO+LwzifSdY2IeVH98lNTEedOmu+TD+9vk+FvL2x6saDwIfu9lse",
-	"KnLn28Fu8qhgIE07NNXoQOcptPx7nw96fdZmG9ePfC7OY7jzZOGOlomsj+7vF0AM0nQmKeRknWF49Yq9",
-	"G/7+VzORr98NfzclHE0S9ypnKEl6E/LWIrwdGufSH606U5QUeSe0sSzRfFZqwlPmx5VAUxGDbxTydy0P",
-	"Mx4vgO3SXfH1pOrd3V2H02M6J+y/Nd3fRsenZ1en7d1Or7OwS1dYEpZKC0+RELWi8nL6O5FFrehL24UK",
-	"7bh67i0a9DoHD/SXFCTPRDSI9jq9zp4rSSwoXdysYdU/EvHCP0DRVEwqPu0+8t3Dw+afeRh6C12/X+Vf",
-	"8ncdtv+Ww9NnHLZaGh/qRa8nrt7//hT4xrWG23wuGlxdUeXG+CCz3/fC2v+619u7201f+Mm+++Qrrrfv",
-	"0V+1OPiKteC7Wzfih7LaxxeUnTbuyA/aQ3kfaL8A7lSASdSKLJ8b6nCqFKxct2WznehWfLzp3teaGekv",
-	"zcyBhLyu4+/BPqnewv21C7sob7Kvt0luatlLr7av15MfHlrfYkpq2tz7r6DNpsC1G39u47+ZMv6LNItq",
-	"xhuK9R5sJYXJa9gW1ahRwZ5QqK/9KxjuiCq96rDFPc/EpVL2obvRKdVdOUSw4lpQzsGJJb3syrQznqfW",
-	"w5NBt0sZroUydnDUO+pHmwJH6SClXgaOUGI/FavevpcBCeqeUEL6EaPUKXW+xrOHTw//LwAA///d74VQ",
-	"GmsAAA==",
+	"H4sIAAAAAAAC/+y9jXIbOZIg/CqI6o1Y20NSJCW5LW58EUtLtIfR1s9IdM/OmP4ksAoUMS4C1QBKMseh",
+	"iHuNe717kotMAFWoH0q02+6929uY6LFYVQASiUQi//EliuU6k4IJo6PRlyimabqg8Sf8IcVMUaFpbLgU",
+	"x3KdpcywBN58+RfFfsuZNr2FTDY/7el8oWPFM/jw0r7Z01x8eoCPM6kN/Juw4ptoFEkRM2JWjOiNNmxN",
+	"VlQT1ykxkjBBFykjmbxnqquZMVzcarKUitA0nQtomLA7HjNNuCGxTFMWG40dKqbz1GhCRUIyJe94Yj+C",
+	"eREjbePxxZQcS6HzNVNzEXUimTFFAbZpEo2iqZydMXMv1afzzPA1/ye+OpOGL3mMf0edKKOKrplhSkej",
+	"D1+if1FsGY2in/ZKlO6Vn+x97sZSKZZSI1X08LETudm+lskGUS+FYQIxRbMsdcPsxanME3YHvf3pHxpQ",
+	"9yXS8YqtKX6ZpufLraPb7/TeMfQxgT5wYPaZwlrimlBj+4kNv8MBrww1uZ2QRTC85tndwThJFNNIGFm+",
+	"SHlcPIheHfQGw8Pe0X5v0I867vWFVCYaHR79/PLwoQM9vCwbDPv9wShZvBq9OqT7o1fJ/miwPzgavaJD",
+	"Ntr/uT/6ef/gIOpEwi7BOI6Z1tOECUA/U9EoGgz3Dw5f/vzq6N8TuaZc9GK5hpFXUrCzfL3Aj/5UfBU9",
+	"dCLtJhZlTCRc3CIqTEnguO7aKHzVQcy4JTGbjEWjyqrgSnQiHrTpRFrmCvAVrYzJ9GhvTwT0csVEcsXU",
+	"HVODYc+tgINaZyy+Y0rbjTHoAQ4NXzNE1OBVt3/Q7R/OBj+P9gejfv/v8NZCJNVtL6Zrqmim5D9YbHpc",
+	"mq7DWlcGlNsNQendDXpuY9E7nO7Dw0Ontj8zukklTQjggHLBxS1urmKb+G0WWULmCjiDUTl7gAc6k0Iz",
+	"JJZhf9jc/X+TuSIa0UE4oGLNhLH96pXM04QoZnIliFlxTf48m10Qu34klgkjfInAwBqRe2QcMeN3LCE6",
+	"R1pZ5mm6iTrRitEEt+eXqLL9Ru37xX1e26qAm2H/4PFJ7Ai1kCSV4hZmLQxTTBuWEC7IMldmxRTJs4Qa",
+	"pr8n6Af9/rZGxTrtvWWCKR7Dt9hk8BVNBrbJ/lc02ccmg68AbGABGx7t3mR4FMH8NYtzxc0GWVq4DfRr",
+	"RhVT49ysotGHj8AOdL5eU7WJRtGFPzjsqVEcK0Ra0sPzCTZFsSG020aPLFyVfI7dOyB6nuDZhkTNl0um",
+	"mDBIWnDCAYsoOP5jfP4/jmtrH55QX6JpwtaZNEzEm+4vbNMCUcqZMN1bQCAFyvzENmRNP/nN789nTZeM",
+	"GAnUrjY9MvYv5kKxjFE4qwnFxjRVjCYbkmuWkHtuVtiPpmtGQHJw+8We21LxWy5oOhd+LfEIr7Bo7EPm",
+	"hsSqGEew+/CjHvnriqfMHvNLrrQp4EYALGBcE214mpIFg14yJYFvsKRDKLGTYMlc+IZcE8WAwfpZHPSP",
+	"yPH52Zt30+MZQhlTQRYMMcJZ0iOXLNcFGubCjVyuLc6+2e1wSKYnk9OL89nk7Phv179M/nZ9Or06Hc+O",
+	"/9ybi1/YRhP2OeOKEbo0TBEK/HnJb3NlxSWmuEx6KNBwWFFLjHCUUjxP6iQQUtaafn7HxC3shuHhYSda",
+	"c+F/D4ojxx+QD53vSN2toFa6/+YdUDmKAi63XeDyQpY7o23Dydnkcnp8fdDvX0/Pfh2/m55cjy/fvj+d",
+	"nM2aU5+KO5ryhIzVbQ7nWo+4gcnVRhj6mUw+xyxzQuQdTXNmwUlwherdd6I105resmKDEhAYQBJKCBWE",
+	"u9GoG61TkDvSmFTkt5ypDUFe0ItKQeig3wcMhXM7fz+7Pn9zfTk+eztpzus8R/Z3ScUt65ErC0RzUm6z",
+	"r5gglNzyOybIkrM0QREf/qOC5ELnWSYVUD5ioNeCigo0u6JBIXT1aT50vlponigl1VQsZfTQ+RJlChi9",
+	"4UyXAH6JmMjX0ehD26JVgP8YyJ9Fq4N+/+PDQ7Gz5AI4QfTwsUUYe00T4vSq7ykaBEf4t+6HwfX7s/H7",
+	"2Z8nZ7Pp8Xg2OWmSjQMcWKSQBrgkzc2KCQMj4OIljosHz5Ft6LnwsuW8ba/Uhw5pxI8KQ1bHS3I8vNZc",
+	"A4fueMrpwFaxzDWZi1gx1DdoquGAa4WOeOAswy0JbvDDCa4+8y0ENtiVwN4LmJ1U/J8s+SEUtv/NFLZ/",
+	"fTG5PJ1eXU3Pz65PJmfTNhq7YArXUwqSMIEn8DkIdkNi5CdgRKgWkEQyjRSxonesEGlgCYmOZcaABJBx",
+	"watcM0WWlKe6FPJoSgp5skmPTUBbuFYVBp0vlzzGF1kxBw30mTG1lGptdQkn2lSobP+HU1lzPlvobH9X",
+	"Onsj1YInCRM/hMgOvpnIDq6nJ7Cb3kwnl9dn57PrN+fvz1robFz2SEqDBMnFJyHv21nUL2fnfz27Hl9c",
+	"vIO9Crgsh6rQB9CcoeqWGRIATrj23VeX/6B6eB88BvYls7YJwi3pLWUukhZoyy5CwGYrFpy1qq2vBmg/",
+	"mDJDQJ9A8RaSPdiVZM8CfH1/kj36ZpI9uvYKSHO9T9ByV2hfXITqGzA7Qw0yO9AeUh6biiYL32dK3qKx",
+	"rkkkxbB1GrH2QqCLrQOHZqcSCroNDh2CUqWxox9OY8U8t1DQ0a4UdOwm9yMIaPDNqsygf/32/KxFzH+v",
+	"GaC9Yiohy1TeEyPB+i7vK9ZzeMpFAl/CkUoN4UYT70iwXMIbvegd5Sloqi1khcCEJGXNvtB9wBmr3KfR",
+	"b4VGBj9e8Eeg2+ljsLN0/1YK9iNoYzj8VtoYDq+3mSGa9AJbv2ZXQKNs0/YTGkBCJbVNDd42fEgh9VF3",
+	"H7FCJ8Nh5SgdDq9R6jl7Gx4jjWmjF6ldHHAnIxdxmifWwBuwwZbZtowXzjMX5fHbPuQT87maXP46PZ6g",
+	"iOCOydfvWrb+lbXJWKXJDgRmJW+8cd60hPBi4JbJbBmsIVG4sRyyii382GiNaf7g7b11Kq0LtpVm2xnE",
+	"cLi7dubsk4ifY7ehfwTH+GZxZHh0/Zf357Px9eQ/jieTk8fsAKHV0+ni7HPMWGLtpYtcc8G0Jr/l0lCS",
+	"8jVv2zK10ULqcnaq4qjAjqqkc1TdIUfXs/Pz69Px2d+uLyd/eT+5ml21yP6V8wiOQDBmLRgTxAAfUlTx",
+	"dEMWqYw/lVNTTsbRGf/ECFUKUICT0s72rBiNV6xNHG8CVbFwQM/Yk++iMccfvD0aa9AEeAvp7yw7zaQk",
+	"p1RsvPlL/wCyP/xm+9dhf4C8YXp68W4Chr/JyeNMtfB1goViZo0M1qXEnZPQORLBSnYnkf1RTZZUwT+Z",
+	"1JoDCzCSgDCEXhNEHr/Dh0gHhKb8VrCkHMz5p9r1vRD2Ko/mmixzEVu7Bzeb4lwrJ0E2zIRUd/gH2L7q",
+	"QLeS2GF/8DUK3rSc0vcjsAIP2NMxFehabLIVDL1guqocSUEo0SuqmHc5+ngX9GlZ6pFmxdRc+DfwMY1N",
+	"jmZOKdJNacwCO1aumGNH2KFmJEaYyg60oRuNkhMX5GZNP7+xzS6YioGObtlNj5zDoPdcWwNa4HmbC64J",
+	"XaBVf0S4IWt5xwqGeQMgsOTGucQ7+NACQIIJoPfPSIWuOLQMV9DCgFAqugmCi3tnLm6Ox2fjy79dj1+f",
+	"X84mJzfo5gMPDBpoq2TVNju7OkuapyYa9euEcko/83W+LlelBj0qP2u6QWR3UHW1PXcKmQYXrGwgyaJc",
+	"sSiIzBn0OxFY/6iJRlEic6syrS0A8LqP/jn7q1+QubChL+D5rc2pwpDaqKqgGySvEPoeGRuSMqoNkaKi",
+	"5TsE/FtJZvYBuUce5uRf0Bn9MB0ipP8oW1GgIfoJSD+lMeuFGDgMpnt0FMy29EVyYdgtTPchjEH5EM79",
+	"Y4MDdKIgGqpVl6kQl9djo06UcPhyzYXnBWuaZeAMHX35bpE4TwZwXcDXV/bjzvcatoe76unBkUtHD8VO",
+	"2pxZVy2i+KG+wXxsWRXD2BFJmEHDuo8yst8srK6Eh9jx+HR8OUYxC9iAYqjFxSwhiw1aGHDQluVtBG7V",
+	"IVizhNMuvLM71o9toxAw1K6Ai4lYJuglWucafVfzhnAwj3B7lwDDSesPq/rH0ceGK92GkdWhLJWeQj5A",
+	"0DqOzThwcsF/y5nXMJ3Ai9P/jAfzo078MmTt8dP5yn71UAtVq4P8q33hOYvTWd1GMpLcr3i8CqaCVj+p",
+	"1po889MZ9PqELwkP3hlJghhI+KI3JA6G5wGmIW6uDbk2ku7xCZ5Qw2bwXSExPCGuACyzTcYqEaF1PsST",
+	"Miiwijo3igMOBJhjDONoiSWoyHBdj9My7AMekzLOhwjGErtJcA2CwFyypoKCtQyjaGOAE09UxGtvLqbC",
+	"0/A9s86pTLGELbkAIdQYxRe5YZqkoMjchD1P0GsKCLzpVN+c0s+IKw0vuODgUMUHN3NRBBBYYsANGQzj",
+	"ScJDwEW16xNkITdziMplI6AaSoJ4Hec+Yzb0RLM7pmgaDNXBY8l1CIzHvrqHmKBcuxilG4vmmwDBbaJE",
+	"OLE2+R8jmm+MytkNLAzwtNhbT/my/PueAoU74Z4KBxLVREsp4N/GknJtI6GcGz3OlY2t4XCYc4zSXNrI",
+	"aNuXD9uei4k9aEelHde9I5eSrgvC6JFpAKBmRpNwtgAszAtHLyUDLohyvRSgdMoZcU2M4re3DF3t7zPo",
+	"BZDiDjGSsJhrxzQ+MZYR7iQ4t7kXUqaMCmRIDYp4au8eI76umu1qvZVE3S4mVNYB4wYco+NrRp5xQRJq",
+	"WBd/WUnueeAHcdszpIQemTq2vpRoZP9w+eaY7O/vH3185gOK4WwzisafmOpxZpY9qW73Ehnvrcw63VPL",
+	"GD7/STMUx7uHvZfPcWGwV+vyBnD+KQXrkd3QHopkEMq73+0PuoOfZ4P90eDVaLjfe/lqCBHJpbDqZx21",
+	"HTdtrKGF6fmzz1K8kwOJlW7hePHEnEllvBhdRi0+m+f9/j77/wZPYJx0ybnNQuDad861N6Z0mruNiUR/",
+	"C+IOv0p8bSHpVjF2Gx23UqsVuPzRXKLFYjIcstcmVBUHZLNzvmba0HUGfRd6gIwtK4rhJMkyJsDecQpk",
+	"SJMVU2is8OTdm4u/ji/PRgQtDjJzcRY2ZowLUsqfuiZTBCE4hIu5CESwmvHDso+Qklvj6nej4pMiL6Im",
+	"2oqki0EjAFO2Rt7trDWxFAIoxUhCyVoueMqIE9N7xLFiTeSyVMW98qTXVBnMa9BEKjKVM6KZ0FLpPauy",
+	"STyTnP+VpXAuYTRqvJJS4+DOkI4Ls2DAWkp3gh1uLkpxU4/m4gW5CVI+bvyDl5UHQbKFfbAlXQOO6PPZ",
+	"ZDBCAE7Pzsma364McRGA1lBBkQaZKW0TlhD83GC7cXEnP7nZ+Tmt89TwLGWBk8AzBOD91IC9bC5orKTW",
+	"gTPo9Oxc98jUherH1Fkzwl5O31/NEF/itkgbQqnA4qxnpzUcoYEO9XuuybFcr625jDOgTtCbWQckKqoY",
+	"YSDTxjacmqL9bi62YA2IGzGTUWUKkRrPMBhtLpa5yRXrZkrKJWoowOndDihijJxAAAjGM4UbDdJNby7G",
+	"GD8Mnbo2a2Zo1wFMACIULqT3YUHvlnWk7I4KA8YenTujk2JapnfAM90AVo4SjCVuMdhn0IM4QJLIOLem",
+	"trlwesttzhOWcsGceLXm4iKQsAYNgauaj/SoaI+LNXUNonoq0o6NiwYPj2QlPd7X2ZZmD7W0pcd7uQg+",
+	"bbFveuaEmvqxTFq41CWjulTSvF1QgvhsCbtTsnGXvcJ1Yb3DKEngU/A6pcDQDXg/jO3hxiVX3bi+yD3l",
+	"GNRhZBAeP5qLLrk5mRS+NQzlubEMwqG3pGGIfQpMVT1s/Hp8/Mvk7ASiT38dT9+BW67WPkYblIs4Lc90",
+	"DiqBzM1cEIz2o5rIO6ZA22dJxz/CvUPTFAOZgVlzlFoTxqB5LsqAA+jnkhm1gVmmFDYVmAAxEYklVWAn",
+	"l5fnlzUwC1cYPIxXEL0MQFCh7614TEgu2OcMP0o3tsPzy+nb6dn43fXVbDybeHe167mqGlbMfGTBlhJs",
+	"rKRqVfVTxjinDtHSauAVBFqjrB3/4vL8zfRdc+0qnWZKLgF1clk3FGNkLwniNzzIvv/xMUZxzaank/P3",
+	"s5sRfOrybyrpXkVWgSNcTIqArkM8sCSQHB21ajvOr5PL6RsfM/ZmPH03qVMhjWOWVZenQxa5492khmzF",
+	"aGIzhpZKrkPEF9S8piZeWYUG9sDl9M3s+mQymxzPirEfW0CYvgUDSUPmRvuj3TvUNRcxa64vWqJYsbpu",
+	"yaGT6tpOz2aTy7Pxu5uRDas3TIE4Zg3vTq5zEc/e9FLfyVEnatmfwVPcCBAk30rHUSdqEFjUiapEEXWi",
+	"ltWLOlEVpeCWdxNqWocghwXm0L2jStA1nC0fooJ1Wk56Js0bF/1XvHkNGphI3oswnqn+Fn+Hz89dfhNk",
+	"2LJTG30evr+w+6VtwDFuGxC1ZW7CF78yVYjDb5BDh29PFF+aE2aQd4Qvpm5V0fRUOyHBZyc2zmdXMatX",
+	"sn7hSEa/lX/w8aHzxPdlWjAm27UpKZaACxdoIY6h/sY4ek+Q0WBf5JlcANWz5DmZXgApUzuazf2WyhpP",
+	"GBNe/QsDa7GlQpaPXdrpQC/PUhnT1OrO4HtuG80PhaI6ijxuCC9RE/IMJUIurFZhvQmYJSYXhqJNa7Eh",
+	"d1RxmWuyZlRo6yhyagryEOhHyzUjJ2dXDmL9HIx1liuAVFdmUXj4quBRxcqsu2e8x3rkbDxzzlzo38L/",
+	"HD4SpPzU47JcjlJtADAX0qygeWWdLeYrlGGhPRvPXh4421AOIrF5esG50dXecYlF4h4CKeHyaQb2OcPS",
+	"jW9V4mV6cfeymMozVCpxzqVM5zms3QfOof4c7aFgIgTdQHdqs/TGyQIjYE4ERcFGnQX+MhhMLj3tVjED",
+	"sIdTSRiIT0wYkmdSOHs518RGf0NPU+HsHGnHq5FeYSzTa6pINCvG/TCAjYPKPknBFlRVjr8pv78qn9dY",
+	"w1PuBS5u04qIXgPg25pbAJ8Qp+GbR+ToaVVX+GqWZSM0il1ZkiLS7WID7AW3OM6h8oUVIZwv3pmiBDMB",
+	"aTvHesBwWO+2B+3hzB+N+v6LvZcHJFNsyT8/bxpCdqrEUBhGYN9st4lcFWEYNVdgYTF5WtuC3hI4uJ78",
+	"3J5u1vQGrVio9Dw9UKkj+banPvilGU9v3ZVVpWkXRYl4PWkuvkFRmos2XDeCXYqSFp2Ii24Quu/KIcAC",
+	"euEgpiJmoNi0OMta90EFyy2YsW/Qjy9KhOwsw7ZJsAtm7hkTVRnWBss5KRZPAW50ES0yqg/gnuMBfk83",
+	"HZ/DDw/xA/2JZ5lV+cJ6MsRiyjLyunzeI+OFRjcRzFNINw3oby5sIkxFMPZAwFLY4cql2E0aRfRflt3g",
+	"76uiL/zpBD+Q5co4qsZKWQE+kEYabqy41V4AO8Sl8RrpvLdOtG1Q5vrxDcQS25SELx8l8GovjYoatnwA",
+	"S8LDt4SQ+OTv6Gmbux2xY3FQTqTN8N7u/N0S6FD4uVxCtHOB2fAD/LPUqQLC+V7xJN87RKTNwf6GivO8",
+	"xe95QWPnwLGG6gYj0CW7qOcFKVu/aS6YSHQHzeYQDVmYanxFCjcPH+yfQpJIoO27cZwN2SYWSWugsaZY",
+	"RlIYGcPidIfcpnJB03RjhUymCht9i9MXF09fMHXFYilaIjhOG26rRqxVhj5VaN4jx1Z+s6HQlltRU7Fi",
+	"eHnCTaNXDRjrw684zTW/Y6fe22RUzlojyR6LHfsHN4apv3KRyHs7uZa9eJUpRpMn1ldRkch1usF1szvz",
+	"HrvF+DI7c92xko47RpXBPGyR+L4qVBhOeR+mXMSIvXp5sCUoLtzzjc18tt2YWwvT9CJ0EJjjJDUbt171",
+	"y4sWP89UkP23FxfEMLXmQqbydtOxhjZlOVVSBEu+vbiaOrexCSunTD47c0wJKnn25Z2MK48e/v3LCZbF",
+	"Cp8975H3AmM3oCPDUoZmZ0eenYbVS+twqi70V+cLb66UqGDTBcfQYJVjNRwuCG4r641AbS/hdzzJcVfZ",
+	"ABSry0OMVyslb6vt1eA7F1Wr+VcuV4mAivMTFqm6bnrLEp1eTa9OzsizU/v1lStf5Uwrzol55eNLFDnh",
+	"isVGqg2xMD/HsaRKmPIebM99fFCXwQwD+7IkBl5Y5dwg9lUHVk2qxAmRk97g5QFsKJFQlXSc/O9p6V//",
+	"9K9VpAe10jpRBgMpQOP/P5//6cOge/TxQ7979PHLQWdw8PAvrYvhlK2ahnR8Abv5/cmFtcY4HhNs25eH",
+	"h/uHj2/bThQEPfoqGA0Vgxq55nElgHdJU806u4dbp2mFeUkFTwWQiGVFaygtxAS5mo0vZ74ygEALkTdA",
+	"h+19NhpEQbv9Egzo3GYu0rluIK9GTisJAnsXDMtl+LQNjnaK484x0uTm8vzdu8nJNZhib0jKtauaCNOB",
+	"xv7IJH+1R6oL1gUQZA5EJjbFt5X4Zxv5retTASWJGgb8f0tAeWtcT1xEyz8ay2O/wpB+BHtrKvT0BBf0",
+	"Vsk8C728oDIZtta766YOVqoUxZFtpUo8/5vTWBay0WOdOwnKmlHABtycBkTatp2IhZfFK0YVowCQyVo6",
+	"Wcl9qR8RKZwFIQX/PJijWNYheWoUfe5IX665MZ5g3UYrQOCFk6HKW6CfNp6hmA8SeQpBl+WXtSiigB88",
+	"aiNqaeJiQy+wZlaToTCRQNRLy9EiCBfAWK1bnq9Zh1j92y/QrOgWw+fCXNCOtbLieuAXyOKF9D0k1GAc",
+	"/I7xVIYqszuU+Hk7nDsO2VSb3Pgf66/8hiw3SPvCtWlYFX7vVLgmw2+ULP3yNVvZNWrZ0BlVoOBVK4M2",
+	"vehAkXjWlh9aCTd8wLHaVRANJZdt67h121/svtUfV6QfQ0gw1RIrtumfuQaRpUX8x9eswe7JPWq9KyXz",
+	"21WHyDRh2tickl1Zre0ageLGmfbqa2Tqq7ODJetCSSNjmT4Sb6h8HETK77BmmmvSI+cQioP2B269C/fO",
+	"CyLkfaC3wxeQHvyX2Wzf/XsIrsvTv8DjszGmcP4yfvPLOAoL8fp2jQW8rLDIOglmjJrWtDGIdchT1iMT",
+	"FLNL8vOqtSEUecJcoD8aWt74VjcYag1v/y4Fu0FBAyyYmtwkzqB3ykVumL4hSPNsLu7RUwKZr5a3UFOG",
+	"mqLHGR9RMhyO+miW7r+EP6wyiDGTei640QQ9b9hWEx+0RTepDRizU7Q2N1Dq5+K8mJr1c/lgaJ/CVjL4",
+	"G2et9VnlToASc1FHjzU2LqVzm2WK3aGHTgqGzJoJTEovLIgQTF1DM9BIZVfIJbql5D3IQyBYuV9zUSmz",
+	"0XExrWXBzTYuA8bGmxYudYNGBLDuPtE+1XIurCFYIyQqF1jht5xCm8WjtvotslZg72VVlKDCv3YNA8o/",
+	"eNWv5rW96j8eLNuJPJ22MiVlHPG4I66EAGQhWJbDro0sjZWN20aVUAryzELXISuZqw5QHfSxlsKsOv4f",
+	"9/CesU/Pw1lEfTIckhfwv2hLCgrspRbOMz4blzuliPGD6RFMk6GptRTxqttmksPK7F3KXQ5pj68AkE5j",
+	"MdvO4ZpnrUXJLv1WB4XfCulbSO+vWlP9qQJ7zZsYupYO2rB3xcWn46Ic3xYoPpGyYl9Z9b1Wsk8q4svc",
+	"2Qehb14wrLCgNkH5+eIg8DHnqJNRV4ym90RS4Pj4eHJ1NTv/ZXK27ayzdqcZ1KgLptiJLt6Np1sbXaSU",
+	"Vz+/nLy5nFz9+dGhLtlSMb2qj9XM5isROXN5fYFruPZyVJlkww9c/7rNgwrr7Xdr+X3P5/XMWl+Trg/P",
+	"Jdqm1wRw+HO54oqxGO1U4K0h7uNTO6k2ndYtUyTUPZrS4DL0CPde/iI3xofLky5MjhIhRRfchpu5uHl/",
+	"Oe0WiZA3mIiFoZTvL6feAg5hIo7GzWYETuEXxGeP3HKzyhdgTgtvFbDfrClPjRzFIl5272+7NtIkZVr/",
+	"O5oIevCixyWOJmBPaLBUdZ2l6v3lmQfg/fvpiRs3V2KU5zwZvWSvFvHBfr97FO/T7mCQHHWPXr486vZf",
+	"9fvDfj8+oi9fQs9BbZsy061U1l23IfB78Nlelqfp3mC4b98PuoeHh93BcL8LBq2av/vJ0vw6B/plaSyd",
+	"3bHgT7niJfafTq1siLENDeZ3SOfGZ13sntm4zeNVdtVKz2FGlHd8Nck7cGqhpBcXIUNlgp1TCqySVDW6",
+	"XoXpebabogeX/1d1rP7f5B+7ajdXNDmhT8AD9hXUN284DEPcPXEEoV6xhTTg3Ra7SO1E8GpQC4Mv0lYf",
+	"NdPZrx46ZU9PtLgIRoTTvR1hXuIocovdGa1XFCu7+/ObJcRWY7dBBqn1YBTKXSuDYCLJJBcmvKsD77Sp",
+	"sYSqudy2ns/35vO93p9aLeW6Ic48Edz0qXpaQ4ctsvcx0jCZFAmumrCU3/pyMRVkLDYtm9AfuI9l3Nl+",
+	"0WsZNLXJHjhCbCvWmEp6XAp5SXOxs/7fynIeUE2Y2g6GyH3dj0HdPFDndHbRCtLreKr1uGzje0+Yf4LX",
+	"FQ9TEZu1lKq4I2Iv4dr+helbS0ZNrpiuEFKOyeINYgnGecfb3B6CfTbHudKtJfjxOaz+kpl45Tx8nw3J",
+	"KESxUxvKYlV4m8sBL9rACLTH3e1s4cHl7tV4eGKpKuM8sSxXW4JE3vElizdxWgRloUkn6Nlmn3itKLkZ",
+	"FbFXsGjcaOeGtvYR+NRZBW5GVVsL17WgpKpBDtuilZLVW1bi8cPRDV4tkpRjuyixUeWGjFp0FS8riVl/",
+	"EraM/YVdN6NygR3JuuwUrBIUlmfBhhnMFqU7F8rWbG9fQGOMTQ5b79CmUg/GThpzNoI9VeaeqFzY6fi4",
+	"Ndd1xeZINSnet2X6Yg+hF20UOPGaE0GSEcS6FGugdYpMuGotH8jWCRZgLsZVEN29JX5xnYcPIx9KArPa",
+	"5dLegmLJ116v0mbGscWaAkImuTA8JUJWbFpck5QtTVV+KhpFzolghSFLrfidiycsqAjPuSphtIcVdqIA",
+	"0TtGuYXbOoCsuts9kMHTsYc3eDbxoLdeXld5fOEnVKROBC/bnh0HswweX+KEX+N8a0zKXyj0h3kvnAV0",
+	"bL6m5smjPszAEbZrf/JeMBX0Fng6/us4V77ZfRk67XbF6O/RFZ/wlXQie+vYV9HM9oN7mpT1ANuO8PCK",
+	"nrKsIpDcwf7+fnzwsntwFPe7B8uXw+6rfvJzd9lny6P9/nIQH7ysStsfaPef4+7f+92j7vXo33ogdkPl",
+	"hxj/n315+Pil3xkevmyLWAkuBgOOs3bS1Lbrwb5EC/z1xgtsjRswK3J6r2opevD3F+E0saMSIlAZcM/g",
+	"fRVtig7XeJihuw1vtSBD8uFUKob2y7I6CM14RVtJZKz3wBgEtmoo4G1VNryGooQVH+LtF/gXaL82TmvN",
+	"hBmFYstIMZpAvWqFdWpoUQ8VnncxWR1v5HP5Cri9iHXjFsVYYSc9NsS94oaVY+DPYKRH+gVKB9vme5WG",
+	"alyADwm42MOPKh5CiojHeo3MxdedyLhFtLxQMsljU2SwW/GLGmKVr6gT5ZXBQ4NbaGLYa79eFKbAW2O3",
+	"f/qJnN8xdcfZvQ1lBT3C9UDCLrzeZc3f9YtOizhC8P5kNnSPO3HHnUdWRymtb15ZsUWjyDJltkZDGVn7",
+	"009kKozFDBaBnKETgwmquCSU+PKn7kY9ZQ1EIEsIprQ3G87A4kbOfRyKkXORsCyVG5yqG82Gp3R8AYwO",
+	"cbVw9HPvK8SoBujqf/2P/6mJjZC75wlMmKVpntIwpHcmCRM6Vxgag5nIRQ2jBTKZDUn50tY2Cm+AYe7K",
+	"lthmFcxFY4qasU/WVWDRWovZqYjvulA7pnI2FxbDSY7Hnw0RwQWC6g3YGzd4qdeCaiu4BkW6zEoxvZJp",
+	"4iOe64AhVsqt5+/etYU1LPYCypqLBmkZSZKNoGse2zDp5B+5NkHKPVY5sTBWSmbNbCYkxsDYGi9r/k8X",
+	"7+KUM39/D8Y23tEUnaFJjtUyNL91IrFCi6vHD09SZutIuoA4J9dim5SxjKAmaFFcaCleFk+4VnmGNB8r",
+	"bmBORQpKpfCtLbYK8/cOOT0XwaQT7ry3jnrQCGCfAFBh0qk7NNeFLynL0s1cMMHU7abLisuAiiuPbfmB",
+	"NeXFNay3OQXmyFhCfssRvq5cdh3gc4HWFt0jrzdoeFH01lu+xhfTjuWgnk4t+evKHnM601ygF5amlnQ9",
+	"xot90MFFwdMS64sA57QhTOHeQePyXNgKjm4x7VxJLMGvQGxgfkBmNI5lLoxdM8gxdZB1nRnJNbdbSZOi",
+	"a2azA1aMJt6mEXACt1hcLBXVRuUxcLW5cAzFJgFcjs9IbnjqIXFTDgnpeY/Ya5xA5RZsyY3L0s0F0i3Q",
+	"E0sKKvKu0DUVOdCvpW0mnCIJdZpxhFzD4vpY6ltJU8cYQ7ajWMrdJ735fC7gvxcvXPEgzEDGMjaAEDjs",
+	"Ry9e+K8+vHjhOMGLFx+fPXk6QZP2E2pvkcrFHhDjXuUM3BtfTK+rT1wn166X67Cb6/eaqSsj1Qb+Oqaa",
+	"XQ966+R5OavZytI8YanbK84F+OjBRxULZv3iRTNuGF6L4m7XoJS7Pdpddjksk/CBDDS461WR0pSH22Iu",
+	"HEuvnZPF+elKGgUbrLcFPhvnthXA4gwP0g3b4kEdIHPhjh6YRXFWuAI+Nns0uDIVwPmJjCu+ceRcFf+5",
+	"PVPm3tR85eRn/BLD3pFaHRG7agbzqBRHfNFXKcgKvLNUVMuxOYkatbpC5PvERI9c2KpE6H0DpDhv6sbm",
+	"CAFqsKZTcbDNxdNUbvswm7FIXAdl+73nkM6nPL4Y4u+uWt7UKYzFcga3ZMW1MAOUvZ0V3t1NYg3m9FYB",
+	"G8+zktugQCHFQlIbyu9ufug4x7ANTrLpkY16dt4yNb6YzoVDu+pAaibH3AwjPXd1um2cUsUgnV5lUtub",
+	"NyziwyNjLuAM0gbjhpyl0eWBeAKFGCkXE56yW0hJgLMfN2nCY0NtAb65sNFdKbvlOqUF5cF/U2ET7SFI",
+	"S2FsrEamZxUKXV7QG5r3YL65Zgqtf3PBPjMVu7rkXBEFQWO6cDuuGQSOcb3WYP5cEYpySJcjpe9Jhb8g",
+	"xN1mgxaF+RWDo+cWhMOQKDHZd01FQoGP9WzxOn+egog7Fx5MxYCVacIF1iRLORX+bMBE+XhDFLvNUy8z",
+	"5BnIcAUxZIqLmLsCcXbbohUl3hQI6MZMGMVj3193sekmDA5oK6ADFCche8anLqv5e2sUm4rgCyBaibOq",
+	"WPgTuU3BmAu59MJkIZPrQApDc1UhR/p7xdxGyOwEK0LloiIMee8uPErr0jjgBBOHl7bcnldE1q5Mboku",
+	"JMEU9zYV+KSp6zwmL89FKSPDl346bsK+hC1SSynmVGQCL/H0qlAVy/PMbDI3tj89GzAi3s2Kq6QLWtlm",
+	"Lux6BRrC84qKACPguofV8jy7dPd0OcTVFg5j362FTdcKUYYyoO4UW9SfvcA77rt2mdcycfTiRM2uRQlL",
+	"vGaIy/R6E3h9HyPvTgMnyFDcbsZ9zJU7wgtKcMvACCizjH4KtMJAFq6qk3NR6JN8jXsKhAoMfSg1j4qY",
+	"J3bYnNYMamUWd9SPL6a98lR6tLWrwuiSgLDyLBoBsDt0ldk/ByMydXX9bRLhNuW2sqHXmwol+EOOBqVk",
+	"ijvLLbBcZLkpyYku5J0XyFC+Q4iaJgH7s0KRCOb0AvN+rqanJEx3fA7dVMAGdqnc1Yg5urJs6V6FVXPs",
+	"IGi53fMuHAya0c894DI3WW4eBzyQyew45Jkr0bDnC/FlhU+pyFdCYEPD9/TEDgPWD9wbFgIv2fnQBcJ1",
+	"sIAjsueXZi+c+dweBuPEarE09QUhqzn07phIuUaB1r6McSdyZ5tHcqoXaLU5zSuaa3DVWD6lyujqZhtI",
+	"zoZGXp2wnjkIgytOWzu695AxTahNNI1dqCy5CW4POMFzkLwtSkzeIK3VRcoPtsl1jAU8exu6Tj8++5Jy",
+	"8enayGsnBD7sNb9yoiIl3lVWw1GBQBx2rEPtQOUp67jvbg77A9IltdttboqaUbZKqr8CaC6qvbuq+Vy3",
+	"V9r0NlnLGH76ibwZ/+Vf9Vw8ezP+iy7F0cTdVkddJcOayFvR8J5jP5cWMQRiR/RcvOFKG5Iouix3wmPs",
+	"x3olUx4zl+PjLv8fZzReMTLs9RtG1fv7+x7F11j92rXVe++mx5Ozq0l32Ov3oBy2DZMz6Fp4DAS4Fsnf",
+	"aBDd8ww9lFZV6MZhNddo1O9BqSOZMUEzHo2i/V6/t29dEis0F7fvMHiTSRu94e8Twc+laPVRbnHCFE33",
+	"trR7sMAp6l09RbJr9daQUurYei9T+UntVqPOk9/XLmWMoNSbO+Zfy2Szw01b5eVRj5duaiQFP1TdUkbl",
+	"DB+4DQg9DvvDHwOBHSNquV/qouV0LGL9fH3J73mt2UG/v61RgYu94gbifoRNBl/RZGCb7H9Fk33b5OAr",
+	"mhzYJkdf0eQImwyHuzcZDqHJ4VdMH74N3Ye4h7zz7sMOzi0sfqh9YECxR9G6xLo7CFWB+BN1IkNvNQa4",
+	"B24xm47Zzo326sFct8y0ueBNroRu2pfC1kVcPlTKBWVjZS8GuXEvwAhVaszPSZFrZla+pNCSp4YpDRk2",
+	"2mBhV7QmuyiGuXCXnV1i8Veb45WBEkfxjjHM6FpL3E/lB+VVoghDcUTCpkdFnJKbMm4Og24yqlGKuInd",
+	"M3dewmc4PXtQVbkrROQ9ylmrKMUUQluxqIpFLz8VIdkcPv8tZwpuxnUnYvFyN97UEgnw0PkKiMBT41fK",
+	"V9UqqittLROyBXbbgQ0M2An6rbWyn5hDNeGruDipNX5qC6xtASrfgPRp8lUILy5xMaiOu9rsXBOXht0G",
+	"qWuDhdx3BjGM4Phq6IqsyJ0Ae41f/1jIioTNr0EcNvoD0NbMJt0Brj8Ca8448TU4Y+KPIDTvQdwNX0x8",
+	"TxprluuqgGakg3gLMP6e4hKQog7NsL/16sbW22Aa6MooJFnZoymoeaerx9gC9UOfrQyH1rb9iQ0qsDai",
+	"o75SLfjYELP7303MrsfAt90SHK5URcpw8sX/E+L1HyTFYhRYTYiFhdkuJ/5eKXXvSyW+8MHuLlB62/Jg",
+	"bFo7Ja4oKpGqyG/fBmGPjP02R88N3pWFFyKgTXDD0IRHEq5jqhJmy7oAedkoctsJFgdwRZ/mAu8FL2L8",
+	"4Vu6XFb8GZUSUM6TxlUR5D8XFccExIFjYHi1sJO74NY1r6blQEwi+BJj5u9mhSAoWsCKIBaOtR6ZtZ75",
+	"m7mwke6hq9EDn+FlvyS8PqJNUraL8qisjIwKDCkln6oHlVY1+98hlP1O1vaHWxCOw5X7b8vBD7Mc/Gea",
+	"Aewa16+7ruorW5ho52kVfksqVLDLA0W+ygnmIvjod3OCt8z8V2ED/f8TDIm68MIlxLmSlnmabv6bK7Rz",
+	"hf9EGektM1v34VKqdoPfI6JTHbKtyQsfLCD28hD81HpUvtCMX0ppHvZqKe97d9YPckcVx0gLS974cUWz",
+	"QafMaG8P43pWUpvRUf9oEDVqbkIQjJS7uYSA5j4Ws27esQgA7Z1gGN4WI2mv5B0VnD18fPjfAwC6vAwJ",
+	"8KQAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -18,6 +18,7 @@ package main
 import (
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/labstack/echo/v4"
@@ -44,9 +45,26 @@ func main() {
 		return c.NoContent(http.StatusOK)
 	})
 
+	e.Use(middleware.Correlator())
 	e.Use(middleware.DebugBodyLogger())
 	e.Use(middleware.ZapLogger())
-	e.Use(middleware.JWT())
+
+	jwksRefreshInterval, err := time.ParseDuration(conf.Auth.JwksRefreshInterval)
+	if err != nil {
+		log.Warn("Invalid JWKS refresh interval, using default 1h",
+			zap.String("configured", conf.Auth.JwksRefreshInterval),
+			zap.Error(err))
+		jwksRefreshInterval = time.Hour
+	}
+	keys, err := middleware.NewJWKS(conf.Auth.JwksFile, conf.Auth.JwksUrl, jwksRefreshInterval)
+	if err != nil {
+		log.With(zap.Error(err)).Fatal("failed to load JWKS")
+	}
+	e.Use(middleware.JWT(middleware.JWTConfig{
+		Keys:     keys,
+		Issuer:   conf.Auth.Issuer,
+		Audience: conf.Auth.Audience,
+	}))
 
 	// Load OpenAPI spec from file for validation
 	specPath := os.Getenv("OPENAPI_SPEC_PATH")
@@ -65,7 +83,7 @@ func main() {
 	// Add OpenAPI validation middleware for request validation (skip healthz)
 	e.Use(echomiddleware.OapiRequestValidatorWithOptions(swagger, &echomiddleware.Options{
 		Options: openapi3filter.Options{
-			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			AuthenticationFunc: middleware.OAuth2Scopes,
 		},
 		Skipper: func(c echo.Context) bool {
 			// Skip validation for health check endpoint
@@ -79,7 +97,31 @@ func main() {
 			Fatal("failed to connect to mongo Database")
 	}
 
-	h, err := handler.New(db)
+	if _, err := conf.PowerSaving.Profile(""); err != nil {
+		log.With(zap.Error(err)).Fatal("invalid default power-saving profile")
+	}
+
+	idempotencyKeyTTL, err := time.ParseDuration(conf.Idempotency.KeyTTL)
+	if err != nil {
+		log.Warn("Invalid idempotency key TTL, using default 24h",
+			zap.String("configured", conf.Idempotency.KeyTTL),
+			zap.Error(err))
+		idempotencyKeyTTL = 24 * time.Hour
+	}
+
+	idempotencyKeyLease, err := time.ParseDuration(conf.Idempotency.KeyLease)
+	if err != nil {
+		log.Warn("Invalid idempotency key lease, using default 1m",
+			zap.String("configured", conf.Idempotency.KeyLease),
+			zap.Error(err))
+		idempotencyKeyLease = time.Minute
+	}
+
+	h, err := handler.New(db, &handler.Config{
+		IdempotencyKeyTTL:   idempotencyKeyTTL,
+		IdempotencyKeyLease: idempotencyKeyLease,
+		PowerSaving:         conf.PowerSaving,
+	})
 	if err != nil {
 		log.With(zap.Error(err)).
 			Fatal("failed to create api handler")
@@ -0,0 +1,173 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/receiver"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+)
+
+const usage = `Usage: deadletter <command> [arguments]
+
+Commands:
+  list [-consumer <source>] [-limit <n>]  List the dead letters, latest failure first
+  show <id>                               Print a dead letter and its event
+  replay <id>                             Publish the event of a dead letter again for its consumer (requires K_SINK)
+  discard <id>                            Remove a dead letter
+
+The consumer is the source of the consuming service, e.g. urn:tim:iot-worker.
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("missing command")
+	}
+
+	conf := config.GetConf()
+	db, err := database.NewMongoDB(conf.Database)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch args[0] {
+	case "list":
+		return list(ctx, db, args[1:])
+	case "show":
+		return withID(args[1:], func(id string) error { return show(ctx, db, id) })
+	case "replay":
+		return withID(args[1:], func(id string) error { return replay(ctx, db, id) })
+	case "discard":
+		return withID(args[1:], func(id string) error { return discard(ctx, db, id) })
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// withID runs a command taking the ID of a dead letter as its only argument.
+func withID(args []string, command func(id string) error) error {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("expected a dead letter ID")
+	}
+	return command(args[0])
+}
+
+func list(ctx context.Context, db database.Interface, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	consumer := flags.String("consumer", "", "only list the dead letters of this consumer")
+	limit := flags.Int("limit", database.DefaultPageLimit, "maximum number of dead letters listed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	deadLetters, err := db.ListDeadLetters(ctx, *consumer, *limit)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tATTEMPTS\tLAST FAILED\tREPLAYED\tERROR")
+	for _, dl := range deadLetters {
+		replayed := "-"
+		if dl.ReplayedAt != nil {
+			replayed = dl.ReplayedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			dl.ID, dl.Type, dl.Attempts, dl.LastFailedAt.Format(time.RFC3339), replayed, dl.Error)
+	}
+	return w.Flush()
+}
+
+func show(ctx context.Context, db database.Interface, id string) error {
+	deadLetter, err := db.GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Print the event as a JSON object rather than as a string
+	out := struct {
+		*database.DeadLetter
+		Event json.RawMessage `json:"event"`
+	}{deadLetter, json.RawMessage(deadLetter.Event)}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// replay publishes the event again to the broker, targeted at the consumer of the dead letter: the other
+// consumers receiving it acknowledge it without processing it. The dead letter is removed once the consumer
+// processes it.
+func replay(ctx context.Context, db database.Interface, id string) error {
+	deadLetter, err := db.GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var e cloudevents.Event
+	if err := json.Unmarshal([]byte(deadLetter.Event), &e); err != nil {
+		return fmt.Errorf("decode event: %w", err)
+	}
+	e.SetExtension(receiver.ReplayExtension, deadLetter.Consumer)
+
+	replayer, err := event.NewReplayer()
+	if err != nil {
+		return fmt.Errorf("failed to create event replayer: %w", err)
+	}
+	if err := replayer.Replay(ctx, e); err != nil {
+		return fmt.Errorf("replay event: %w", err)
+	}
+
+	// The dead letter is already gone when the consumer has processed the event in the meantime
+	if err := db.MarkDeadLetterReplayed(ctx, id); err != nil && !errors.Is(err, database.ErrDeadLetterNotFound) {
+		return fmt.Errorf("mark dead letter replayed: %w", err)
+	}
+	fmt.Printf("Replayed %s\n", id)
+	return nil
+}
+
+func discard(ctx context.Context, db database.Interface, id string) error {
+	if err := db.DeleteDeadLetter(ctx, id); err != nil {
+		return err
+	}
+	fmt.Printf("Discarded %s\n", id)
+	return nil
+}
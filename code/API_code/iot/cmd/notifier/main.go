@@ -59,6 +59,17 @@ func run() error {
 	}
 	log.Info("Event receiver initialized", zap.String("address", conf.API.Address))
 
+	// Ignore the dead letters replayed for other consumers, before deduplication so that they are not
+	// recorded as processed
+	receiver = event.FilterReplays(receiver, event.SourceiotNotify)
+
+	// Acknowledge redelivered events without processing them again
+	receiver = event.Deduplicate(receiver, db, event.SourceiotNotify, conf.Idempotency)
+
+	// Keep the events failing to process as dead letters, after deduplication so that the duplicates
+	// deferred while in progress are not recorded
+	receiver = event.WithDeadLetters(receiver, db, event.SourceiotNotify)
+
 	// Create notification worker
 	notificationWorker := notifier.New(db, receiver)
 
@@ -30,6 +30,7 @@ import (
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/metrics"
 )
 
 func main() {
@@ -67,6 +68,17 @@ func run() error {
 	}
 	log.Info("Event receiver initialized", zap.String("address", conf.API.Address))
 
+	// Ignore the dead letters replayed for other consumers, before deduplication so that they are not
+	// recorded as processed
+	receiver = event.FilterReplays(receiver, event.SourceiotScheduler)
+
+	// Acknowledge redelivered events without processing them again
+	receiver = event.Deduplicate(receiver, db, event.SourceiotScheduler, conf.Idempotency)
+
+	// Keep the events failing to process as dead letters, after deduplication so that the duplicates
+	// deferred while in progress are not recorded
+	receiver = event.WithDeadLetters(receiver, db, event.SourceiotScheduler)
+
 	// Parse retention configuration
 	retentionPeriod, err := time.ParseDuration(conf.Retention.Period)
 	if err != nil {
@@ -84,14 +96,96 @@ func run() error {
 		cleanupInterval = 1 * time.Hour
 	}
 
+	// Parse scheduled actions configuration
+	pollInterval, err := time.ParseDuration(conf.Scheduler.PollInterval)
+	if err != nil {
+		log.Warn("Invalid poll interval, using default 1s",
+			zap.String("configured", conf.Scheduler.PollInterval),
+			zap.Error(err))
+		pollInterval = 1 * time.Second
+	}
+
+	leaseDuration, err := time.ParseDuration(conf.Scheduler.LeaseDuration)
+	if err != nil {
+		log.Warn("Invalid lease duration, using default 1m",
+			zap.String("configured", conf.Scheduler.LeaseDuration),
+			zap.Error(err))
+		leaseDuration = 1 * time.Minute
+	}
+
+	leaderLeaseDuration, err := time.ParseDuration(conf.Scheduler.LeaderLeaseDuration)
+	if err != nil {
+		log.Warn("Invalid leader lease duration, using default 15s",
+			zap.String("configured", conf.Scheduler.LeaderLeaseDuration),
+			zap.Error(err))
+		leaderLeaseDuration = 15 * time.Second
+	}
+
+	leaderRenewInterval, err := time.ParseDuration(conf.Scheduler.LeaderRenewInterval)
+	if err != nil {
+		log.Warn("Invalid leader renew interval, using default 5s",
+			zap.String("configured", conf.Scheduler.LeaderRenewInterval),
+			zap.Error(err))
+		leaderRenewInterval = 5 * time.Second
+	}
+	if leaderRenewInterval >= leaderLeaseDuration {
+		return fmt.Errorf("leader renew interval %s must be shorter than the leader lease duration %s", leaderRenewInterval, leaderLeaseDuration)
+	}
+
+	fanOutJitter, err := time.ParseDuration(conf.Scheduler.FanoutJitter)
+	if err != nil {
+		log.Warn("Invalid fan-out jitter, using no jitter",
+			zap.String("configured", conf.Scheduler.FanoutJitter),
+			zap.Error(err))
+		fanOutJitter = 0
+	}
+
+	watchdogInterval, err := time.ParseDuration(conf.Scheduler.WatchdogInterval)
+	if err != nil {
+		log.Warn("Invalid watchdog interval, using default 1m",
+			zap.String("configured", conf.Scheduler.WatchdogInterval),
+			zap.Error(err))
+		watchdogInterval = 1 * time.Minute
+	}
+
+	actionTimeout, err := time.ParseDuration(conf.Scheduler.ActionTimeout)
+	if err != nil {
+		log.Warn("Invalid action timeout, using default 5m",
+			zap.String("configured", conf.Scheduler.ActionTimeout),
+			zap.Error(err))
+		actionTimeout = 5 * time.Minute
+	}
+
 	// Create scheduler with custom config
 	schedulerCfg := &scheduler.Config{
-		WorkerCount:     10,  // number of workers processing fired schedules
-		ChannelSize:     100, // buffered channel size
+		WorkerCount:     10, // number of workers firing claimed actions
 		RetentionPeriod: retentionPeriod,
 		CleanupInterval: cleanupInterval,
+		PollInterval:    pollInterval,
+		LeaseDuration:   leaseDuration,
+
+		LeaderLeaseDuration: leaderLeaseDuration,
+		LeaderRenewInterval: leaderRenewInterval,
+
+		FanOutRate:         conf.Scheduler.FanoutRate,
+		FanOutJitter:       fanOutJitter,
+		FanOutBackendRates: conf.Scheduler.FanoutBackendRates,
+
+		WatchdogInterval:  watchdogInterval,
+		ActionTimeout:     actionTimeout,
+		ActionMaxAttempts: conf.Scheduler.ActionMaxAttempts,
 	}
 
+	log.Info("Fan-out configuration",
+		zap.Float64("rate", conf.Scheduler.FanoutRate),
+		zap.Duration("jitter", fanOutJitter),
+		zap.Any("backendRates", conf.Scheduler.FanoutBackendRates))
+
+	log.Info("Watchdog configuration",
+		zap.Duration("interval", watchdogInterval),
+		zap.Duration("actionTimeout", actionTimeout),
+		zap.Int("actionMaxAttempts", conf.Scheduler.ActionMaxAttempts))
+
 	log.Info("Retention configuration",
 		zap.Duration("retentionPeriod", retentionPeriod),
 		zap.Duration("cleanupInterval", cleanupInterval))
@@ -107,6 +201,14 @@ func run() error {
 
 	// Start scheduler in goroutine
 	errChan := make(chan error, 1)
+	if conf.Metrics.Address != "" {
+		go func() {
+			if err := metrics.Serve(conf.Metrics.Address); err != nil {
+				errChan <- fmt.Errorf("metrics server error: %w", err)
+			}
+		}()
+		log.Info("Metrics endpoint initialized", zap.String("address", conf.Metrics.Address), zap.String("path", metrics.Path))
+	}
 	go func() {
 		if err := sched.Start(ctx); err != nil {
 			errChan <- fmt.Errorf("scheduler error: %w", err)
@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -56,9 +57,62 @@ func run() error {
 	// Initialize device client
 	var deviceClient easyapi.Client
 	if conf.EasyAPI.BaseURL != "" {
-		deviceClient = easyapi.New(conf.EasyAPI.BaseURL)
+		retryBaseDelay, err := time.ParseDuration(conf.EasyAPI.RetryBaseDelay)
+		if err != nil {
+			log.Warn("Invalid EasyAPI retry base delay, using default 200ms",
+				zap.String("configured", conf.EasyAPI.RetryBaseDelay),
+				zap.Error(err))
+			retryBaseDelay = 200 * time.Millisecond
+		}
+
+		retryMaxDelay, err := time.ParseDuration(conf.EasyAPI.RetryMaxDelay)
+		if err != nil {
+			log.Warn("Invalid EasyAPI retry max delay, using default 10s",
+				zap.String("configured", conf.EasyAPI.RetryMaxDelay),
+				zap.Error(err))
+			retryMaxDelay = 10 * time.Second
+		}
+
+		breakerWindow, err := time.ParseDuration(conf.EasyAPI.BreakerWindow)
+		if err != nil {
+			log.Warn("Invalid EasyAPI circuit breaker window, using default 1m",
+				zap.String("configured", conf.EasyAPI.BreakerWindow),
+				zap.Error(err))
+			breakerWindow = 1 * time.Minute
+		}
+
+		breakerOpenDuration, err := time.ParseDuration(conf.EasyAPI.BreakerOpenDuration)
+		if err != nil {
+			log.Warn("Invalid EasyAPI circuit breaker open duration, using default 30s",
+				zap.String("configured", conf.EasyAPI.BreakerOpenDuration),
+				zap.Error(err))
+			breakerOpenDuration = 30 * time.Second
+		}
+
+		deviceClient = easyapi.New(conf.EasyAPI.BaseURL, easyapi.RetryPolicy{
+			MaxAttempts: conf.EasyAPI.RetryMaxAttempts,
+			BaseDelay:   retryBaseDelay,
+			MaxDelay:    retryMaxDelay,
+		})
 		log.Info("Device client initialized (EasyAPI mode)",
-			zap.String("baseURL", conf.EasyAPI.BaseURL))
+			zap.String("baseURL", conf.EasyAPI.BaseURL),
+			zap.Int("retryMaxAttempts", conf.EasyAPI.RetryMaxAttempts))
+
+		breakerPolicy := easyapi.BreakerPolicy{
+			FailureThreshold: conf.EasyAPI.BreakerFailureThreshold,
+			ErrorRate:        conf.EasyAPI.BreakerErrorRate,
+			MinRequests:      conf.EasyAPI.BreakerMinRequests,
+			Window:           breakerWindow,
+			OpenDuration:     breakerOpenDuration,
+		}
+		if breakerPolicy.Enabled() {
+			deviceClient = easyapi.NewBreaker(deviceClient, breakerPolicy)
+			log.Info("EasyAPI circuit breaker enabled",
+				zap.Int("failureThreshold", breakerPolicy.FailureThreshold),
+				zap.Float64("errorRate", breakerPolicy.ErrorRate),
+				zap.Duration("openDuration", breakerPolicy.OpenDuration),
+				zap.Bool("requeue", conf.EasyAPI.BreakerRequeue))
+		}
 	} else {
 		deviceClient = easyapi.NewDummy()
 		log.Info("Device client initialized (DUMMY mode - no real API calls)")
@@ -78,11 +132,56 @@ func run() error {
 	}
 	log.Info("Event receiver initialized", zap.String("address", conf.API.Address))
 
+	// Ignore the dead letters replayed for other consumers, before deduplication so that they are not
+	// recorded as processed
+	receiver = event.FilterReplays(receiver, event.SourceiotWorker)
+
+	// Acknowledge redelivered events without processing them again
+	receiver = event.Deduplicate(receiver, db, event.SourceiotWorker, conf.Idempotency)
+
+	// Keep the events failing to process as dead letters, after deduplication so that the duplicates
+	// deferred while in progress are not recorded
+	receiver = event.WithDeadLetters(receiver, db, event.SourceiotWorker)
+
+	if _, err := conf.PowerSaving.Profile(""); err != nil {
+		return fmt.Errorf("invalid default power-saving profile: %w", err)
+	}
+	if err := conf.PowerSaving.CheckDriftPolicy(); err != nil {
+		return fmt.Errorf("invalid power-saving configuration: %w", err)
+	}
+
+	deviceLeaseDuration, err := time.ParseDuration(conf.Worker.DeviceLeaseDuration)
+	if err != nil {
+		log.Warn("Invalid device lease duration, using default 2m",
+			zap.String("configured", conf.Worker.DeviceLeaseDuration),
+			zap.Error(err))
+		deviceLeaseDuration = 2 * time.Minute
+	}
+
+	deviceWait, err := time.ParseDuration(conf.Worker.DeviceWait)
+	if err != nil {
+		log.Warn("Invalid device wait, using default 1m",
+			zap.String("configured", conf.Worker.DeviceWait),
+			zap.Error(err))
+		deviceWait = time.Minute
+	}
+
 	// Create actuation worker
-	actuationWorker := worker.New(db, deviceClient, sender, receiver, conf.PowerSaving)
+	actuationWorker := worker.New(db, deviceClient, sender, receiver, conf.PowerSaving, conf.EasyAPI, worker.Limits{
+		MaxConcurrency:      conf.Worker.MaxConcurrency,
+		DeviceLeaseDuration: deviceLeaseDuration,
+		DeviceWait:          deviceWait,
+	})
+	log.Info("Worker limits configured",
+		zap.Int("maxConcurrency", conf.Worker.MaxConcurrency),
+		zap.Duration("deviceLeaseDuration", deviceLeaseDuration),
+		zap.Duration("deviceWait", deviceWait))
 	log.Info("Power saving configuration loaded",
 		zap.String("maxLatency", conf.PowerSaving.MaxLatency),
-		zap.String("maxResponseTime", conf.PowerSaving.MaxResponseTime))
+		zap.String("maxResponseTime", conf.PowerSaving.MaxResponseTime),
+		zap.Int("profiles", len(conf.PowerSaving.Profiles)),
+		zap.String("defaultProfile", conf.PowerSaving.DefaultProfile),
+		zap.String("driftPolicy", conf.PowerSaving.DriftPolicy))
 
 	// Setup graceful shutdown
 	_ = context.Background() // context not currently used but available for future use
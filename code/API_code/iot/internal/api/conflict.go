@@ -0,0 +1,43 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+// formatConflicts builds the 409 message listing each clashing device with the window it is already scheduled in.
+// The transactions of other owners are not named.
+func formatConflicts(conflicts []database.DeviceConflict) string {
+	parts := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		end := "open-ended"
+		if c.EndAt != nil {
+			end = c.EndAt.UTC().Format(time.RFC3339)
+		}
+		transaction := "transaction " + c.TransactionID
+		if c.OtherOwner {
+			transaction = "a transaction of another consumer"
+		}
+		parts = append(parts, fmt.Sprintf("device %s is in %s from %s to %s",
+			c.DeviceID, transaction, c.StartAt.UTC().Format(time.RFC3339), end))
+	}
+	return "requested time window overlaps active transactions: " + strings.Join(parts, "; ")
+}
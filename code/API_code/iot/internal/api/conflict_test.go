@@ -0,0 +1,69 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+func TestFormatConflicts(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		conflicts []database.DeviceConflict
+		expected  string
+	}{
+		{
+			name: "bounded window",
+			conflicts: []database.DeviceConflict{
+				{DeviceID: "dev1@example.com", TransactionID: "tx1", StartAt: start, EndAt: &end},
+			},
+			expected: "requested time window overlaps active transactions: " +
+				"device dev1@example.com is in transaction tx1 from 2025-03-01T10:00:00Z to 2025-03-01T12:00:00Z",
+		},
+		{
+			name: "open-ended window and multiple devices",
+			conflicts: []database.DeviceConflict{
+				{DeviceID: "dev1@example.com", TransactionID: "tx1", StartAt: start},
+				{DeviceID: "dev2@example.com", TransactionID: "tx2", StartAt: start, EndAt: &end},
+			},
+			expected: "requested time window overlaps active transactions: " +
+				"device dev1@example.com is in transaction tx1 from 2025-03-01T10:00:00Z to open-ended; " +
+				"device dev2@example.com is in transaction tx2 from 2025-03-01T10:00:00Z to 2025-03-01T12:00:00Z",
+		},
+		{
+			name: "transaction of another owner",
+			conflicts: []database.DeviceConflict{
+				{DeviceID: "dev1@example.com", TransactionID: "tx1", StartAt: start, EndAt: &end, OtherOwner: true},
+			},
+			expected: "requested time window overlaps active transactions: " +
+				"device dev1@example.com is in a transaction of another consumer from 2025-03-01T10:00:00Z to 2025-03-01T12:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatConflicts(tt.conflicts))
+		})
+	}
+}
@@ -16,6 +16,8 @@ limitations under the License.
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -27,22 +29,52 @@ import (
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/server"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/deviceidentifier"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/middleware"
 )
 
 var _ server.ServerInterface = &handler{}
 
-func New(db database.Interface) (*handler, error) {
+const (
+	defaultIdempotencyKeyTTL   = 24 * time.Hour
+	defaultIdempotencyKeyLease = 1 * time.Minute
+
+	// acceptAttempts is how many times the acceptance of an idempotency key is recorded before giving up
+	acceptAttempts = 3
+)
+
+// Config holds the API handler configuration.
+type Config struct {
+	// IdempotencyKeyTTL is how long an Idempotency-Key is remembered.
+	IdempotencyKeyTTL time.Duration
+	// IdempotencyKeyLease is how long an Idempotency-Key is held by a request that has not been accepted.
+	IdempotencyKeyLease time.Duration
+	// PowerSaving holds the profiles requests can select.
+	PowerSaving config.PowerSaving
+}
+
+func New(db database.Interface, cfg *Config) (*handler, error) {
 	sender, err := event.NewSender()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cloud event sender: %w", err)
 	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if cfg.IdempotencyKeyTTL == 0 {
+		cfg.IdempotencyKeyTTL = defaultIdempotencyKeyTTL
+	}
+	if cfg.IdempotencyKeyLease == 0 {
+		cfg.IdempotencyKeyLease = defaultIdempotencyKeyLease
+	}
 	return &handler{
 		events:     sender,
 		database:   db,
 		translator: deviceidentifier.NewMockTranslator(),
+		config:     *cfg,
 	}, nil
 }
 
@@ -50,12 +82,13 @@ type handler struct {
 	database   database.Interface
 	events     event.Sender
 	translator deviceidentifier.Translator
+	config     Config
 }
 
 // ActivatePowerSaving implements server.ServerInterface.
 // It validates the request, creates a stable scheduleId, publishes a schedule.requested event, and returns 202 Accepted immediately.
 func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePowerSavingParams) error {
-	log := logger.Get()
+	log := logger.FromContext(ctx.Request().Context())
 
 	var req models.PowerSavingRequest
 	if err := ctx.Bind(&req); err != nil {
@@ -67,6 +100,17 @@ func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePo
 		})
 	}
 
+	// Fingerprint the request as received, before identifiers are resolved
+	requestHash, err := hashRequest(&req)
+	if err != nil {
+		log.Error("Failed to hash request", zap.Error(err))
+		return ctx.JSON(http.StatusInternalServerError, models.ErrorInfo{
+			Status:  http.StatusInternalServerError,
+			Code:    "INTERNAL",
+			Message: "failed to process request",
+		})
+	}
+
 	// Validate format fields (IPv4, IPv6) or types with AllOf/AnyOf
 	if err := validatePowerSavingRequest(&req); err != nil {
 		log.Error("Format validation failed", zap.Error(err))
@@ -107,6 +151,22 @@ func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePo
 		})
 	}
 
+	// Resolve the power-saving profile, recording the default profile by name. Its settings are kept with the
+	// transaction, so that a later change of the profiles does not affect it
+	profile := h.config.PowerSaving.DefaultProfile
+	if req.Profile != nil && *req.Profile != "" {
+		profile = *req.Profile
+	}
+	profileSettings, err := h.config.PowerSaving.Profile(profile)
+	if err != nil {
+		log.Warn("Invalid power-saving profile", zap.Error(err))
+		return ctx.JSON(http.StatusBadRequest, models.ErrorInfo{
+			Status:  http.StatusBadRequest,
+			Code:    "INVALID_ARGUMENT",
+			Message: err.Error(),
+		})
+	}
+
 	// Validate request
 	if len(req.Devices) == 0 {
 		return ctx.JSON(http.StatusBadRequest, models.ErrorInfo{
@@ -150,6 +210,66 @@ func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePo
 		deviceIDs = append(deviceIDs, deviceID)
 	}
 
+	transactionID := uuid.New().String()
+	owner := middleware.CtxSub(ctx.Request().Context())
+
+	// Replay the original response for a retried request carrying the same Idempotency-Key. The key is held
+	// for a short lease until the request is accepted, so that a request that stopped does not block the retries
+	accepted := false
+	var record *database.IdempotencyKey
+	if params.IdempotencyKey != nil {
+		record = &database.IdempotencyKey{
+			ID:            idempotencyRecordID(owner, *params.IdempotencyKey),
+			RequestHash:   requestHash,
+			TransactionID: transactionID,
+			ExpiresAt:     time.Now().Add(h.config.IdempotencyKeyLease),
+		}
+		existing, err := h.database.ReserveIdempotencyKey(ctx.Request().Context(), record)
+		if err != nil {
+			log.Error("Failed to reserve idempotency key", zap.Error(err))
+			return ctx.JSON(http.StatusInternalServerError, models.ErrorInfo{
+				Status:  http.StatusInternalServerError,
+				Code:    "INTERNAL",
+				Message: "failed to process idempotency key",
+			})
+		}
+		if existing != nil {
+			if existing.RequestHash != requestHash {
+				log.Warn("Idempotency key reused with a different request body",
+					zap.String("transactionId", existing.TransactionID))
+				return ctx.JSON(http.StatusUnprocessableEntity, models.ErrorInfo{
+					Status:  http.StatusUnprocessableEntity,
+					Code:    "IDEMPOTENCY_KEY_MISMATCH",
+					Message: "Idempotency-Key already used with a different request body",
+				})
+			}
+			// The transaction ID of a request in flight is only valid once the request has been accepted
+			if !existing.Accepted {
+				log.Warn("Idempotency key held by a request in flight",
+					zap.String("transactionId", existing.TransactionID))
+				return ctx.JSON(http.StatusConflict, models.ErrorInfo{
+					Status:  http.StatusConflict,
+					Code:    "CONFLICT",
+					Message: "a request with the same Idempotency-Key is being processed, retry later",
+				})
+			}
+			log.Info("Replaying idempotent request", zap.String("transactionId", existing.TransactionID))
+			return ctx.JSON(http.StatusAccepted, models.PowerSavingResponse{
+				TransactionId: &existing.TransactionID,
+			})
+		}
+
+		// Release the key if the request is not accepted, so that it can be retried
+		defer func() {
+			if accepted {
+				return
+			}
+			if err := h.database.DeleteIdempotencyKey(context.WithoutCancel(ctx.Request().Context()), record.ID, record.TransactionID); err != nil {
+				log.Error("Failed to release idempotency key", zap.Error(err))
+			}
+		}()
+	}
+
 	// If enabled is false, verify all devices have stored configurations
 	if !req.Enabled {
 		missingConfigs, err := h.database.CheckDeviceConfigsExist(ctx.Request().Context(), deviceIDs)
@@ -172,30 +292,6 @@ func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePo
 			})
 		}
 	}
-	// Check for conflicting transactions
-	conflicts, err := h.database.CheckDeviceConflicts(ctx.Request().Context(), deviceIDs)
-	if err != nil {
-		log.Error("Failed to check device conflicts", zap.Error(err))
-		return ctx.JSON(http.StatusInternalServerError, models.ErrorInfo{
-			Status:  http.StatusInternalServerError,
-			Code:    "INTERNAL",
-			Message: "failed to check device availability",
-		})
-	}
-
-	if len(conflicts) > 0 {
-		log.Warn("Request contains devices already in active transactions",
-			zap.Strings("conflictingTransactions", conflicts),
-			zap.Int("conflictCount", len(conflicts)))
-		return ctx.JSON(http.StatusConflict, models.ErrorInfo{
-			Status:  http.StatusConflict,
-			Code:    "CONFLICT",
-			Message: fmt.Sprintf("one or more devices are already in use by active transactions: %v", conflicts),
-		})
-	}
-
-	// Generate stable scheduleId from request content
-	transactionID := uuid.New().String()
 
 	var startAt time.Time
 	var endAt *time.Time
@@ -236,6 +332,39 @@ func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePo
 		startAt = time.Now()
 	}
 
+	// A recurring request must have at least one occurrence within its time period
+	if req.Recurrence != nil {
+		if err := validateRecurrence(*req.Recurrence, startAt, endAt); err != nil {
+			log.Warn("Invalid recurrence", zap.Error(err))
+			return ctx.JSON(http.StatusBadRequest, models.ErrorInfo{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_ARGUMENT",
+				Message: fmt.Sprintf("invalid recurrence: %v", err),
+			})
+		}
+	}
+
+	// Check for transactions using the same devices in an overlapping time window, or occurrence window
+	conflicts, err := h.database.CheckDeviceConflicts(ctx.Request().Context(), deviceIDs, owner, startAt, endAt, req.Recurrence)
+	if err != nil {
+		log.Error("Failed to check device conflicts", zap.Error(err))
+		return ctx.JSON(http.StatusInternalServerError, models.ErrorInfo{
+			Status:  http.StatusInternalServerError,
+			Code:    "INTERNAL",
+			Message: "failed to check device availability",
+		})
+	}
+
+	if len(conflicts) > 0 {
+		log.Warn("Request overlaps active transactions on the same devices",
+			zap.Int("conflictCount", len(conflicts)))
+		return ctx.JSON(http.StatusConflict, models.ErrorInfo{
+			Status:  http.StatusConflict,
+			Code:    "CONFLICT",
+			Message: formatConflicts(conflicts),
+		})
+	}
+
 	// Prepare schedule.requested event payload
 	scheduleData := event.ScheduleRequestedData{
 		StartAt: startAt,
@@ -245,6 +374,13 @@ func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePo
 			Enabled:             req.Enabled,
 			TransactionID:       transactionID,
 			SubscriptionRequest: req.SubscriptionRequest,
+			Owner:               owner,
+			Recurrence:          req.Recurrence,
+			Profile:             profile,
+			ProfileSettings:     &profileSettings,
+			FanOut:              req.FanOut,
+			Canary:              req.Canary,
+			Atomic:              req.Atomic != nil && *req.Atomic,
 		},
 	}
 
@@ -264,6 +400,11 @@ func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePo
 		})
 	}
 
+	accepted = true
+	if record != nil {
+		h.acceptIdempotencyKey(context.WithoutCancel(ctx.Request().Context()), record)
+	}
+
 	log.Info("Schedule requested",
 		zap.String("transactionId", transactionID),
 		zap.Time("startAt", startAt))
@@ -276,15 +417,37 @@ func (h *handler) ActivatePowerSaving(ctx echo.Context, params models.ActivatePo
 	return ctx.JSON(http.StatusAccepted, response)
 }
 
+// acceptIdempotencyKey records that the request holding an idempotency key has been accepted, so that its
+// retries get the same transaction. The transaction is scheduled already: recording the acceptance is retried,
+// and the key is released for good by its lease when it cannot be recorded.
+func (h *handler) acceptIdempotencyKey(ctx context.Context, record *database.IdempotencyKey) {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", record.TransactionID))
+
+	expiresAt := time.Now().Add(h.config.IdempotencyKeyTTL)
+	var err error
+	for attempt := 1; attempt <= acceptAttempts; attempt++ {
+		err = h.database.AcceptIdempotencyKey(ctx, record.ID, record.TransactionID, expiresAt)
+		if err == nil || errors.Is(err, database.ErrIdempotencyKeyLost) {
+			break
+		}
+		log.Warn("Failed to record accepted idempotency key, retrying", zap.Int("attempt", attempt), zap.Error(err))
+		time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+	}
+	if err != nil {
+		log.Error("Failed to record accepted idempotency key", zap.Error(err))
+	}
+}
+
 // GetPowerSaving implements server.ServerInterface.
 func (h *handler) GetPowerSaving(ctx echo.Context, transactionId models.TransactionId, params models.GetPowerSavingParams) error {
-	log := logger.Get()
+	log := logger.FromContext(ctx.Request().Context())
 
 	transactionIDStr := transactionId.String()
 	log.Info("Get power saving request", zap.String("transactionId", transactionIDStr))
 
 	// Retrieve transaction from database
-	transaction, err := h.database.GetTransaction(ctx.Request().Context(), transactionIDStr)
+	// Transactions of other owners are reported as not found
+	transaction, err := h.database.GetOwnedTransaction(ctx.Request().Context(), transactionIDStr, middleware.CtxSub(ctx.Request().Context()))
 	if err != nil {
 		log.Error("Failed to get transaction", zap.Error(err), zap.String("transactionId", transactionIDStr))
 		return ctx.JSON(http.StatusNotFound, models.ErrorInfo{
@@ -294,63 +457,177 @@ func (h *handler) GetPowerSaving(ctx echo.Context, transactionId models.Transact
 		})
 	}
 
-	// Build device status array
-	activationStatus := make([]models.DeviceStatus, 0, len(transaction.Devices))
-
-	for _, txDevice := range transaction.Devices {
-		// Determine device status based on completion state and results
-		var status models.DeviceStatusStatus
-
-		// If END action exists, use END status (transaction with END has completed or END is in progress)
-		if txDevice.EndAction != nil {
-			switch txDevice.EndAction.Status {
-			case "success":
-				status = models.Success
-			case "failed":
-				status = models.Failed
-			case "in-progress":
-				status = models.InProgress
-			case "pending":
-				status = models.Pending
-			default:
-				status = models.Failed
-			}
-		} else if txDevice.StartAction != nil {
-			switch txDevice.StartAction.Status {
-			case "success":
-				if transaction.EndAt != nil {
-					// END is scheduled but hasn't run yet
-					status = models.InProgress
-				} else {
-					// No END scheduled, START success = complete success
-					status = models.Success
-				}
-			case "failed":
-				// START failed = device failed (END won't help)
-				status = models.Failed
-			case "in-progress":
-				status = models.InProgress
-			case "pending":
-				status = models.Pending
-			default:
-				status = models.Failed
-			}
-		} else {
-			// No action status yet - default to pending
-			status = models.Pending
-		}
-
-		activationStatus = append(activationStatus, models.DeviceStatus{
-			Device: &txDevice.Device,
-			Status: &status,
-		})
-	}
+	activationStatus := deviceStatuses(transaction)
+	status := models.TransactionStatus(transaction.Status)
+	statusHistory := statusTransitions(transaction)
 
 	// Build response
 	response := models.PowerSavingResponse{
 		ActivationStatus: &activationStatus,
 		TransactionId:    &transactionIDStr,
+		Status:           &status,
+		StatusHistory:    &statusHistory,
+	}
+	if transaction.ParentTransactionID != "" {
+		response.ParentTransactionId = &transaction.ParentTransactionID
+	}
+	if transaction.Profile != "" {
+		response.Profile = &transaction.Profile
 	}
 
 	return ctx.JSON(http.StatusOK, response)
 }
+
+// ListPowerSaving implements server.ServerInterface.
+// It returns a page of transactions matching the query filters, newest first.
+func (h *handler) ListPowerSaving(ctx echo.Context, params models.ListPowerSavingParams) error {
+	log := logger.FromContext(ctx.Request().Context())
+
+	// Only the caller's transactions are listed
+	filter := database.TransactionFilter{
+		Owner:         middleware.CtxSub(ctx.Request().Context()),
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+		StartAfter:    params.StartAfter,
+		StartBefore:   params.StartBefore,
+		EndAfter:      params.EndAfter,
+		EndBefore:     params.EndBefore,
+	}
+	if params.Status != nil {
+		filter.Status = database.Status(*params.Status)
+	}
+	if params.DeviceId != nil {
+		filter.DeviceID = string(*params.DeviceId)
+	}
+	if params.ParentTransactionId != nil {
+		filter.ParentTransactionID = params.ParentTransactionId.String()
+	}
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+	}
+	if params.Cursor != nil {
+		after, err := decodeCursor(*params.Cursor)
+		if err != nil {
+			log.Warn("Invalid pagination cursor", zap.Error(err))
+			return ctx.JSON(http.StatusBadRequest, models.ErrorInfo{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_ARGUMENT",
+				Message: fmt.Sprintf("invalid cursor: %v", err),
+			})
+		}
+		filter.After = after
+	}
+
+	transactions, next, err := h.database.ListTransactions(ctx.Request().Context(), filter)
+	if err != nil {
+		log.Error("Failed to list transactions", zap.Error(err))
+		return ctx.JSON(http.StatusInternalServerError, models.ErrorInfo{
+			Status:  http.StatusInternalServerError,
+			Code:    "INTERNAL",
+			Message: "failed to list transactions",
+		})
+	}
+
+	summaries := make([]models.TransactionSummary, 0, len(transactions))
+	for _, transaction := range transactions {
+		activationStatus := deviceStatuses(transaction)
+		summary := models.TransactionSummary{
+			TransactionId:    transaction.TransactionID,
+			Status:           models.TransactionStatus(transaction.Status),
+			Enabled:          &transaction.Enabled,
+			StartDate:        &transaction.StartAt,
+			EndDate:          transaction.EndAt,
+			CreatedAt:        &transaction.CreatedAt,
+			UpdatedAt:        &transaction.UpdatedAt,
+			ActivationStatus: &activationStatus,
+			Recurrence:       transaction.Recurrence,
+		}
+		if transaction.Owner != "" {
+			summary.Owner = &transaction.Owner
+		}
+		if transaction.ParentTransactionID != "" {
+			summary.ParentTransactionId = &transaction.ParentTransactionID
+		}
+		if transaction.Profile != "" {
+			summary.Profile = &transaction.Profile
+		}
+		summaries = append(summaries, summary)
+	}
+
+	response := models.TransactionList{
+		Transactions: summaries,
+	}
+	if next != nil {
+		nextCursor, err := encodeCursor(next)
+		if err != nil {
+			log.Error("Failed to encode pagination cursor", zap.Error(err))
+			return ctx.JSON(http.StatusInternalServerError, models.ErrorInfo{
+				Status:  http.StatusInternalServerError,
+				Code:    "INTERNAL",
+				Message: "failed to list transactions",
+			})
+		}
+		response.NextCursor = &nextCursor
+	}
+
+	log.Info("Listed transactions", zap.Int("count", len(summaries)), zap.Bool("hasMore", next != nil))
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// CancelPowerSaving implements server.ServerInterface.
+// It moves the transaction to the cancelled status and publishes a cancel.requested event so that the
+// scheduler unschedules pending actions and restores devices already moved to power-saving. The transaction
+// is cancelled even when the event cannot be published, and the scheduler follows up on it.
+func (h *handler) CancelPowerSaving(ctx echo.Context, transactionId models.TransactionId, params models.CancelPowerSavingParams) error {
+	log := logger.FromContext(ctx.Request().Context())
+
+	transactionIDStr := transactionId.String()
+	log.Info("Cancel power saving request", zap.String("transactionId", transactionIDStr))
+
+	transaction, err := h.database.CancelTransaction(ctx.Request().Context(), transactionIDStr, middleware.CtxSub(ctx.Request().Context()))
+	if err != nil {
+		if errors.Is(err, database.ErrTransactionNotFound) {
+			return ctx.JSON(http.StatusNotFound, models.ErrorInfo{
+				Status:  http.StatusNotFound,
+				Code:    "NOT_FOUND",
+				Message: "transaction not found",
+			})
+		}
+		if errors.Is(err, database.ErrTransactionNotCancellable) {
+			log.Warn("Transaction cannot be cancelled", zap.String("transactionId", transactionIDStr))
+			return ctx.JSON(http.StatusConflict, models.ErrorInfo{
+				Status:  http.StatusConflict,
+				Code:    "CONFLICT",
+				Message: "transaction has already reached a final status",
+			})
+		}
+		log.Error("Failed to cancel transaction", zap.Error(err), zap.String("transactionId", transactionIDStr))
+		return ctx.JSON(http.StatusInternalServerError, models.ErrorInfo{
+			Status:  http.StatusInternalServerError,
+			Code:    "INTERNAL",
+			Message: "failed to cancel transaction",
+		})
+	}
+
+	err = h.events.Send(
+		ctx.Request().Context(),
+		transactionIDStr+"-cancel",
+		event.EventTypeCancelRequested,
+		event.SourceiotAPI,
+		event.CancelRequestedData{TransactionID: transaction.TransactionID},
+	)
+	if err != nil {
+		// The cancellation is recorded: the scheduler handles it once the request is overdue
+		log.Warn("Failed to send cancel.requested event, leaving the cancellation to the scheduler",
+			zap.Error(err), zap.String("transactionId", transactionIDStr))
+	} else {
+		log.Info("Cancel requested", zap.String("transactionId", transactionIDStr))
+	}
+
+	response := models.PowerSavingResponse{
+		TransactionId: &transactionIDStr,
+	}
+
+	return ctx.JSON(http.StatusAccepted, response)
+}
@@ -0,0 +1,368 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/deviceidentifier"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/middleware"
+)
+
+// cancelDatabase holds a single transaction, owned by owner.
+type cancelDatabase struct {
+	database.Interface
+	transaction *database.Transaction
+	owner       string
+}
+
+func (d *cancelDatabase) GetOwnedTransaction(ctx context.Context, transactionID string, owner string) (*database.Transaction, error) {
+	if d.transaction.TransactionID != transactionID || d.owner != owner {
+		return nil, database.ErrTransactionNotFound
+	}
+	return d.transaction, nil
+}
+
+func (d *cancelDatabase) CancelTransaction(ctx context.Context, transactionID string, owner string) (*database.Transaction, error) {
+	transaction, err := d.GetOwnedTransaction(ctx, transactionID, owner)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Status.Final() {
+		return nil, database.ErrTransactionNotCancellable
+	}
+	transaction.Status = database.StatusCancelled
+	return transaction, nil
+}
+
+// requestAs returns a request authenticated as the given JWT subject.
+func requestAs(sub string, method string, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	return req.WithContext(context.WithValue(req.Context(), middleware.Sub, sub))
+}
+
+func TestGetPowerSaving(t *testing.T) {
+	transactionID := uuid.New()
+
+	tests := []struct {
+		name     string
+		caller   string
+		wantCode int
+	}{
+		{name: "returns a transaction of the caller", caller: "owner", wantCode: http.StatusOK},
+		{name: "reports a transaction of another owner as not found", caller: "other", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &cancelDatabase{
+				transaction: &database.Transaction{TransactionID: transactionID.String(), Status: database.StatusActive, Profile: "deep"},
+				owner:       "owner",
+			}
+			h := &handler{database: db}
+
+			req := requestAs(tt.caller, http.MethodGet, "/features/power-saving/transactions/"+transactionID.String(), nil)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err := h.GetPowerSaving(c, models.TransactionId(transactionID), models.GetPowerSavingParams{})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode == http.StatusOK {
+				var response models.PowerSavingResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				require.NotNil(t, response.Profile)
+				assert.Equal(t, "deep", *response.Profile)
+			}
+		})
+	}
+}
+
+// recordingSender records the IDs of the events sent, and fails with err.
+type recordingSender struct {
+	sent []string
+	err  error
+}
+
+func (s *recordingSender) Send(ctx context.Context, requestID string, eventType event.EventType, source event.Source, data any, opts ...event.Option) error {
+	s.sent = append(s.sent, requestID)
+	return s.err
+}
+
+func TestCancelPowerSaving(t *testing.T) {
+	transactionID := uuid.New()
+
+	tests := []struct {
+		name          string
+		transactionID uuid.UUID
+		caller        string
+		status        database.Status
+		sendErr       error
+		wantCode      int
+		wantSent      []string
+		wantStatus    database.Status
+	}{
+		{
+			name:          "cancels an active transaction",
+			transactionID: transactionID,
+			status:        database.StatusActive,
+			wantCode:      http.StatusAccepted,
+			wantSent:      []string{transactionID.String() + "-cancel"},
+			wantStatus:    database.StatusCancelled,
+		},
+		{
+			name:          "reports an unknown transaction",
+			transactionID: uuid.New(),
+			status:        database.StatusActive,
+			wantCode:      http.StatusNotFound,
+			wantStatus:    database.StatusActive,
+		},
+		{
+			name:          "reports a transaction of another owner as not found",
+			transactionID: transactionID,
+			caller:        "other",
+			status:        database.StatusActive,
+			wantCode:      http.StatusNotFound,
+			wantStatus:    database.StatusActive,
+		},
+		{
+			name:          "rejects a transaction in a final status",
+			transactionID: transactionID,
+			status:        database.StatusCompleted,
+			wantCode:      http.StatusConflict,
+			wantStatus:    database.StatusCompleted,
+		},
+		{
+			name:          "accepts the cancellation when cancel.requested cannot be sent",
+			transactionID: transactionID,
+			status:        database.StatusActive,
+			sendErr:       errors.New("broker unavailable"),
+			wantCode:      http.StatusAccepted,
+			wantSent:      []string{transactionID.String() + "-cancel"},
+			wantStatus:    database.StatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &cancelDatabase{
+				transaction: &database.Transaction{TransactionID: transactionID.String(), Status: tt.status},
+				owner:       "owner",
+			}
+			sender := &recordingSender{err: tt.sendErr}
+			h := &handler{database: db, events: sender}
+
+			caller := "owner"
+			if tt.caller != "" {
+				caller = tt.caller
+			}
+			req := requestAs(caller, http.MethodDelete, "/features/power-saving/transactions/"+tt.transactionID.String(), nil)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err := h.CancelPowerSaving(c, models.TransactionId(tt.transactionID), models.CancelPowerSavingParams{})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantSent, sender.sent)
+			assert.Equal(t, tt.wantStatus, db.transaction.Status)
+		})
+	}
+}
+
+// idempotencyDatabase stores idempotency keys in memory and reports the given device conflicts. checked is
+// called while the devices are checked, when the request holds its idempotency key. The first acceptFailures
+// acceptances fail.
+type idempotencyDatabase struct {
+	database.Interface
+	keys           map[string]*database.IdempotencyKey
+	conflicts      []database.DeviceConflict
+	checked        func()
+	acceptFailures int
+}
+
+func (d *idempotencyDatabase) ReserveIdempotencyKey(ctx context.Context, record *database.IdempotencyKey) (*database.IdempotencyKey, error) {
+	if existing, ok := d.keys[record.ID]; ok && existing.ExpiresAt.After(time.Now()) {
+		copied := *existing
+		return &copied, nil
+	}
+	stored := *record
+	d.keys[record.ID] = &stored
+	return nil, nil
+}
+
+func (d *idempotencyDatabase) AcceptIdempotencyKey(ctx context.Context, id string, transactionID string, expiresAt time.Time) error {
+	if d.acceptFailures > 0 {
+		d.acceptFailures--
+		return errors.New("database unavailable")
+	}
+	key, ok := d.keys[id]
+	if !ok || key.TransactionID != transactionID {
+		return database.ErrIdempotencyKeyLost
+	}
+	key.Accepted = true
+	key.ExpiresAt = expiresAt
+	return nil
+}
+
+func (d *idempotencyDatabase) DeleteIdempotencyKey(ctx context.Context, id string, transactionID string) error {
+	if key, ok := d.keys[id]; ok && key.TransactionID == transactionID {
+		delete(d.keys, id)
+	}
+	return nil
+}
+
+func (d *idempotencyDatabase) CheckDeviceConflicts(ctx context.Context, deviceIDs []string, owner string, startAt time.Time, endAt *time.Time, rec *models.Recurrence) ([]database.DeviceConflict, error) {
+	if d.checked != nil {
+		d.checked()
+	}
+	return d.conflicts, nil
+}
+
+func TestActivatePowerSavingIdempotencyKey(t *testing.T) {
+	phone := models.PhoneNumber("+123456789")
+	body, err := json.Marshal(models.PowerSavingRequest{
+		Devices: []models.Device{{PhoneNumber: &phone}},
+		Enabled: true,
+		SubscriptionRequest: models.SubscriptionRequest{
+			Protocol: models.HTTP,
+			Sink:     "https://example.com/notify",
+			Types: []models.SubscriptionEventType{
+				models.SubscriptionEventTypeOrgCamaraprojectIotNetworkOptimizationNotificationV1PowerSaving,
+				models.SubscriptionEventTypeOrgCamaraprojectIotNetworkOptimizationNotificationV1PowerSavingError,
+			},
+		},
+	})
+	require.NoError(t, err)
+	key := models.IdempotencyKey("key-1")
+
+	newHandler := func(db *idempotencyDatabase, sender *recordingSender) *handler {
+		return &handler{
+			database:   db,
+			events:     sender,
+			translator: deviceidentifier.NewMockTranslator(),
+			config:     Config{IdempotencyKeyTTL: time.Hour, IdempotencyKeyLease: time.Minute},
+		}
+	}
+	activate := func(h *handler) (*httptest.ResponseRecorder, models.PowerSavingResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/features/power-saving", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ActivatePowerSaving(echo.New().NewContext(req, rec), models.ActivatePowerSavingParams{IdempotencyKey: &key}))
+
+		var response models.PowerSavingResponse
+		if rec.Code == http.StatusAccepted {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		}
+		return rec, response
+	}
+
+	t.Run("replays an accepted request", func(t *testing.T) {
+		db := &idempotencyDatabase{keys: map[string]*database.IdempotencyKey{}}
+		sender := &recordingSender{}
+		h := newHandler(db, sender)
+
+		first, accepted := activate(h)
+		retry, replayed := activate(h)
+
+		assert.Equal(t, http.StatusAccepted, first.Code)
+		assert.Equal(t, http.StatusAccepted, retry.Code)
+		assert.Equal(t, accepted.TransactionId, replayed.TransactionId)
+		assert.Len(t, sender.sent, 1)
+	})
+
+	t.Run("rejects a retry while the first request is in flight", func(t *testing.T) {
+		db := &idempotencyDatabase{keys: map[string]*database.IdempotencyKey{}, conflicts: []database.DeviceConflict{{TransactionID: "other"}}}
+		sender := &recordingSender{}
+		h := newHandler(db, sender)
+
+		var retry *httptest.ResponseRecorder
+		db.checked = func() {
+			db.checked = nil
+			retry, _ = activate(h)
+		}
+
+		// The first request is rejected after the retry arrived: the retry must not get its transaction ID
+		first, _ := activate(h)
+
+		assert.Equal(t, http.StatusConflict, first.Code)
+		assert.Equal(t, http.StatusConflict, retry.Code)
+		assert.Empty(t, sender.sent)
+		assert.Empty(t, db.keys)
+	})
+
+	t.Run("releases the key of a request not accepted", func(t *testing.T) {
+		db := &idempotencyDatabase{keys: map[string]*database.IdempotencyKey{}}
+		sender := &recordingSender{err: errors.New("broker unavailable")}
+		h := newHandler(db, sender)
+
+		first, _ := activate(h)
+		sender.err = nil
+		retry, _ := activate(h)
+
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, http.StatusAccepted, retry.Code)
+		assert.Len(t, sender.sent, 2)
+	})
+
+	t.Run("takes over the key of a request that stopped", func(t *testing.T) {
+		db := &idempotencyDatabase{keys: map[string]*database.IdempotencyKey{}}
+		sender := &recordingSender{}
+		h := newHandler(db, sender)
+
+		// A request stopped before being accepted, and its lease has expired
+		id := idempotencyRecordID("", string(key))
+		db.keys[id] = &database.IdempotencyKey{ID: id, TransactionID: "stopped", ExpiresAt: time.Now().Add(-time.Second)}
+
+		retry, response := activate(h)
+
+		assert.Equal(t, http.StatusAccepted, retry.Code)
+		assert.NotEqual(t, "stopped", response.TransactionId)
+		assert.Len(t, sender.sent, 1)
+	})
+
+	t.Run("retries recording the acceptance", func(t *testing.T) {
+		db := &idempotencyDatabase{keys: map[string]*database.IdempotencyKey{}, acceptFailures: 1}
+		sender := &recordingSender{}
+		h := newHandler(db, sender)
+
+		first, accepted := activate(h)
+		retry, replayed := activate(h)
+
+		assert.Equal(t, http.StatusAccepted, first.Code)
+		assert.Equal(t, http.StatusAccepted, retry.Code)
+		assert.Equal(t, accepted.TransactionId, replayed.TransactionId)
+		assert.Len(t, sender.sent, 1)
+	})
+}
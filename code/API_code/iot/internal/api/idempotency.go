@@ -0,0 +1,41 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+)
+
+// hashRequest returns a fingerprint of the request body used to detect Idempotency-Key reuse.
+func hashRequest(req *models.PowerSavingRequest) (string, error) {
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// idempotencyRecordID scopes an Idempotency-Key to the caller, so that keys chosen by
+// different API consumers never collide. The owner length prefix keeps the ID unambiguous.
+func idempotencyRecordID(owner, key string) string {
+	return fmt.Sprintf("%d:%s:%s", len(owner), owner, key)
+}
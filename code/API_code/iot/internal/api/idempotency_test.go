@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"testing"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+)
+
+func TestHashRequest(t *testing.T) {
+	phone := models.PhoneNumber("+123456789")
+	otherPhone := models.PhoneNumber("+987654321")
+
+	newRequest := func(phone *models.PhoneNumber, enabled bool) *models.PowerSavingRequest {
+		return &models.PowerSavingRequest{
+			Devices: []models.Device{{PhoneNumber: phone}},
+			Enabled: enabled,
+		}
+	}
+
+	base, err := hashRequest(newRequest(&phone, true))
+	if err != nil {
+		t.Fatalf("hashRequest() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		req      *models.PowerSavingRequest
+		wantSame bool
+	}{
+		{
+			name:     "identical request",
+			req:      newRequest(&phone, true),
+			wantSame: true,
+		},
+		{
+			name:     "different device",
+			req:      newRequest(&otherPhone, true),
+			wantSame: false,
+		},
+		{
+			name:     "different enabled flag",
+			req:      newRequest(&phone, false),
+			wantSame: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hashRequest(tt.req)
+			if err != nil {
+				t.Fatalf("hashRequest() error = %v", err)
+			}
+			if (got == base) != tt.wantSame {
+				t.Errorf("hashRequest() same = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestIdempotencyRecordID(t *testing.T) {
+	if idempotencyRecordID("a:b", "c") == idempotencyRecordID("a", "b:c") {
+		t.Error("idempotencyRecordID() must not collide across owners")
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+// encodeCursor returns the opaque representation of a pagination cursor handed to API consumers.
+func encodeCursor(cursor *database.TransactionCursor) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor parses a cursor previously produced by encodeCursor.
+func decodeCursor(s string) (*database.TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor encoding")
+	}
+	var cursor database.TransactionCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, errors.New("invalid cursor content")
+	}
+	if cursor.TransactionID == "" || cursor.CreatedAt.IsZero() {
+		return nil, errors.New("incomplete cursor")
+	}
+	return &cursor, nil
+}
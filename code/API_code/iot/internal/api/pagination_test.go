@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := &database.TransactionCursor{
+		CreatedAt:     time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC),
+		TransactionID: "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	}
+
+	encoded, err := encodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("encodeCursor() error = %v", err)
+	}
+
+	decoded, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("decodeCursor() error = %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.TransactionID != cursor.TransactionID {
+		t.Errorf("decodeCursor() = %+v, want %+v", decoded, cursor)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{
+			name:   "not base64",
+			cursor: "not a cursor!",
+		},
+		{
+			name:   "not JSON",
+			cursor: base64.RawURLEncoding.EncodeToString([]byte("plain text")),
+		},
+		{
+			name:   "missing transaction ID",
+			cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"createdAt":"2025-03-01T10:30:00Z"}`)),
+		},
+		{
+			name:   "missing creation time",
+			cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"transactionId":"abc"}`)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor); err == nil {
+				t.Errorf("decodeCursor(%q) expected error", tt.cursor)
+			}
+		})
+	}
+}
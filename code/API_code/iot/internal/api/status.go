@@ -0,0 +1,132 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+// deviceStatuses maps the per-device action results stored in a transaction to the API device status.
+func deviceStatuses(transaction *database.Transaction) []models.DeviceStatus {
+	activationStatus := make([]models.DeviceStatus, 0, len(transaction.Devices))
+
+	for _, txDevice := range transaction.Devices {
+		// Determine device status based on completion state and results
+		var status models.DeviceStatusStatus
+
+		// If the transaction was cancelled after START, report the restore of the device
+		if txDevice.CancelAction != nil {
+			switch txDevice.CancelAction.Status {
+			case "success":
+				status = models.Cancelled
+			case "failed":
+				status = models.Failed
+			case "in-progress", "awaiting-start":
+				status = models.InProgress
+			case "pending":
+				status = models.Pending
+			default:
+				status = models.Failed
+			}
+		} else if txDevice.EndAction != nil {
+			// If END action exists, use END status (transaction with END has completed or END is in progress)
+			switch txDevice.EndAction.Status {
+			case "success":
+				status = models.Success
+			case "failed":
+				status = models.Failed
+			case "in-progress":
+				status = models.InProgress
+			case "pending":
+				status = models.Pending
+			default:
+				status = models.Failed
+			}
+		} else if txDevice.StartAction != nil {
+			switch txDevice.StartAction.Status {
+			case "success":
+				if transaction.EndAt != nil {
+					// END is scheduled but hasn't run yet
+					status = models.InProgress
+				} else {
+					// No END scheduled, START success = complete success
+					status = models.Success
+				}
+			case "failed":
+				// START failed = device failed (END won't help)
+				status = models.Failed
+			case "in-progress":
+				status = models.InProgress
+			case "pending":
+				status = models.Pending
+			case "cancelled":
+				// Transaction cancelled before START ran on this device
+				status = models.Cancelled
+			default:
+				status = models.Failed
+			}
+		} else {
+			// No action status yet - default to pending
+			status = models.Pending
+		}
+
+		deviceStatus := models.DeviceStatus{
+			Device: &txDevice.Device,
+			Status: &status,
+		}
+		if action := reportedAction(txDevice); action != nil {
+			if action.ErrorCode != "" {
+				errorCode := models.DeviceErrorCode(action.ErrorCode)
+				deviceStatus.ErrorCode = &errorCode
+				deviceStatus.ErrorMessage = &action.ErrorMessage
+			}
+			if action.Drift != "" {
+				drift := models.DriftDecision(action.Drift)
+				deviceStatus.Drift = &drift
+			}
+		}
+
+		activationStatus = append(activationStatus, deviceStatus)
+	}
+
+	return activationStatus
+}
+
+// reportedAction returns the device action whose status is reported: the restore after a cancellation,
+// then END, then START.
+func reportedAction(txDevice *database.TransactionDevice) *database.DeviceActionStatus {
+	switch {
+	case txDevice.CancelAction != nil:
+		return txDevice.CancelAction
+	case txDevice.EndAction != nil:
+		return txDevice.EndAction
+	default:
+		return txDevice.StartAction
+	}
+}
+
+// statusTransitions maps the status history of a transaction to the API status transitions.
+func statusTransitions(transaction *database.Transaction) []models.StatusTransition {
+	transitions := make([]models.StatusTransition, 0, len(transaction.StatusHistory))
+	for _, transition := range transaction.StatusHistory {
+		transitions = append(transitions, models.StatusTransition{
+			Status:    models.TransactionStatus(transition.Status),
+			Timestamp: transition.At,
+		})
+	}
+	return transitions
+}
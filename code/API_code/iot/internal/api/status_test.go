@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+func TestDeviceStatusesFailureReason(t *testing.T) {
+	transaction := &database.Transaction{
+		Devices: []*database.TransactionDevice{
+			{
+				DeviceID: "ok@example.com",
+				StartAction: &database.DeviceActionStatus{
+					Status: "success",
+				},
+			},
+			{
+				DeviceID: "unknown@example.com",
+				StartAction: &database.DeviceActionStatus{
+					Status:       "failed",
+					ErrorCode:    string(models.ErrorCodeDeviceNotFound),
+					ErrorMessage: "device not found: EasyAPI error: status 404",
+				},
+			},
+			{
+				DeviceID: "restored@example.com",
+				StartAction: &database.DeviceActionStatus{
+					Status: "success",
+				},
+				EndAction: &database.DeviceActionStatus{
+					Status:       "failed",
+					ErrorCode:    string(models.ErrorCodeOriginalStateMissing),
+					ErrorMessage: "original device state not found",
+				},
+			},
+			{
+				DeviceID: "changed@example.com",
+				StartAction: &database.DeviceActionStatus{
+					Status: "success",
+				},
+				EndAction: &database.DeviceActionStatus{
+					Status: "success",
+					Drift:  string(models.DriftSkipped),
+				},
+			},
+		},
+	}
+
+	statuses := deviceStatuses(transaction)
+	assert.Len(t, statuses, 4)
+
+	assert.Equal(t, models.Success, *statuses[0].Status)
+	assert.Nil(t, statuses[0].ErrorCode)
+	assert.Nil(t, statuses[0].ErrorMessage)
+
+	assert.Equal(t, models.Failed, *statuses[1].Status)
+	assert.Equal(t, models.ErrorCodeDeviceNotFound, *statuses[1].ErrorCode)
+	assert.Equal(t, "device not found: EasyAPI error: status 404", *statuses[1].ErrorMessage)
+
+	// The reason of the END failure is reported over the successful START
+	assert.Equal(t, models.Failed, *statuses[2].Status)
+	assert.Equal(t, models.ErrorCodeOriginalStateMissing, *statuses[2].ErrorCode)
+
+	// A skipped restore is flagged without failing the device
+	assert.Equal(t, models.Success, *statuses[3].Status)
+	assert.Nil(t, statuses[3].ErrorCode)
+	assert.Equal(t, models.DriftSkipped, *statuses[3].Drift)
+}
@@ -21,8 +21,12 @@ import (
 	"time"
 
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/recurrence"
 )
 
+// maxJitterWindowSeconds bounds the window the device actuations can be spread over.
+const maxJitterWindowSeconds = 86400
+
 // validateIPv4Format validates that a string is a valid IPv4 address.
 func validateIPv4Format(ip string) error {
 	parsed := net.ParseIP(ip)
@@ -115,5 +119,60 @@ func validatePowerSavingRequest(req *models.PowerSavingRequest) error {
 		}
 	}
 
+	// Validate fan-out pacing
+	if req.FanOut != nil {
+		if err := validateFanOut(req.FanOut); err != nil {
+			return fmt.Errorf("fanOut: %w", err)
+		}
+	}
+
+	// Validate canary rollout
+	if req.Canary != nil {
+		if err := validateCanary(req.Canary); err != nil {
+			return fmt.Errorf("canary: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateCanary validates the share of canary devices and the failure threshold of a canary rollout.
+func validateCanary(canary *models.Canary) error {
+	if canary.Percentage < 1 || canary.Percentage > 99 {
+		return fmt.Errorf("percentage must be between 1 and 99")
+	}
+	if canary.MaxFailurePercentage != nil && !(*canary.MaxFailurePercentage >= 0 && *canary.MaxFailurePercentage <= 100) {
+		return fmt.Errorf("maxFailurePercentage must be between 0 and 100")
+	}
+	return nil
+}
+
+// validateFanOut validates the pacing of the device actuations requested by the consumer.
+func validateFanOut(fanOut *models.FanOut) error {
+	if fanOut.EventsPerSecond != nil && !(*fanOut.EventsPerSecond > 0) {
+		return fmt.Errorf("eventsPerSecond must be greater than 0")
+	}
+	if fanOut.JitterWindowSeconds != nil && (*fanOut.JitterWindowSeconds < 0 || *fanOut.JitterWindowSeconds > maxJitterWindowSeconds) {
+		return fmt.Errorf("jitterWindowSeconds must be between 0 and %d", maxJitterWindowSeconds)
+	}
+	return nil
+}
+
+// validateRecurrence validates the recurrence rule and checks that it has at least one occurrence
+// starting within the [startAt, endAt) period of the request.
+func validateRecurrence(rec models.Recurrence, startAt time.Time, endAt *time.Time) error {
+	rule, err := recurrence.Parse(rec.Schedule, rec.TimeZone, rec.DurationMinutes)
+	if err != nil {
+		return err
+	}
+
+	from := startAt
+	if now := time.Now(); now.After(from) {
+		from = now
+	}
+	first, _, ok := rule.Next(from)
+	if !ok || (endAt != nil && !first.Before(*endAt)) {
+		return fmt.Errorf("no occurrence within the time period")
+	}
 	return nil
 }
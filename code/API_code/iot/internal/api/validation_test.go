@@ -417,3 +417,145 @@ func TestValidatePowerSavingRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRecurrence(t *testing.T) {
+	start := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	sameDay := time.Date(2030, 1, 10, 20, 0, 0, 0, time.UTC)
+	nextDay := time.Date(2030, 1, 11, 12, 0, 0, 0, time.UTC)
+	nightly := models.Recurrence{Schedule: "0 22 * * *", TimeZone: "Europe/Rome", DurationMinutes: 480}
+
+	tests := []struct {
+		name    string
+		rec     models.Recurrence
+		endAt   *time.Time
+		wantErr bool
+	}{
+		{
+			name:    "open-ended period",
+			rec:     nightly,
+			wantErr: false,
+		},
+		{
+			name:    "occurrence within the period",
+			rec:     nightly,
+			endAt:   &nextDay,
+			wantErr: false,
+		},
+		{
+			name:    "no occurrence within the period",
+			rec:     nightly,
+			endAt:   &sameDay,
+			wantErr: true,
+		},
+		{
+			name:    "invalid schedule",
+			rec:     models.Recurrence{Schedule: "0 25 * * *", TimeZone: "Europe/Rome", DurationMinutes: 480},
+			wantErr: true,
+		},
+		{
+			name:    "unknown time zone",
+			rec:     models.Recurrence{Schedule: "0 22 * * *", TimeZone: "Europe/Atlantis", DurationMinutes: 480},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRecurrence(tt.rec, start, tt.endAt)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRecurrence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFanOut(t *testing.T) {
+	rate := func(v float64) *float64 { return &v }
+	window := func(v int) *int { return &v }
+
+	tests := []struct {
+		name    string
+		fanOut  *models.FanOut
+		wantErr bool
+	}{
+		{
+			name:    "rate and jitter",
+			fanOut:  &models.FanOut{EventsPerSecond: rate(100), JitterWindowSeconds: window(300)},
+			wantErr: false,
+		},
+		{
+			name:    "defaults",
+			fanOut:  &models.FanOut{},
+			wantErr: false,
+		},
+		{
+			name:    "zero rate",
+			fanOut:  &models.FanOut{EventsPerSecond: rate(0)},
+			wantErr: true,
+		},
+		{
+			name:    "negative jitter",
+			fanOut:  &models.FanOut{JitterWindowSeconds: window(-1)},
+			wantErr: true,
+		},
+		{
+			name:    "jitter over a day",
+			fanOut:  &models.FanOut{JitterWindowSeconds: window(86401)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFanOut(tt.fanOut)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFanOut() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCanary(t *testing.T) {
+	threshold := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		canary  *models.Canary
+		wantErr bool
+	}{
+		{
+			name:    "percentage and threshold",
+			canary:  &models.Canary{Percentage: 10, MaxFailurePercentage: threshold(5)},
+			wantErr: false,
+		},
+		{
+			name:    "default threshold",
+			canary:  &models.Canary{Percentage: 99},
+			wantErr: false,
+		},
+		{
+			name:    "zero percentage",
+			canary:  &models.Canary{Percentage: 0},
+			wantErr: true,
+		},
+		{
+			name:    "all devices",
+			canary:  &models.Canary{Percentage: 100},
+			wantErr: true,
+		},
+		{
+			name:    "threshold over 100",
+			canary:  &models.Canary{Percentage: 10, MaxFailurePercentage: threshold(100.5)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCanary(tt.canary)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCanary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -17,9 +17,11 @@ package database
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
 )
 
 // Interface defines the database operations for power-saving jobs
@@ -27,28 +29,95 @@ type Interface interface {
 	// Transaction operations
 	CreateTransaction(ctx context.Context, transaction *Transaction) error
 	GetTransaction(ctx context.Context, transactionID string) (*Transaction, error)
+	GetOwnedTransaction(ctx context.Context, transactionID string, owner string) (*Transaction, error)
 	GetPendingTransactions(ctx context.Context) ([]*Transaction, error)
+	GetActiveOccurrence(ctx context.Context, parentTransactionID string) (*Transaction, error)
+	GetInFlightTransactions(ctx context.Context) ([]*Transaction, error)
 	ClaimTransaction(ctx context.Context, transactionID string, action string) (bool, error)
 	MarkTransactionFailed(ctx context.Context, transactionID string, errorMsg string) error
 	MarkTransactionCompleted(ctx context.Context, transactionID string) error
-	CheckDeviceConflicts(ctx context.Context, deviceIDs []string) ([]string, error)
+	CancelTransaction(ctx context.Context, transactionID string, owner string) (*Transaction, error)
+	GetPendingCancellations(ctx context.Context, cancelledBefore time.Time) ([]*Transaction, error)
+	CompleteCancelRequest(ctx context.Context, transactionID string) error
+	AbortTransaction(ctx context.Context, transactionID string, reason string) (*Transaction, error)
+	SetCanaryStatus(ctx context.Context, transactionID string, from CanaryStatus, to CanaryStatus) (bool, error)
+	ListTransactions(ctx context.Context, filter TransactionFilter) ([]*Transaction, *TransactionCursor, error)
+	CheckDeviceConflicts(ctx context.Context, deviceIDs []string, owner string, startAt time.Time, endAt *time.Time, rec *models.Recurrence) ([]DeviceConflict, error)
+
+	// Idempotency keys
+	ReserveIdempotencyKey(ctx context.Context, record *IdempotencyKey) (*IdempotencyKey, error)
+	AcceptIdempotencyKey(ctx context.Context, id string, transactionID string, expiresAt time.Time) error
+	DeleteIdempotencyKey(ctx context.Context, id string, transactionID string) error
 	DeleteOldTransactions(ctx context.Context, olderThan time.Time) (int64, error)
 
+	// Processed events
+	ReserveEvent(ctx context.Context, record *ProcessedEvent) (*ProcessedEvent, error)
+	MarkEventProcessed(ctx context.Context, id string, expiresAt time.Time) error
+	ReleaseEvent(ctx context.Context, id string) error
+
+	// Dead letters
+	RecordDeadLetter(ctx context.Context, deadLetter *DeadLetter) error
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error)
+	ListDeadLetters(ctx context.Context, consumer string, limit int) ([]*DeadLetter, error)
+	MarkDeadLetterReplayed(ctx context.Context, id string) error
+	DeleteDeadLetter(ctx context.Context, id string) error
+
+	// Scheduled actions
+	ScheduleAction(ctx context.Context, action *ScheduledAction) error
+	ClaimDueAction(ctx context.Context, owner string, leaseDuration time.Duration) (*ScheduledAction, error)
+	ExtendActionLease(ctx context.Context, id string, owner string, leaseDuration time.Duration) error
+	MarkActionFired(ctx context.Context, id string, owner string) error
+	DeleteScheduledActions(ctx context.Context, transactionID string) error
+	GetScheduledActions(ctx context.Context, transactionID string) ([]*ScheduledAction, error)
+
+	// Leases
+	AcquireLease(ctx context.Context, name string, holder string, duration time.Duration) (*Lease, error)
+	CheckLease(ctx context.Context, name string, token int64) error
+	ReleaseLease(ctx context.Context, name string, holder string) error
+	CountLeases(ctx context.Context, prefix string) (int, error)
+
 	// Device operations within transaction
-	StoreDeviceOriginalState(ctx context.Context, deviceID string, originalState *DeviceOriginalState) error
-	GetDeviceOriginalState(ctx context.Context, deviceID string) (*DeviceOriginalState, error)
+	StoreDeviceOriginalState(ctx context.Context, deviceID string, transactionID string, originalState *DeviceOriginalState) (*DeviceOriginalState, error)
+	GetDeviceOriginalState(ctx context.Context, deviceID string, transactionID string) (*DeviceOriginalState, error)
+	MarkDeviceRestored(ctx context.Context, deviceID string) error
 	CheckDeviceConfigsExist(ctx context.Context, deviceIDs []string) ([]string, error)
-	UpdateDeviceActionStatus(ctx context.Context, transactionID string, deviceID string, action string, status string) (allCompleted bool, err error)
+	UpdateDeviceActionStatus(ctx context.Context, transactionID string, deviceID string, action string, status *DeviceActionStatus) (progress ActionProgress, err error)
 	GetTransactionDevices(ctx context.Context, transactionID string, action string) ([]*TransactionDevice, error)
+	RequeueDeviceAction(ctx context.Context, transactionID string, deviceID string, action string, observed *DeviceActionStatus) (bool, error)
+	ExpireDeviceAction(ctx context.Context, transactionID string, deviceID string, action string, observed *DeviceActionStatus, status *DeviceActionStatus) (progress ActionProgress, expired bool, err error)
 }
 
-type Status string
+// CanaryStatus is the phase of a transaction actuating its canary devices first.
+type CanaryStatus string
 
 const (
-	StatusPending    Status = "pending"
-	StatusProcessing Status = "processing"
-	StatusCompleted  Status = "completed"
-	StatusFailed     Status = "failed"
+	CanaryRunning    CanaryStatus = "running"    // START is applied to the canary devices
+	CanaryEvaluating CanaryStatus = "evaluating" // all canary devices completed START
+	CanaryPassed     CanaryStatus = "passed"     // START is applied to the other devices
+	CanaryAborted    CanaryStatus = "aborted"    // too many canary devices failed
+)
+
+var (
+	// ErrTransactionNotFound is returned when no transaction matches the given ID and owner.
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrTransactionExists is returned when creating a transaction whose ID is already used.
+	ErrTransactionExists = errors.New("transaction already exists")
+	// ErrTransactionNotCancellable is returned when a transaction has already reached a final status.
+	ErrTransactionNotCancellable = errors.New("transaction cannot be cancelled")
+	// ErrDeviceActionCancelled is returned when updating a device action that was cancelled, or no longer exists.
+	ErrDeviceActionCancelled = errors.New("device action cancelled")
+	// ErrInvalidTransition is returned when a transaction status change is not allowed from its current status.
+	ErrInvalidTransition = errors.New("invalid transaction status transition")
+	// ErrOriginalStateNotFound is returned when no configuration was backed up for a device.
+	ErrOriginalStateNotFound = errors.New("original device state not found")
+	// ErrLeaseHeld is returned when acquiring a lease held by another holder.
+	ErrLeaseHeld = errors.New("lease held by another holder")
+	// ErrLeaseLost is returned when a lease has expired or has been taken over by another holder.
+	ErrLeaseLost = errors.New("lease lost")
+	// ErrIdempotencyKeyLost is returned when an idempotency key is no longer held by the request that reserved it.
+	ErrIdempotencyKeyLost = errors.New("idempotency key no longer held")
+	// ErrDeadLetterNotFound is returned when no dead letter matches the given ID.
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
 )
 
 // Transaction represents the complete transaction with all devices embedded
@@ -57,40 +126,230 @@ type Transaction struct {
 	StartAt             time.Time                  `bson:"startAt" json:"startAt"`
 	EndAt               *time.Time                 `bson:"endAt,omitempty" json:"endAt,omitempty"`
 	Enabled             bool                       `bson:"enabled" json:"enabled"`
+	Profile             string                     `bson:"profile,omitempty" json:"profile,omitempty"`
+	ProfileSettings     *config.Profile            `bson:"profileSettings,omitempty" json:"profileSettings,omitempty"` // resolved when requested
+	FanOut              *models.FanOut             `bson:"fanOut,omitempty" json:"fanOut,omitempty"`
+	Canary              *models.Canary             `bson:"canary,omitempty" json:"canary,omitempty"`
+	CanaryStatus        CanaryStatus               `bson:"canaryStatus,omitempty" json:"canaryStatus,omitempty"`
+	Atomic              bool                       `bson:"atomic,omitempty" json:"atomic,omitempty"` // START applies to all devices or none
 	SubscriptionRequest models.SubscriptionRequest `bson:"subscriptionRequest" json:"subscriptionRequest"`
 	Status              Status                     `bson:"status" json:"status"`
+	StatusHistory       []StatusTransition         `bson:"statusHistory,omitempty" json:"statusHistory,omitempty"`
 	CreatedAt           time.Time                  `bson:"createdAt" json:"createdAt"`
 	UpdatedAt           time.Time                  `bson:"updatedAt" json:"updatedAt"`
 	ErrorMessage        string                     `bson:"errorMessage,omitempty" json:"errorMessage,omitempty"`
+	Owner               string                     `bson:"owner,omitempty" json:"owner,omitempty"`
+	XCorrelator         string                     `bson:"xCorrelator,omitempty" json:"xCorrelator,omitempty"`
+	CancelledAt         *time.Time                 `bson:"cancelledAt,omitempty" json:"cancelledAt,omitempty"`
+
+	// CancelRequestPending is set by a cancellation until the scheduler has handled its cancel.requested event
+	CancelRequestPending bool `bson:"cancelRequestPending,omitempty" json:"cancelRequestPending,omitempty"`
+
+	// Recurring transactions create one occurrence transaction at a time, linked through ParentTransactionID
+	Recurrence          *models.Recurrence `bson:"recurrence,omitempty" json:"recurrence,omitempty"`
+	ParentTransactionID string             `bson:"parentTransactionId,omitempty" json:"parentTransactionId,omitempty"`
 
 	// All devices embedded in the transaction
 	Devices []*TransactionDevice `bson:"devices" json:"devices"`
 
 	// Action completion tracking
-	StartActionCompleted bool `bson:"startActionCompleted" json:"startActionCompleted"`
-	EndActionCompleted   bool `bson:"endActionCompleted" json:"endActionCompleted"`
 	StartActionNotified  bool `bson:"startActionNotified" json:"startActionNotified"`
 	EndActionNotified    bool `bson:"endActionNotified" json:"endActionNotified"`
+	CancelActionNotified bool `bson:"cancelActionNotified" json:"cancelActionNotified"`
 }
 
 // TransactionDevice represents a single device within a transaction
 type TransactionDevice struct {
-	DeviceID    string              `bson:"deviceId" json:"deviceId"`
-	Device      models.Device       `bson:"device" json:"device"`
-	StartAction *DeviceActionStatus `bson:"startAction,omitempty" json:"startAction,omitempty"`
-	EndAction   *DeviceActionStatus `bson:"endAction,omitempty" json:"endAction,omitempty"`
+	DeviceID     string              `bson:"deviceId" json:"deviceId"`
+	Device       models.Device       `bson:"device" json:"device"`
+	Canary       bool                `bson:"canary,omitempty" json:"canary,omitempty"` // actuated first by a canary rollout
+	StartAction  *DeviceActionStatus `bson:"startAction,omitempty" json:"startAction,omitempty"`
+	EndAction    *DeviceActionStatus `bson:"endAction,omitempty" json:"endAction,omitempty"`
+	CancelAction *DeviceActionStatus `bson:"cancelAction,omitempty" json:"cancelAction,omitempty"`
+}
+
+// ActionProgress reports the progress of a transaction action after a device status update.
+type ActionProgress struct {
+	// AllCompleted is true for the single update completing the action on all devices.
+	AllCompleted bool
+	// CanaryCompleted is true for the single update completing the START action on all canary devices.
+	CanaryCompleted bool
 }
 
-// DeviceOriginalState stores the original configuration before actuation in a separate collection
+// DeviceOriginalState is a snapshot of the configuration of a device before a transaction applied power-saving,
+// stored in a separate collection. Snapshots are kept per device and transaction. Until the device is restored,
+// its snapshots are unrestored and the oldest one holds its baseline, the configuration to restore.
 type DeviceOriginalState struct {
-	DeviceID              string    `bson:"_id" json:"deviceId"`
+	ID       string `bson:"_id" json:"-"`
+	DeviceID string `bson:"deviceId" json:"deviceId"`
+	// TransactionID is the transaction taking the snapshot, empty for a snapshot stored per device only
+	TransactionID         string    `bson:"transactionId" json:"transactionId"`
 	PpMaximumLatency      string    `bson:"ppMaximumLatency" json:"ppMaximumLatency"`
 	PpMaximumResponseTime string    `bson:"ppMaximumResponseTime" json:"ppMaximumResponseTime"`
 	Timestamp             time.Time `bson:"timestamp" json:"timestamp"`
+	// InheritedFrom is the transaction whose unrestored snapshot was copied, instead of reading the device
+	InheritedFrom string `bson:"inheritedFrom,omitempty" json:"inheritedFrom,omitempty"`
+	// Configuration applied by the transaction, compared with the device before restoring it
+	AppliedPpMaximumLatency      string `bson:"appliedPpMaximumLatency,omitempty" json:"appliedPpMaximumLatency,omitempty"`
+	AppliedPpMaximumResponseTime string `bson:"appliedPpMaximumResponseTime,omitempty" json:"appliedPpMaximumResponseTime,omitempty"`
+	// RestoredAt is when the device was restored to its baseline
+	RestoredAt *time.Time `bson:"restoredAt,omitempty" json:"restoredAt,omitempty"`
+}
+
+// DeviceOriginalStateID returns the ID of the snapshot of a device taken by a transaction.
+func DeviceOriginalStateID(deviceID string, transactionID string) string {
+	return deviceID + "/" + transactionID
 }
 
 // DeviceActionStatus tracks the status of a device action (start or end)
 type DeviceActionStatus struct {
-	Status    string    `bson:"status" json:"status"` // "in-progress", "success", "failed"
+	Status    string    `bson:"status" json:"status"` // "pending", "in-progress", "success", "failed", "cancelled", "awaiting-start"
 	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
+
+	// Reason of a failed action: a models.DeviceErrorCode and the error details
+	ErrorCode    string `bson:"errorCode,omitempty" json:"errorCode,omitempty"`
+	ErrorMessage string `bson:"errorMessage,omitempty" json:"errorMessage,omitempty"`
+
+	// Attempts counts the actuation requests published for the action, when published again by the watchdog
+	Attempts int `bson:"attempts,omitempty" json:"attempts,omitempty"`
+	// BackendAttempts counts the requests made to the backend for the last attempt, retries included
+	BackendAttempts int `bson:"backendAttempts,omitempty" json:"backendAttempts,omitempty"`
+
+	// Drift is the decision taken when the configuration of the device changed outside the service before
+	// its restore: "restored", "skipped" or "failed"
+	Drift string `bson:"drift,omitempty" json:"drift,omitempty"`
+}
+
+// TransactionFilter selects the transactions returned by ListTransactions.
+// Empty fields are not applied. Time ranges are inclusive of the lower bound and exclusive of the upper bound.
+type TransactionFilter struct {
+	Status              Status
+	DeviceID            string
+	Owner               string
+	ParentTransactionID string
+	CreatedAfter        *time.Time
+	CreatedBefore       *time.Time
+	StartAfter          *time.Time
+	StartBefore         *time.Time
+	EndAfter            *time.Time
+	EndBefore           *time.Time
+
+	// Pagination: transactions are sorted by creation time (newest first) and ID. A Limit of 0 or less
+	// selects DefaultPageLimit, and a Limit above MaxPageLimit is capped
+	After *TransactionCursor
+	Limit int
+}
+
+const (
+	// DefaultPageLimit is the number of transactions of a page when the filter sets no limit.
+	DefaultPageLimit = 20
+	// MaxPageLimit is the largest number of transactions of a page.
+	MaxPageLimit = 100
+)
+
+// pageLimit returns the number of transactions of a page selected by the filter.
+func (f TransactionFilter) pageLimit() int {
+	switch {
+	case f.Limit <= 0:
+		return DefaultPageLimit
+	case f.Limit > MaxPageLimit:
+		return MaxPageLimit
+	default:
+		return f.Limit
+	}
+}
+
+// TransactionCursor identifies the last transaction of a page.
+type TransactionCursor struct {
+	CreatedAt     time.Time `json:"createdAt"`
+	TransactionID string    `json:"transactionId"`
+}
+
+// IdempotencyKey records the outcome of a request sent with an Idempotency-Key header.
+// Until Accepted is set, the key is held by the request in flight until ExpiresAt, then a retry takes it over.
+// Documents are removed by a TTL index on ExpiresAt.
+type IdempotencyKey struct {
+	ID            string    `bson:"_id" json:"id"` // caller-scoped key
+	RequestHash   string    `bson:"requestHash" json:"requestHash"`
+	TransactionID string    `bson:"transactionId" json:"transactionId"`
+	Accepted      bool      `bson:"accepted" json:"accepted"`
+	CreatedAt     time.Time `bson:"createdAt" json:"createdAt"`
+	ExpiresAt     time.Time `bson:"expiresAt" json:"expiresAt"`
+}
+
+// ProcessedEvent records a CloudEvent handled by a consumer, so that its redeliveries are acknowledged without
+// processing it again. Until ProcessedAt is set, the event is held by the delivery in flight until ExpiresAt.
+// Documents are removed by a TTL index on ExpiresAt.
+type ProcessedEvent struct {
+	ID          string     `bson:"_id" json:"id"` // consumer, source and ID of the event
+	Consumer    string     `bson:"consumer" json:"consumer"`
+	Source      string     `bson:"source" json:"source"`
+	EventID     string     `bson:"eventId" json:"eventId"`
+	Type        string     `bson:"type" json:"type"`
+	ProcessedAt *time.Time `bson:"processedAt,omitempty" json:"processedAt,omitempty"`
+	CreatedAt   time.Time  `bson:"createdAt" json:"createdAt"`
+	ExpiresAt   time.Time  `bson:"expiresAt" json:"expiresAt"`
+}
+
+// ProcessedEventID returns the ID of the record of an event handled by a consumer.
+func ProcessedEventID(consumer string, source string, eventID string) string {
+	return consumer + "/" + source + "/" + eventID
+}
+
+// DeadLetter is a CloudEvent that a consumer failed to process, kept until it is processed by a later delivery,
+// replayed successfully or discarded.
+type DeadLetter struct {
+	ID       string `bson:"_id" json:"id"` // consumer, source and ID of the event
+	Consumer string `bson:"consumer" json:"consumer"`
+	Source   string `bson:"source" json:"source"`
+	EventID  string `bson:"eventId" json:"eventId"`
+	Type     string `bson:"type" json:"type"`
+	// Event is the CloudEvent in JSON format, as last delivered
+	Event string `bson:"event" json:"event"`
+	// Error is the error of the last failed delivery, and Attempts counts the failed deliveries
+	Error         string     `bson:"error" json:"error"`
+	Attempts      int        `bson:"attempts" json:"attempts"`
+	FirstFailedAt time.Time  `bson:"firstFailedAt" json:"firstFailedAt"`
+	LastFailedAt  time.Time  `bson:"lastFailedAt" json:"lastFailedAt"`
+	ReplayedAt    *time.Time `bson:"replayedAt,omitempty" json:"replayedAt,omitempty"`
+}
+
+// ScheduledAction is a START or END action of a transaction due at a given time.
+// Scheduler replicas claim due actions with a lease: an action is fired by the replica holding the lease,
+// and by another replica once the lease has expired if the first one did not mark it as fired.
+type ScheduledAction struct {
+	ID                  string                     `bson:"_id" json:"id"` // transaction ID and action
+	TransactionID       string                     `bson:"transactionId" json:"transactionId"`
+	Action              string                     `bson:"action" json:"action"`
+	DueAt               time.Time                  `bson:"dueAt" json:"dueAt"`
+	SubscriptionRequest models.SubscriptionRequest `bson:"subscriptionRequest" json:"subscriptionRequest"`
+	XCorrelator         string                     `bson:"xCorrelator,omitempty" json:"xCorrelator,omitempty"`
+	LeaseOwner          string                     `bson:"leaseOwner,omitempty" json:"leaseOwner,omitempty"`
+	LeaseExpiresAt      *time.Time                 `bson:"leaseExpiresAt,omitempty" json:"leaseExpiresAt,omitempty"`
+	FiredAt             *time.Time                 `bson:"firedAt,omitempty" json:"firedAt,omitempty"`
+	CreatedAt           time.Time                  `bson:"createdAt" json:"createdAt"`
+}
+
+// ScheduledActionID returns the ID of the scheduled action of a transaction.
+func ScheduledActionID(transactionID string, action string) string {
+	return transactionID + "-" + action
+}
+
+// Lease grants a role to a single holder until it expires. Token increases each time the lease
+// changes holder, so that a former holder can be fenced off with CheckLease.
+type Lease struct {
+	Name       string    `bson:"_id" json:"name"`
+	Holder     string    `bson:"holder" json:"holder"`
+	Token      int64     `bson:"token" json:"token"`
+	AcquiredAt time.Time `bson:"acquiredAt" json:"acquiredAt"`
+	ExpiresAt  time.Time `bson:"expiresAt" json:"expiresAt"`
+}
+
+// DeviceConflict reports a device requested in a time window overlapping an active transaction.
+type DeviceConflict struct {
+	DeviceID      string
+	TransactionID string
+	StartAt       time.Time
+	EndAt         *time.Time // nil for an open-ended window
+	OtherOwner    bool       // the transaction belongs to another owner, its ID must not be disclosed
 }
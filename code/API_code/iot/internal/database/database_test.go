@@ -0,0 +1,177 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+)
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "defaults an unset limit", limit: 0, want: DefaultPageLimit},
+		{name: "defaults a negative limit", limit: -1, want: DefaultPageLimit},
+		{name: "keeps a limit in range", limit: 5, want: 5},
+		{name: "caps a limit above the maximum", limit: MaxPageLimit + 1, want: MaxPageLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TransactionFilter{Limit: tt.limit}.pageLimit())
+		})
+	}
+}
+
+func TestOwnedTransactionFilter(t *testing.T) {
+	assert.Equal(t, bson.M{"_id": "tx1", "owner": "alice"}, ownedTransactionFilter("tx1", "alice"))
+}
+
+func TestTransactionsQueryOwner(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter TransactionFilter
+		want   bson.M
+	}{
+		{
+			name:   "selects the transactions of the owner",
+			filter: TransactionFilter{Owner: "alice"},
+			want:   bson.M{"$and": bson.A{bson.M{"owner": "alice"}}},
+		},
+		{
+			name:   "combines the owner with the other filters",
+			filter: TransactionFilter{Status: StatusScheduled, Owner: "alice"},
+			want:   bson.M{"$and": bson.A{bson.M{"status": StatusScheduled}, bson.M{"owner": "alice"}}},
+		},
+		{
+			name:   "applies no condition to an empty filter",
+			filter: TransactionFilter{},
+			want:   bson.M{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, transactionsQuery(tt.filter))
+		})
+	}
+}
+
+func TestOverlappingOccurrence(t *testing.T) {
+	// Nightly from 22:00 to 06:00 UTC, from June 1st
+	nightly := &models.Recurrence{Schedule: "0 22 * * *", TimeZone: "UTC", DurationMinutes: 480}
+	seriesStart := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time { return time.Date(2025, 6, day, hour, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name      string
+		seriesEnd *time.Time
+		startAt   time.Time
+		endAt     *time.Time
+		wantOK    bool
+		wantStart time.Time
+	}{
+		{name: "window between two occurrences", startAt: at(10, 8), endAt: ptr(at(10, 20))},
+		{name: "window overlapping an occurrence", startAt: at(10, 20), endAt: ptr(at(10, 23)), wantOK: true, wantStart: at(10, 22)},
+		{name: "window within a running occurrence", startAt: at(11, 2), endAt: ptr(at(11, 3)), wantOK: true, wantStart: at(10, 22)},
+		{name: "open-ended window", startAt: at(10, 8), wantOK: true, wantStart: at(10, 22)},
+		{name: "window after the time period", seriesEnd: ptr(at(5, 0)), startAt: at(10, 8)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := overlappingOccurrence(nightly, seriesStart, tt.seriesEnd, tt.startAt, tt.endAt)
+
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.wantStart, start)
+				assert.Equal(t, tt.wantStart.Add(8*time.Hour), *end)
+			}
+		})
+	}
+}
+
+func TestFirstConflict(t *testing.T) {
+	// Nightly from 22:00 to 06:00 UTC, and weekdays from 09:00 to 17:00 UTC, from June 1st (a Sunday)
+	nightly := &models.Recurrence{Schedule: "0 22 * * *", TimeZone: "UTC", DurationMinutes: 480}
+	office := &models.Recurrence{Schedule: "0 9 * * 1-5", TimeZone: "UTC", DurationMinutes: 480}
+	saturdayMorning := &models.Recurrence{Schedule: "0 5 * * 6", TimeZone: "UTC", DurationMinutes: 120}
+	at := func(day, hour int) time.Time { return time.Date(2025, 6, day, hour, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name      string
+		existing  series
+		requested series
+		wantOK    bool
+		wantStart time.Time
+		wantEnd   *time.Time
+	}{
+		{
+			name:      "recurring request between the windows of a transaction",
+			existing:  series{StartAt: at(10, 10), EndAt: ptr(at(10, 16))},
+			requested: series{Recurrence: nightly, StartAt: at(1, 0)},
+		},
+		{
+			name:      "recurring request overlapping a transaction",
+			existing:  series{StartAt: at(10, 20), EndAt: ptr(at(10, 23))},
+			requested: series{Recurrence: nightly, StartAt: at(1, 0)},
+			wantOK:    true,
+			wantStart: at(10, 20),
+			wantEnd:   ptr(at(10, 23)),
+		},
+		{
+			name:      "recurring transactions never overlapping",
+			existing:  series{Recurrence: office, StartAt: at(1, 0)},
+			requested: series{Recurrence: nightly, StartAt: at(1, 0)},
+		},
+		{
+			name:      "recurring transactions overlapping once a week",
+			existing:  series{Recurrence: nightly, StartAt: at(1, 0)},
+			requested: series{Recurrence: saturdayMorning, StartAt: at(1, 0)},
+			wantOK:    true,
+			wantStart: at(6, 22),
+			wantEnd:   ptr(at(7, 6)),
+		},
+		{
+			name:      "recurring transactions overlapping after the end of the request",
+			existing:  series{Recurrence: nightly, StartAt: at(1, 0)},
+			requested: series{Recurrence: saturdayMorning, StartAt: at(1, 0), EndAt: ptr(at(7, 0))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := firstConflict(tt.existing, tt.requested)
+
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.wantStart, start)
+				assert.Equal(t, tt.wantEnd, end)
+			}
+		})
+	}
+}
@@ -17,7 +17,9 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -25,17 +27,27 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.uber.org/zap"
 
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/recurrence"
 )
 
 var _ Interface = &mongoDB{}
 
 type mongoDB struct {
-	transactions  *mongo.Collection
-	deviceConfigs *mongo.Collection
+	transactions    *mongo.Collection
+	deviceConfigs   *mongo.Collection
+	idempotencyKeys *mongo.Collection
+	actions         *mongo.Collection
+	leases          *mongo.Collection
+	processedEvents *mongo.Collection
+	deadLetters     *mongo.Collection
 }
 
+// firedActionRetention is how long fired actions are kept, so that redelivered events do not schedule them again.
+const firedActionRetention = 7 * 24 * time.Hour
+
 // NewMongoDB creates a new MongoDB connection using the provided URI and database name.
 func NewMongoDB(conf config.Database) (Interface, error) {
 	clientOpts := options.Client().ApplyURI(conf.Uri)
@@ -46,24 +58,144 @@ func NewMongoDB(conf config.Database) (Interface, error) {
 	db := client.Database(conf.Name)
 	transactionsColl := db.Collection("transactions")
 	deviceConfigsColl := db.Collection("device_configs")
+	idempotencyKeysColl := db.Collection("idempotency_keys")
+	actionsColl := db.Collection("scheduled_actions")
+	leasesColl := db.Collection("leases")
+	processedEventsColl := db.Collection("processed_events")
+	deadLettersColl := db.Collection("dead_letters")
+
+	// Let MongoDB remove idempotency keys once they expire
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = idempotencyKeysColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create idempotency keys TTL index: %w", err)
+	}
+
+	// Processed events are forgotten once they expire, like idempotency keys
+	_, err = processedEventsColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create processed events TTL index: %w", err)
+	}
+
+	// Dead letters are listed by consumer, latest failure first
+	_, err = deadLettersColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "consumer", Value: 1}, {Key: "lastFailedAt", Value: -1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create dead letters index: %w", err)
+	}
+
+	// Replicas look for unfired actions by due time; fired ones are removed after a while
+	_, err = actionsColl.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "firedAt", Value: 1}, {Key: "dueAt", Value: 1}}},
+		{Keys: bson.D{{Key: "transactionId", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "firedAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(firedActionRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create scheduled actions indexes: %w", err)
+	}
+
+	// Transactions stored before they were scoped to an owner cannot be reached by any caller
+	if conf.LegacyOwner != "" {
+		result, err := transactionsColl.UpdateMany(ctx, bson.M{"owner": bson.M{"$in": bson.A{nil, ""}}},
+			bson.M{"$set": bson.M{"owner": conf.LegacyOwner}})
+		if err != nil {
+			return nil, fmt.Errorf("assign legacy owner: %w", err)
+		}
+		if result.ModifiedCount > 0 {
+			logger.Get().Info("Assigned legacy owner to transactions",
+				zap.String("owner", conf.LegacyOwner),
+				zap.Int64("transactions", result.ModifiedCount))
+		}
+	}
+
+	if err := migrateStatuses(ctx, transactionsColl); err != nil {
+		return nil, fmt.Errorf("migrate transaction statuses: %w", err)
+	}
+
+	if err := migrateOriginalStates(ctx, deviceConfigsColl); err != nil {
+		return nil, fmt.Errorf("migrate device original states: %w", err)
+	}
+
+	// Snapshots are looked up by device, oldest unrestored first
+	_, err = deviceConfigsColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "deviceId", Value: 1}, {Key: "timestamp", Value: 1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create device configs index: %w", err)
+	}
 
 	return &mongoDB{
-		transactions:  transactionsColl,
-		deviceConfigs: deviceConfigsColl,
+		transactions:    transactionsColl,
+		deviceConfigs:   deviceConfigsColl,
+		idempotencyKeys: idempotencyKeysColl,
+		actions:         actionsColl,
+		leases:          leasesColl,
+		processedEvents: processedEventsColl,
+		deadLetters:     deadLettersColl,
 	}, nil
 }
 
+// migrateStatuses moves the transactions stored with the former pending and processing statuses to the
+// matching lifecycle status, guessed from the progress of their actions.
+func migrateStatuses(ctx context.Context, transactions *mongo.Collection) error {
+	legacy := bson.M{"$in": bson.A{"pending", "processing"}}
+	steps := []struct {
+		filter bson.M
+		status Status
+	}{
+		{bson.M{"status": legacy, "startActionNotified": true, "devices.endAction": bson.M{"$exists": true}}, StatusEnding},
+		{bson.M{"status": legacy, "startActionNotified": true}, StatusActive},
+		{bson.M{"status": legacy, "devices.startAction.status": bson.M{"$in": bson.A{"in-progress", "success", "failed"}}}, StatusStarting},
+		{bson.M{"status": legacy}, StatusScheduled},
+	}
+	for _, step := range steps {
+		if _, err := transactions.UpdateMany(ctx, step.filter, bson.M{"$set": bson.M{"status": step.status}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateOriginalStates turns the configurations formerly stored per device, keyed by the device ID, into
+// snapshots without transaction. Whether they were restored is unknown: they are considered restored, so that
+// they are only used when no other snapshot is found for the device.
+func migrateOriginalStates(ctx context.Context, deviceConfigs *mongo.Collection) error {
+	_, err := deviceConfigs.UpdateMany(ctx,
+		bson.M{"deviceId": bson.M{"$exists": false}},
+		bson.A{bson.M{"$set": bson.M{
+			"deviceId":      "$_id",
+			"transactionId": "",
+			"restoredAt":    "$timestamp",
+		}}})
+	return err
+}
+
 // CreateTransaction creates a new transaction document with embedded devices.
 func (m *mongoDB) CreateTransaction(ctx context.Context, transaction *Transaction) error {
 	now := time.Now()
 	transaction.CreatedAt = now
 	transaction.UpdatedAt = now
+	transaction.StatusHistory = []StatusTransition{{Status: transaction.Status, At: now}}
 
 	if transaction.Devices == nil {
 		transaction.Devices = []*TransactionDevice{}
 	}
 
 	_, err := m.transactions.InsertOne(ctx, transaction)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrTransactionExists
+	}
 	return err
 }
 
@@ -77,12 +209,26 @@ func (m *mongoDB) GetTransaction(ctx context.Context, transactionID string) (*Tr
 	return &transaction, nil
 }
 
-// GetPendingTransactions retrieves all transactions that are pending or processing.
+// GetOwnedTransaction retrieves a transaction by ID on behalf of its owner.
+// Transactions of other owners are reported as ErrTransactionNotFound.
+func (m *mongoDB) GetOwnedTransaction(ctx context.Context, transactionID string, owner string) (*Transaction, error) {
+	var transaction Transaction
+	err := m.transactions.FindOne(ctx, ownedTransactionFilter(transactionID, owner)).Decode(&transaction)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// GetPendingTransactions retrieves all transactions that have not reached a final status.
 func (m *mongoDB) GetPendingTransactions(ctx context.Context) ([]*Transaction, error) {
 	log := logger.Get()
 
 	filter := bson.M{
-		"status": bson.M{"$in": []Status{StatusPending, StatusProcessing}},
+		"status": bson.M{"$in": activeStatuses},
 	}
 
 	cursor, err := m.transactions.Find(ctx, filter)
@@ -100,99 +246,316 @@ func (m *mongoDB) GetPendingTransactions(ctx context.Context) ([]*Transaction, e
 	return transactions, nil
 }
 
-// ClaimTransaction atomically claims a transaction for a specific action.
-func (m *mongoDB) ClaimTransaction(ctx context.Context, transactionID string, action string) (bool, error) {
-	filter := bson.M{"_id": transactionID}
+// GetActiveOccurrence retrieves the occurrence of a recurring transaction that has not reached a final status.
+// Returns ErrTransactionNotFound when no occurrence is active.
+func (m *mongoDB) GetActiveOccurrence(ctx context.Context, parentTransactionID string) (*Transaction, error) {
+	filter := bson.M{
+		"parentTransactionId": parentTransactionID,
+		"status":              bson.M{"$in": activeStatuses},
+	}
 
-	if action == "start" {
-		filter["startActionCompleted"] = bson.M{"$ne": true}
-	} else if action == "end" {
-		filter["endActionCompleted"] = bson.M{"$ne": true}
+	var transaction Transaction
+	err := m.transactions.FindOne(ctx, filter).Decode(&transaction)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrTransactionNotFound
 	}
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
 
-	update := bson.M{
-		"$set": bson.M{
-			"updatedAt": time.Now(),
-		},
+// GetInFlightTransactions retrieves the transactions whose devices are being actuated: those starting or
+// ending, and those whose devices are being restored after a cancellation, an abort or a rollback.
+func (m *mongoDB) GetInFlightTransactions(ctx context.Context) ([]*Transaction, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": bson.M{"$in": []Status{StatusStarting, StatusEnding}}},
+		bson.M{"cancelActionNotified": false, "devices.cancelAction": bson.M{"$exists": true}},
+	}}
+
+	cursor, err := m.transactions.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("query in-flight transactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []*Transaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, fmt.Errorf("decode in-flight transactions: %w", err)
 	}
+	return transactions, nil
+}
 
-	res, err := m.transactions.UpdateOne(ctx, filter, update)
+// ClaimTransaction atomically claims a transaction for a specific action: START moves it from scheduled
+// to starting and END from active to ending. An action fired again, e.g. after an interrupted fan-out or
+// for the rollout of a canary, claims a transaction already in the starting or ending status.
+// Returns false when the transaction is in any other status.
+func (m *mongoDB) ClaimTransaction(ctx context.Context, transactionID string, action string) (bool, error) {
+	running := StatusStarting
+	if action == "end" {
+		running = StatusEnding
+	}
+
+	_, err := m.transition(ctx, bson.M{"_id": transactionID}, running, bson.M{}, options.FindOneAndUpdate())
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, ErrInvalidTransition) {
+		return false, err
+	}
+
+	res, err := m.transactions.UpdateOne(ctx,
+		bson.M{"_id": transactionID, "status": running},
+		bson.M{"$set": bson.M{"updatedAt": time.Now()}})
 	if err != nil {
 		return false, err
 	}
+	return res.MatchedCount == 1, nil
+}
 
-	// If no documents matched, the transaction doesn't exist or action is already completed
-	if res.MatchedCount == 0 {
-		// Try to fetch the transaction to distinguish between "not found" and "already completed"
-		var transaction Transaction
-		err := m.transactions.FindOne(ctx, bson.M{"_id": transactionID}).Decode(&transaction)
-		if err == mongo.ErrNoDocuments {
-			return false, fmt.Errorf("transaction not found: %s", transactionID)
-		}
+// transition atomically moves the transaction matching filter to the to status, applying set, and records
+// the transition in its status history. Returns ErrInvalidTransition when the transaction is in a status
+// the transition is not allowed from, and ErrTransactionNotFound when no transaction matches filter.
+func (m *mongoDB) transition(ctx context.Context, filter bson.M, to Status, set bson.M, opts *options.FindOneAndUpdateOptionsBuilder) (*Transaction, error) {
+	now := time.Now()
+	set["status"] = to
+	set["updatedAt"] = now
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"statusHistory": StatusTransition{Status: to, At: now}},
+	}
+
+	conditions := bson.M{"status": bson.M{"$in": sourcesOf(to)}}
+	for key, value := range filter {
+		conditions[key] = value
+	}
+
+	var updated Transaction
+	err := m.transactions.FindOneAndUpdate(ctx, conditions, update, opts.SetReturnDocument(options.After)).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		// Tell a missing transaction from one in another status
+		count, err := m.transactions.CountDocuments(ctx, filter)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
-		// Transaction exists but action already completed
-		return false, nil
+		if count == 0 {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, ErrInvalidTransition
 	}
-
-	return true, nil
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
 }
 
-// MarkTransactionFailed marks a transaction as failed.
+// MarkTransactionFailed marks an active transaction as failed.
 func (m *mongoDB) MarkTransactionFailed(ctx context.Context, transactionID string, errorMsg string) error {
-	filter := bson.M{"_id": transactionID}
-	update := bson.M{
-		"$set": bson.M{
-			"status":       StatusFailed,
-			"errorMessage": errorMsg,
-			"updatedAt":    time.Now(),
-		},
-	}
-	_, err := m.transactions.UpdateOne(ctx, filter, update)
+	_, err := m.transition(ctx, bson.M{"_id": transactionID}, StatusFailed, bson.M{"errorMessage": errorMsg}, options.FindOneAndUpdate())
 	return err
 }
 
-// MarkTransactionCompleted marks a transaction as completed.
+// MarkTransactionCompleted marks a scheduled recurring transaction, or a running transaction, as completed.
 func (m *mongoDB) MarkTransactionCompleted(ctx context.Context, transactionID string) error {
-	filter := bson.M{"_id": transactionID}
-	update := bson.M{
-		"$set": bson.M{
-			"status":    StatusCompleted,
-			"updatedAt": time.Now(),
-		},
+	_, err := m.transition(ctx, bson.M{"_id": transactionID}, StatusCompleted, bson.M{}, options.FindOneAndUpdate())
+	return err
+}
+
+// CancelTransaction atomically moves an active transaction to the cancelled status.
+// Devices whose START is still pending are marked as cancelled; when the transaction enabled
+// power-saving, devices whose START already succeeded get a pending cancel action so that the
+// worker restores their original configuration, and devices whose START is in progress are
+// restored by the worker once it completes. Returns the updated transaction.
+// Transactions of other owners are reported as ErrTransactionNotFound.
+func (m *mongoDB) CancelTransaction(ctx context.Context, transactionID string, owner string) (*Transaction, error) {
+	transaction, err := m.GetOwnedTransaction(ctx, transactionID, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.stopTransaction(ctx, transaction, bson.M{"_id": transaction.TransactionID}, StatusCancelled, bson.M{
+		"cancelledAt":          time.Now(),
+		"cancelRequestPending": true,
+	})
+}
+
+// AbortTransaction atomically moves an active transaction to the failed status with reason, and
+// prepares the restore of its devices like CancelTransaction. Returns the updated transaction.
+func (m *mongoDB) AbortTransaction(ctx context.Context, transactionID string, reason string) (*Transaction, error) {
+	transaction, err := m.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.stopTransaction(ctx, transaction, bson.M{"_id": transaction.TransactionID}, StatusFailed, bson.M{
+		"errorMessage": reason,
+	})
+}
+
+// stopTransaction moves an active transaction matched by filter to the to status applying set, and stops its devices:
+// pending STARTs are cancelled and, when the transaction enabled power-saving, started devices get a pending cancel
+// action. Returns ErrTransactionNotCancellable when the transaction has already reached a final status.
+func (m *mongoDB) stopTransaction(ctx context.Context, transaction *Transaction, filter bson.M, to Status, set bson.M) (*Transaction, error) {
+	if transaction.Status.Final() {
+		return nil, ErrTransactionNotCancellable
 	}
-	_, err := m.transactions.UpdateOne(ctx, filter, update)
+
+	now := time.Now()
+	set["devices.$[pending].startAction.status"] = "cancelled"
+	set["devices.$[pending].startAction.timestamp"] = now
+	arrayFilters := []interface{}{
+		bson.M{"pending.startAction.status": "pending"},
+	}
+	if transaction.Enabled {
+		set["devices.$[started].cancelAction"] = &DeviceActionStatus{
+			Status:    "pending",
+			Timestamp: now,
+		}
+		set["devices.$[starting].cancelAction"] = &DeviceActionStatus{
+			Status:    "awaiting-start",
+			Timestamp: now,
+		}
+		arrayFilters = append(arrayFilters, bson.M{
+			"started.startAction.status": "success",
+			"started.endAction":          bson.M{"$exists": false},
+		}, bson.M{
+			"starting.startAction.status": "in-progress",
+		})
+	}
+
+	opts := options.FindOneAndUpdate().SetArrayFilters(arrayFilters)
+	updated, err := m.transition(ctx, filter, to, set, opts)
+	if errors.Is(err, ErrInvalidTransition) {
+		// Status changed between read and update
+		return nil, ErrTransactionNotCancellable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stop transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// GetPendingCancellations retrieves the transactions cancelled before cancelledBefore whose cancel.requested
+// event has not been handled by the scheduler yet.
+func (m *mongoDB) GetPendingCancellations(ctx context.Context, cancelledBefore time.Time) ([]*Transaction, error) {
+	filter := bson.M{
+		"cancelRequestPending": true,
+		"cancelledAt":          bson.M{"$lte": cancelledBefore},
+	}
+
+	cursor, err := m.transactions.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("query pending cancellations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []*Transaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, fmt.Errorf("decode pending cancellations: %w", err)
+	}
+	return transactions, nil
+}
+
+// CompleteCancelRequest records that the cancel.requested event of a cancelled transaction has been handled.
+func (m *mongoDB) CompleteCancelRequest(ctx context.Context, transactionID string) error {
+	_, err := m.transactions.UpdateOne(ctx,
+		bson.M{"_id": transactionID},
+		bson.M{"$unset": bson.M{"cancelRequestPending": ""}})
 	return err
 }
 
-// StoreDeviceOriginalState stores the original device configuration.
-func (m *mongoDB) StoreDeviceOriginalState(ctx context.Context, deviceID string, originalState *DeviceOriginalState) error {
-	originalState.DeviceID = deviceID
-	originalState.Timestamp = time.Now()
+// SetCanaryStatus moves the canary phase of a transaction from one status to another.
+// Returns false when the canary phase was not in the from status.
+func (m *mongoDB) SetCanaryStatus(ctx context.Context, transactionID string, from CanaryStatus, to CanaryStatus) (bool, error) {
+	res, err := m.transactions.UpdateOne(ctx,
+		bson.M{"_id": transactionID, "canaryStatus": from},
+		bson.M{"$set": bson.M{"canaryStatus": to, "updatedAt": time.Now()}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// StoreDeviceOriginalState stores the snapshot of a device taken by a transaction and returns the snapshot kept.
+// A snapshot already taken by the transaction is kept, as the device may hold the power-saving values by now.
+// While the device has an unrestored snapshot, the new snapshot copies its baseline instead of originalState.
+func (m *mongoDB) StoreDeviceOriginalState(ctx context.Context, deviceID string, transactionID string, originalState *DeviceOriginalState) (*DeviceOriginalState, error) {
+	return storeSnapshot(ctx, mongoSnapshots{m.deviceConfigs}, deviceID, transactionID, originalState)
+}
+
+// GetDeviceOriginalState returns the snapshot to restore a device to for a transaction: the snapshot taken by
+// the transaction, or else the baseline of the device, from its oldest unrestored snapshot or its latest one.
+// An empty transactionID selects the baseline of the device.
+func (m *mongoDB) GetDeviceOriginalState(ctx context.Context, deviceID string, transactionID string) (*DeviceOriginalState, error) {
+	return originalState(ctx, mongoSnapshots{m.deviceConfigs}, deviceID, transactionID)
+}
+
+// MarkDeviceRestored records that a device is back to its baseline, so that none of its snapshots is
+// unrestored anymore. It applies to the device, whatever the transactions that took the snapshots: the
+// next transaction backs up the configuration of the device again instead of inheriting a baseline.
+func (m *mongoDB) MarkDeviceRestored(ctx context.Context, deviceID string) error {
+	return mongoSnapshots{m.deviceConfigs}.markRestored(ctx, deviceID, time.Now())
+}
+
+// mongoSnapshots is the snapshot store kept in the device_configs collection.
+type mongoSnapshots struct {
+	collection *mongo.Collection
+}
+
+func (s mongoSnapshots) get(ctx context.Context, id string) (*DeviceOriginalState, error) {
+	return s.find(ctx, bson.M{"_id": id}, 1)
+}
+
+func (s mongoSnapshots) oldestUnrestored(ctx context.Context, deviceID string) (*DeviceOriginalState, error) {
+	return s.find(ctx, unrestoredFilter(deviceID), 1)
+}
+
+func (s mongoSnapshots) latest(ctx context.Context, deviceID string) (*DeviceOriginalState, error) {
+	return s.find(ctx, bson.M{"deviceId": deviceID}, -1)
+}
+
+func (s mongoSnapshots) insert(ctx context.Context, snapshot *DeviceOriginalState) (bool, error) {
+	_, err := s.collection.InsertOne(ctx, snapshot)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	opts := options.Replace().SetUpsert(true)
-	_, err := m.deviceConfigs.ReplaceOne(ctx, bson.M{"_id": deviceID}, originalState, opts)
+func (s mongoSnapshots) markRestored(ctx context.Context, deviceID string, at time.Time) error {
+	_, err := s.collection.UpdateMany(ctx, unrestoredFilter(deviceID), bson.M{"$set": bson.M{"restoredAt": at}})
 	return err
 }
 
-// GetDeviceOriginalState retrieves the original device configuration.
-func (m *mongoDB) GetDeviceOriginalState(ctx context.Context, deviceID string) (*DeviceOriginalState, error) {
+// find returns the first snapshot matching filter, by timestamp in the given order.
+func (s mongoSnapshots) find(ctx context.Context, filter bson.M, order int) (*DeviceOriginalState, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: order}})
 	var state DeviceOriginalState
-	err := m.deviceConfigs.FindOne(ctx, bson.M{"_id": deviceID}).Decode(&state)
+	err := s.collection.FindOne(ctx, filter, opts).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrOriginalStateNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &state, nil
 }
 
+// unrestoredFilter selects the snapshots of a device not restored yet.
+func unrestoredFilter(deviceID string) bson.M {
+	return bson.M{"deviceId": deviceID, "restoredAt": bson.M{"$exists": false}}
+}
+
 // CheckDeviceConfigsExist returns deviceIDs that don't have stored configurations.
 func (m *mongoDB) CheckDeviceConfigsExist(ctx context.Context, deviceIDs []string) ([]string, error) {
 	log := logger.Get()
 	missing := make([]string, 0)
 
 	for _, deviceID := range deviceIDs {
-		count, err := m.deviceConfigs.CountDocuments(ctx, bson.M{"_id": deviceID})
+		count, err := m.deviceConfigs.CountDocuments(ctx, bson.M{"deviceId": deviceID})
 		if err != nil {
 			log.Error("Failed to check device config existence",
 				zap.String("deviceId", deviceID),
@@ -207,35 +570,117 @@ func (m *mongoDB) CheckDeviceConfigsExist(ctx context.Context, deviceIDs []strin
 	return missing, nil
 }
 
-// UpdateDeviceActionStatus updates the status of a device action.
+// UpdateDeviceActionStatus updates the status of a device action, timestamped with the current time.
 // Returns true if all devices are complete and this caller won the notification race.
-func (m *mongoDB) UpdateDeviceActionStatus(ctx context.Context, transactionID string, deviceID string, action string, status string) (allCompleted bool, err error) {
+// A cancelled device action is left as is and ErrDeviceActionCancelled is returned.
+func (m *mongoDB) UpdateDeviceActionStatus(ctx context.Context, transactionID string, deviceID string, action string, status *DeviceActionStatus) (progress ActionProgress, err error) {
+	filter := bson.M{
+		"_id": transactionID,
+		"devices": bson.M{"$elemMatch": bson.M{
+			"deviceId":               deviceID,
+			action + "Action.status": bson.M{"$ne": "cancelled"},
+		}},
+	}
+	transaction, err := m.updateDeviceAction(ctx, filter, action, status)
+	if err == mongo.ErrNoDocuments {
+		return progress, ErrDeviceActionCancelled
+	}
+	if err != nil {
+		return progress, err
+	}
+	return m.actionProgress(ctx, transaction, action)
+}
+
+// RequeueDeviceAction moves a device action back to pending, counting one more attempt, so that its
+// actuation request can be published again. The update only applies while the action is still in the
+// observed status, nil for an action not started; it returns false otherwise.
+func (m *mongoDB) RequeueDeviceAction(ctx context.Context, transactionID string, deviceID string, action string, observed *DeviceActionStatus) (bool, error) {
+	attempts := 1
+	if observed != nil && observed.Attempts > 0 {
+		attempts = observed.Attempts
+	}
+	status := &DeviceActionStatus{Status: "pending", Attempts: attempts + 1}
+
+	_, err := m.updateDeviceAction(ctx, observedFilter(transactionID, deviceID, action, observed), action, status)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ExpireDeviceAction sets the final status of a device action that got no result, like UpdateDeviceActionStatus.
+// The update only applies while the action is still in the observed status; expired is false otherwise.
+func (m *mongoDB) ExpireDeviceAction(ctx context.Context, transactionID string, deviceID string, action string, observed *DeviceActionStatus, status *DeviceActionStatus) (progress ActionProgress, expired bool, err error) {
+	transaction, err := m.updateDeviceAction(ctx, observedFilter(transactionID, deviceID, action, observed), action, status)
+	if err == mongo.ErrNoDocuments {
+		return progress, false, nil
+	}
+	if err != nil {
+		return progress, false, err
+	}
+	progress, err = m.actionProgress(ctx, transaction, action)
+	return progress, true, err
+}
+
+// observedFilter matches a transaction whose device action is still in the observed status, or not started.
+func observedFilter(transactionID string, deviceID string, action string, observed *DeviceActionStatus) bson.M {
+	field := action + "Action"
+	device := bson.M{"deviceId": deviceID}
+	if observed == nil {
+		device[field] = bson.M{"$exists": false}
+	} else {
+		device[field+".status"] = observed.Status
+		device[field+".timestamp"] = observed.Timestamp
+	}
+	return bson.M{
+		"_id":     transactionID,
+		"devices": bson.M{"$elemMatch": device},
+	}
+}
+
+// updateDeviceAction sets the status of the action of the device matched by filter, timestamped with the
+// current time, and returns the updated transaction.
+func (m *mongoDB) updateDeviceAction(ctx context.Context, filter bson.M, action string, status *DeviceActionStatus) (*Transaction, error) {
 	actionField := "devices.$.startAction"
-	notifiedField := "startActionNotified"
 	if action == "end" {
 		actionField = "devices.$.endAction"
-		notifiedField = "endActionNotified"
+	} else if action == "cancel" {
+		actionField = "devices.$.cancelAction"
 	}
 
-	filter := bson.M{
-		"_id":              transactionID,
-		"devices.deviceId": deviceID,
-	}
+	status.Timestamp = time.Now()
 	update := bson.M{
 		"$set": bson.M{
-			actionField: &DeviceActionStatus{
-				Status:    status,
-				Timestamp: time.Now(),
-			},
+			actionField: status,
 			"updatedAt": time.Now(),
 		},
 	}
 
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var transaction Transaction
-	err = m.transactions.FindOneAndUpdate(ctx, filter, update, opts).Decode(&transaction)
-	if err != nil {
-		return false, err
+	if err := m.transactions.FindOneAndUpdate(ctx, filter, update, opts).Decode(&transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// actionProgress reports the progress of an action after the update of a device status. The update completing
+// the action on all devices moves the transaction on and marks the action as notified, atomically, so that
+// the completion is reported once.
+func (m *mongoDB) actionProgress(ctx context.Context, transaction *Transaction, action string) (progress ActionProgress, err error) {
+	notifiedField := "startActionNotified"
+	if action == "end" {
+		notifiedField = "endActionNotified"
+	} else if action == "cancel" {
+		notifiedField = "cancelActionNotified"
+	}
+
+	// While the canary devices run, the other devices wait for the canary to be evaluated
+	if action == "start" && transaction.CanaryStatus == CanaryRunning {
+		return m.canaryProgress(ctx, transaction)
 	}
 
 	completedCount := 0
@@ -243,9 +688,17 @@ func (m *mongoDB) UpdateDeviceActionStatus(ctx context.Context, transactionID st
 
 	for _, device := range transaction.Devices {
 		var deviceAction *DeviceActionStatus
-		if action == "start" {
+		switch action {
+		case "start":
 			deviceAction = device.StartAction
-		} else {
+		case "cancel":
+			// Only devices that needed a restore take part in the cancel action
+			if device.CancelAction == nil {
+				totalDevices--
+				continue
+			}
+			deviceAction = device.CancelAction
+		default:
 			deviceAction = device.EndAction
 		}
 
@@ -257,9 +710,32 @@ func (m *mongoDB) UpdateDeviceActionStatus(ctx context.Context, transactionID st
 	// Mark as notified atomically to prevent duplicate notifications.
 	if completedCount == totalDevices && totalDevices > 0 {
 		filter := bson.M{
-			"_id":         transactionID,
+			"_id":         transaction.TransactionID,
 			notifiedField: false,
 		}
+
+		// The completion of START or END moves the transaction on, together with the notification flag
+		if action == "start" || action == "end" {
+			var err error
+			if to := completedStatus(transaction, action); to == StatusRolledBack {
+				// The devices whose START succeeded are restored like for a cancellation
+				_, err = m.stopTransaction(ctx, transaction, filter, to, bson.M{
+					notifiedField:  true,
+					"errorMessage": rollbackReason(transaction),
+				})
+			} else {
+				_, err = m.transition(ctx, filter, to, bson.M{notifiedField: true}, options.FindOneAndUpdate())
+			}
+			if err == nil {
+				progress.AllCompleted = true
+				return progress, nil
+			}
+			if !errors.Is(err, ErrInvalidTransition) && !errors.Is(err, ErrTransactionNotFound) && !errors.Is(err, ErrTransactionNotCancellable) {
+				return progress, err
+			}
+			// Already notified, or stopped in the meantime: a cancelled transaction keeps its status
+		}
+
 		update := bson.M{
 			"$set": bson.M{
 				notifiedField: true,
@@ -268,13 +744,34 @@ func (m *mongoDB) UpdateDeviceActionStatus(ctx context.Context, transactionID st
 		}
 		result, err := m.transactions.UpdateOne(ctx, filter, update)
 		if err != nil {
-			return false, err
+			return progress, err
 		}
 
-		return result.MatchedCount == 1, nil
+		progress.AllCompleted = result.MatchedCount == 1
+	}
+
+	return progress, nil
+}
+
+// canaryProgress reports the completion of the START action on all the canary devices of a transaction.
+// The canary phase moves to evaluating atomically, so that the completion is reported once.
+func (m *mongoDB) canaryProgress(ctx context.Context, transaction *Transaction) (ActionProgress, error) {
+	var progress ActionProgress
+	for _, device := range transaction.Devices {
+		if !device.Canary {
+			continue
+		}
+		if device.StartAction == nil || (device.StartAction.Status != "success" && device.StartAction.Status != "failed") {
+			return progress, nil
+		}
 	}
 
-	return false, nil
+	moved, err := m.SetCanaryStatus(ctx, transaction.TransactionID, CanaryRunning, CanaryEvaluating)
+	if err != nil {
+		return progress, err
+	}
+	progress.CanaryCompleted = moved
+	return progress, nil
 }
 
 // GetTransactionDevices retrieves all devices for a transaction with their action status.
@@ -287,48 +784,627 @@ func (m *mongoDB) GetTransactionDevices(ctx context.Context, transactionID strin
 	return transaction.Devices, nil
 }
 
-// CheckDeviceConflicts returns transactionIDs of active transactions containing the specified devices.
-func (m *mongoDB) CheckDeviceConflicts(ctx context.Context, deviceIDs []string) ([]string, error) {
+// ListTransactions returns a page of transactions matching the filter, newest first.
+// The returned cursor is nil when there are no more results.
+func (m *mongoDB) ListTransactions(ctx context.Context, filter TransactionFilter) ([]*Transaction, *TransactionCursor, error) {
+	query := transactionsQuery(filter)
+
+	// Fetch one extra document to know whether another page exists
+	limit := filter.pageLimit()
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit) + 1)
+
+	cursor, err := m.transactions.Find(ctx, query, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query transactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	transactions := []*Transaction{}
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, nil, fmt.Errorf("decode transactions: %w", err)
+	}
+
+	if len(transactions) <= limit {
+		return transactions, nil, nil
+	}
+
+	transactions = transactions[:limit]
+	last := transactions[len(transactions)-1]
+	return transactions, &TransactionCursor{
+		CreatedAt:     last.CreatedAt,
+		TransactionID: last.TransactionID,
+	}, nil
+}
+
+// ownedTransactionFilter selects a transaction by ID when it belongs to the owner.
+func ownedTransactionFilter(transactionID string, owner string) bson.M {
+	return bson.M{"_id": transactionID, "owner": owner}
+}
+
+// transactionsQuery builds the query selecting the transactions of the filter, after its cursor.
+func transactionsQuery(filter TransactionFilter) bson.M {
+	conditions := bson.A{}
+
+	if filter.Status != "" {
+		conditions = append(conditions, bson.M{"status": filter.Status})
+	}
+	if filter.DeviceID != "" {
+		conditions = append(conditions, bson.M{"devices.deviceId": filter.DeviceID})
+	}
+	if filter.Owner != "" {
+		conditions = append(conditions, bson.M{"owner": filter.Owner})
+	}
+	if filter.ParentTransactionID != "" {
+		conditions = append(conditions, bson.M{"parentTransactionId": filter.ParentTransactionID})
+	}
+	if r := timeRange(filter.CreatedAfter, filter.CreatedBefore); r != nil {
+		conditions = append(conditions, bson.M{"createdAt": r})
+	}
+	if r := timeRange(filter.StartAfter, filter.StartBefore); r != nil {
+		conditions = append(conditions, bson.M{"startAt": r})
+	}
+	if r := timeRange(filter.EndAfter, filter.EndBefore); r != nil {
+		conditions = append(conditions, bson.M{"endAt": r})
+	}
+	if filter.After != nil {
+		// Continue strictly after the last transaction of the previous page
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"createdAt": bson.M{"$lt": filter.After.CreatedAt}},
+			bson.M{"createdAt": filter.After.CreatedAt, "_id": bson.M{"$lt": filter.After.TransactionID}},
+		}})
+	}
+
+	query := bson.M{}
+	if len(conditions) > 0 {
+		query["$and"] = conditions
+	}
+	return query
+}
+
+// timeRange builds a [from, to) range condition, or nil when both bounds are unset.
+func timeRange(from, to *time.Time) bson.M {
+	if from == nil && to == nil {
+		return nil
+	}
+	r := bson.M{}
+	if from != nil {
+		r["$gte"] = *from
+	}
+	if to != nil {
+		r["$lt"] = *to
+	}
+	return r
+}
+
+// CheckDeviceConflicts returns, for each requested device, the active transactions using it in a window
+// overlapping [startAt, endAt), whoever their owner: a device is actuated by one transaction at a time.
+// A nil endAt denotes an open-ended window, on either side. Conflicts with the transactions of other owners
+// are flagged as such. Occurrences of recurring transactions are not reported: a recurring transaction, existing
+// or requested with rec, is expanded into its occurrence windows instead, and the first occurrence of the
+// existing transaction overlapping a window of the request is reported.
+func (m *mongoDB) CheckDeviceConflicts(ctx context.Context, deviceIDs []string, owner string, startAt time.Time, endAt *time.Time, rec *models.Recurrence) ([]DeviceConflict, error) {
+	// Two windows overlap when each one starts before the other ends. The time periods of recurring
+	// transactions are matched first, then their occurrences
 	filter := bson.M{
 		"status": bson.M{
-			"$in": []Status{StatusPending, StatusProcessing},
+			"$in": activeStatuses,
 		},
 		"devices.deviceId": bson.M{
 			"$in": deviceIDs,
 		},
+		"parentTransactionId": bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"endAt": nil},
+			bson.M{"endAt": bson.M{"$gt": startAt}},
+		},
+	}
+	if endAt != nil {
+		filter["startAt"] = bson.M{"$lt": *endAt}
 	}
 
-	cursor, err := m.transactions.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	projection := bson.M{"_id": 1, "owner": 1, "startAt": 1, "endAt": 1, "recurrence": 1, "devices.deviceId": 1}
+	cursor, err := m.transactions.Find(ctx, filter, options.Find().SetProjection(projection))
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var conflictingTxs []string
+	requested := make(map[string]bool, len(deviceIDs))
+	for _, id := range deviceIDs {
+		requested[id] = true
+	}
+
+	var conflicts []DeviceConflict
 	for cursor.Next(ctx) {
 		var result struct {
-			ID string `bson:"_id"`
+			ID         string             `bson:"_id"`
+			Owner      string             `bson:"owner"`
+			StartAt    time.Time          `bson:"startAt"`
+			EndAt      *time.Time         `bson:"endAt"`
+			Recurrence *models.Recurrence `bson:"recurrence"`
+			Devices    []struct {
+				DeviceID string `bson:"deviceId"`
+			} `bson:"devices"`
 		}
 		if err := cursor.Decode(&result); err != nil {
 			return nil, err
 		}
-		conflictingTxs = append(conflictingTxs, result.ID)
+		start, end, ok := firstConflict(
+			series{Recurrence: result.Recurrence, StartAt: result.StartAt, EndAt: result.EndAt},
+			series{Recurrence: rec, StartAt: startAt, EndAt: endAt})
+		if !ok {
+			continue
+		}
+		result.StartAt, result.EndAt = start, end
+		for _, device := range result.Devices {
+			if !requested[device.DeviceID] {
+				continue
+			}
+			conflicts = append(conflicts, DeviceConflict{
+				DeviceID:      device.DeviceID,
+				TransactionID: result.ID,
+				StartAt:       result.StartAt,
+				EndAt:         result.EndAt,
+				OtherOwner:    result.Owner != owner,
+			})
+		}
 	}
 
 	if err := cursor.Err(); err != nil {
 		return nil, err
 	}
 
-	return conflictingTxs, nil
+	return conflicts, nil
+}
+
+// conflictHorizon bounds the comparison of two recurring transactions: it covers the yearly patterns of their
+// schedules, leap days included, and series not overlapping within it are considered to never overlap.
+const conflictHorizon = 4 * 366 * 24 * time.Hour
+
+// series is the time period of a transaction, expanded into occurrence windows when it is recurring.
+type series struct {
+	Recurrence *models.Recurrence // nil for a single window
+	StartAt    time.Time
+	EndAt      *time.Time // nil for an open-ended time period
+}
+
+// firstConflict returns the first window of an existing transaction that overlaps a window of the requested
+// one, their time periods overlapping already.
+func firstConflict(existing, requested series) (start time.Time, end *time.Time, ok bool) {
+	switch {
+	case existing.Recurrence == nil && requested.Recurrence == nil:
+		return existing.StartAt, existing.EndAt, true
+	case requested.Recurrence == nil:
+		return overlappingOccurrence(existing.Recurrence, existing.StartAt, existing.EndAt, requested.StartAt, requested.EndAt)
+	case existing.Recurrence == nil:
+		_, _, ok := overlappingOccurrence(requested.Recurrence, requested.StartAt, requested.EndAt, existing.StartAt, existing.EndAt)
+		return existing.StartAt, existing.EndAt, ok
+	}
+
+	// Both are recurring: leapfrog from an occurrence of the existing transaction to the next occurrence of the
+	// request not overlapping it, and back, until two occurrences overlap
+	from := existing.StartAt
+	if requested.StartAt.After(from) {
+		from = requested.StartAt
+	}
+	horizon := from.Add(conflictHorizon)
+	for from.Before(horizon) {
+		start, end, ok := overlappingOccurrence(existing.Recurrence, existing.StartAt, existing.EndAt, from, requested.EndAt)
+		if !ok {
+			return time.Time{}, nil, false
+		}
+		if _, _, ok := overlappingOccurrence(requested.Recurrence, requested.StartAt, requested.EndAt, start, end); ok {
+			return start, end, true
+		}
+		if end == nil {
+			return time.Time{}, nil, false
+		}
+		// The next occurrence of the request ends after this one, and so starts after it ends
+		if from, _, ok = overlappingOccurrence(requested.Recurrence, requested.StartAt, requested.EndAt, *end, existing.EndAt); !ok {
+			return time.Time{}, nil, false
+		}
+	}
+	return time.Time{}, nil, false
+}
+
+// overlappingOccurrence returns the first occurrence of a recurring transaction, within its time period
+// [seriesStart, seriesEnd), that overlaps [startAt, endAt). A nil seriesEnd or endAt is open-ended. An invalid
+// rule, which the API does not accept, is considered to use the whole time period.
+func overlappingOccurrence(rec *models.Recurrence, seriesStart time.Time, seriesEnd *time.Time, startAt time.Time, endAt *time.Time) (start time.Time, end *time.Time, ok bool) {
+	rule, err := recurrence.Parse(rec.Schedule, rec.TimeZone, rec.DurationMinutes)
+	if err != nil {
+		return seriesStart, seriesEnd, true
+	}
+
+	// An occurrence started before startAt may still be running; its wall-clock duration may be an hour
+	// longer across a daylight saving change
+	from := startAt.Add(-time.Duration(rec.DurationMinutes)*time.Minute - time.Hour)
+	if seriesStart.After(from) {
+		from = seriesStart
+	}
+	for {
+		start, end, ok := rule.Next(from)
+		if !ok || (seriesEnd != nil && !start.Before(*seriesEnd)) || (endAt != nil && !start.Before(*endAt)) {
+			return time.Time{}, nil, false
+		}
+		if seriesEnd != nil && end.After(*seriesEnd) {
+			end = *seriesEnd
+		}
+		if end.After(startAt) {
+			return start, &end, true
+		}
+		from = start.Add(time.Minute)
+	}
+}
+
+// ReserveIdempotencyKey stores a new idempotency key record, held by the request until its expiry.
+// If the key is already in use, the existing record is returned and nothing is stored. A key whose
+// request stopped before being accepted is taken over once it expires.
+func (m *mongoDB) ReserveIdempotencyKey(ctx context.Context, record *IdempotencyKey) (*IdempotencyKey, error) {
+	now := time.Now()
+	record.CreatedAt = now
+
+	// The TTL monitor runs periodically: drop an expired record it has not removed yet
+	_, err := m.idempotencyKeys.DeleteOne(ctx, bson.M{
+		"_id":       record.ID,
+		"expiresAt": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("delete expired idempotency key: %w", err)
+	}
+
+	_, err = m.idempotencyKeys.InsertOne(ctx, record)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("insert idempotency key: %w", err)
+	}
+
+	var existing IdempotencyKey
+	if err := m.idempotencyKeys.FindOne(ctx, bson.M{"_id": record.ID}).Decode(&existing); err != nil {
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+	return &existing, nil
+}
+
+// AcceptIdempotencyKey records that the request holding an idempotency key for a transaction has been
+// accepted, and remembers the key until expiresAt. Returns ErrIdempotencyKeyLost when the key has been
+// taken over by another request.
+func (m *mongoDB) AcceptIdempotencyKey(ctx context.Context, id string, transactionID string, expiresAt time.Time) error {
+	res, err := m.idempotencyKeys.UpdateOne(ctx,
+		bson.M{"_id": id, "transactionId": transactionID},
+		bson.M{"$set": bson.M{"accepted": true, "expiresAt": expiresAt}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrIdempotencyKeyLost
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey releases an idempotency key held for a transaction, so that the request can be retried.
+func (m *mongoDB) DeleteIdempotencyKey(ctx context.Context, id string, transactionID string) error {
+	_, err := m.idempotencyKeys.DeleteOne(ctx, bson.M{"_id": id, "transactionId": transactionID})
+	return err
+}
+
+// ReserveEvent stores a new record of an event being processed by a consumer.
+// If the event is already recorded, the existing record is returned and nothing is stored.
+func (m *mongoDB) ReserveEvent(ctx context.Context, record *ProcessedEvent) (*ProcessedEvent, error) {
+	now := time.Now()
+	record.CreatedAt = now
+
+	// The TTL monitor runs periodically: drop an expired record it has not removed yet, such as the
+	// reservation of a delivery that never completed
+	_, err := m.processedEvents.DeleteOne(ctx, bson.M{
+		"_id":       record.ID,
+		"expiresAt": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("delete expired processed event: %w", err)
+	}
+
+	_, err = m.processedEvents.InsertOne(ctx, record)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("insert processed event: %w", err)
+	}
+
+	var existing ProcessedEvent
+	if err := m.processedEvents.FindOne(ctx, bson.M{"_id": record.ID}).Decode(&existing); err != nil {
+		return nil, fmt.Errorf("get processed event: %w", err)
+	}
+	return &existing, nil
+}
+
+// MarkEventProcessed records that a reserved event has been processed, remembered until expiresAt.
+func (m *mongoDB) MarkEventProcessed(ctx context.Context, id string, expiresAt time.Time) error {
+	_, err := m.processedEvents.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$set": bson.M{"processedAt": time.Now(), "expiresAt": expiresAt}})
+	return err
+}
+
+// ReleaseEvent removes the reservation of an event whose processing failed, so that a redelivery processes it.
+func (m *mongoDB) ReleaseEvent(ctx context.Context, id string) error {
+	_, err := m.processedEvents.DeleteOne(ctx, bson.M{"_id": id, "processedAt": nil})
+	return err
+}
+
+// RecordDeadLetter stores a failed delivery of an event. A dead letter already stored for the event is updated
+// with the last delivery and error, and counts one more attempt.
+func (m *mongoDB) RecordDeadLetter(ctx context.Context, deadLetter *DeadLetter) error {
+	now := time.Now()
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"consumer":      deadLetter.Consumer,
+			"source":        deadLetter.Source,
+			"eventId":       deadLetter.EventID,
+			"type":          deadLetter.Type,
+			"firstFailedAt": now,
+		},
+		"$set": bson.M{
+			"event":        deadLetter.Event,
+			"error":        deadLetter.Error,
+			"lastFailedAt": now,
+		},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"replayedAt": ""},
+	}
+	_, err := m.deadLetters.UpdateOne(ctx, bson.M{"_id": deadLetter.ID}, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// Another delivery inserted the dead letter concurrently
+		_, err = m.deadLetters.UpdateOne(ctx, bson.M{"_id": deadLetter.ID}, update)
+	}
+	return err
+}
+
+// GetDeadLetter retrieves a dead letter by ID.
+func (m *mongoDB) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	var deadLetter DeadLetter
+	err := m.deadLetters.FindOne(ctx, bson.M{"_id": id}).Decode(&deadLetter)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get dead letter: %w", err)
+	}
+	return &deadLetter, nil
+}
+
+// ListDeadLetters retrieves the dead letters of a consumer, or of all consumers when empty, latest failure first.
+// A limit of 0 or less selects DefaultPageLimit.
+func (m *mongoDB) ListDeadLetters(ctx context.Context, consumer string, limit int) ([]*DeadLetter, error) {
+	filter := bson.M{}
+	if consumer != "" {
+		filter["consumer"] = consumer
+	}
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "lastFailedAt", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.deadLetters.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("query dead letters: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deadLetters []*DeadLetter
+	if err := cursor.All(ctx, &deadLetters); err != nil {
+		return nil, fmt.Errorf("decode dead letters: %w", err)
+	}
+	return deadLetters, nil
+}
+
+// MarkDeadLetterReplayed records that a dead letter has been published again.
+func (m *mongoDB) MarkDeadLetterReplayed(ctx context.Context, id string) error {
+	res, err := m.deadLetters.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"replayedAt": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// DeleteDeadLetter removes a dead letter, once its event has been processed or when it is discarded.
+// Returns ErrDeadLetterNotFound when no dead letter matches the ID.
+func (m *mongoDB) DeleteDeadLetter(ctx context.Context, id string) error {
+	res, err := m.deadLetters.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// ScheduleAction stores an action to fire at its due time. Scheduling an action that is already
+// stored, fired or not, leaves it unchanged.
+func (m *mongoDB) ScheduleAction(ctx context.Context, action *ScheduledAction) error {
+	action.CreatedAt = time.Now()
+
+	_, err := m.actions.UpdateOne(ctx,
+		bson.M{"_id": action.ID},
+		bson.M{"$setOnInsert": action},
+		options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// Another replica inserted the action concurrently
+		return nil
+	}
+	return err
+}
+
+// ClaimDueAction leases the earliest due action that is not fired nor leased by another replica.
+// Returns nil when no action is due.
+func (m *mongoDB) ClaimDueAction(ctx context.Context, owner string, leaseDuration time.Duration) (*ScheduledAction, error) {
+	now := time.Now()
+	filter := bson.M{
+		"firedAt": nil,
+		"dueAt":   bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"leaseExpiresAt": nil},
+			bson.M{"leaseExpiresAt": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"leaseOwner":     owner,
+			"leaseExpiresAt": now.Add(leaseDuration),
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "dueAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var action ScheduledAction
+	err := m.actions.FindOneAndUpdate(ctx, filter, update, opts).Decode(&action)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claim due action: %w", err)
+	}
+	return &action, nil
+}
+
+// ExtendActionLease extends the lease of an action that its owner is still firing.
+// Returns ErrLeaseLost when the lease has been taken over by another replica.
+func (m *mongoDB) ExtendActionLease(ctx context.Context, id string, owner string, leaseDuration time.Duration) error {
+	res, err := m.actions.UpdateOne(ctx,
+		bson.M{"_id": id, "leaseOwner": owner, "firedAt": nil},
+		bson.M{"$set": bson.M{"leaseExpiresAt": time.Now().Add(leaseDuration)}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// MarkActionFired records that the owner of the lease of an action has fired it.
+// It does nothing when the lease has been taken over by another replica.
+func (m *mongoDB) MarkActionFired(ctx context.Context, id string, owner string) error {
+	_, err := m.actions.UpdateOne(ctx,
+		bson.M{"_id": id, "leaseOwner": owner},
+		bson.M{"$set": bson.M{"firedAt": time.Now()}})
+	return err
+}
+
+// GetScheduledActions retrieves the actions of a transaction, fired or not.
+func (m *mongoDB) GetScheduledActions(ctx context.Context, transactionID string) ([]*ScheduledAction, error) {
+	cursor, err := m.actions.Find(ctx, bson.M{"transactionId": transactionID})
+	if err != nil {
+		return nil, fmt.Errorf("query scheduled actions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var actions []*ScheduledAction
+	if err := cursor.All(ctx, &actions); err != nil {
+		return nil, fmt.Errorf("decode scheduled actions: %w", err)
+	}
+	return actions, nil
+}
+
+// DeleteScheduledActions removes the actions of a transaction that have not fired yet.
+func (m *mongoDB) DeleteScheduledActions(ctx context.Context, transactionID string) error {
+	_, err := m.actions.DeleteMany(ctx, bson.M{"transactionId": transactionID, "firedAt": nil})
+	return err
+}
+
+// AcquireLease acquires or renews a lease for holder, until duration from now.
+// Returns ErrLeaseHeld when the lease has not expired and belongs to another holder.
+func (m *mongoDB) AcquireLease(ctx context.Context, name string, holder string, duration time.Duration) (*Lease, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"holder": holder},
+			bson.M{"expiresAt": bson.M{"$lte": now}},
+		},
+	}
+
+	// The token and acquisition time only change when the lease changes holder
+	sameHolder := bson.M{"$eq": bson.A{"$holder", holder}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"token": bson.M{"$cond": bson.A{
+			sameHolder,
+			"$token",
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$token", 0}}, 1}},
+		}},
+		"acquiredAt": bson.M{"$cond": bson.A{sameHolder, "$acquiredAt", now}},
+		"holder":     holder,
+		"expiresAt":  now.Add(duration),
+	}}}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var lease Lease
+	err := m.leases.FindOneAndUpdate(ctx, filter, update, opts).Decode(&lease)
+	if mongo.IsDuplicateKeyError(err) {
+		// The lease exists and is held by another holder
+		return nil, ErrLeaseHeld
+	}
+	if err != nil {
+		return nil, fmt.Errorf("acquire lease: %w", err)
+	}
+	return &lease, nil
+}
+
+// CheckLease verifies that the lease is still held with token.
+// Returns ErrLeaseLost when it has expired or has changed holder.
+func (m *mongoDB) CheckLease(ctx context.Context, name string, token int64) error {
+	filter := bson.M{
+		"_id":       name,
+		"token":     token,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+	err := m.leases.FindOne(ctx, filter).Err()
+	if err == mongo.ErrNoDocuments {
+		return ErrLeaseLost
+	}
+	return err
+}
+
+// ReleaseLease expires a lease held by holder, so that another holder can acquire it right away.
+func (m *mongoDB) ReleaseLease(ctx context.Context, name string, holder string) error {
+	_, err := m.leases.UpdateOne(ctx,
+		bson.M{"_id": name, "holder": holder},
+		bson.M{"$set": bson.M{"expiresAt": time.Now()}})
+	return err
+}
+
+// CountLeases returns the number of unexpired leases whose name starts with prefix.
+func (m *mongoDB) CountLeases(ctx context.Context, prefix string) (int, error) {
+	count, err := m.leases.CountDocuments(ctx, bson.M{
+		"_id":       bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)},
+		"expiresAt": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count leases: %w", err)
+	}
+	return int(count), nil
 }
 
-// DeleteOldTransactions removes completed or failed transactions older than the specified time.
+// DeleteOldTransactions removes transactions in a final status older than the specified time.
 func (m *mongoDB) DeleteOldTransactions(ctx context.Context, olderThan time.Time) (int64, error) {
 	log := logger.Get()
 
 	filter := bson.M{
 		"status": bson.M{
-			"$in": []Status{StatusCompleted, StatusFailed},
+			"$in": finalStatuses,
 		},
 		"updatedAt": bson.M{
 			"$lt": olderThan,
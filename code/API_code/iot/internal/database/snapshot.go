@@ -0,0 +1,95 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// snapshotStore holds the snapshots of the device configurations. The choice of the snapshot to keep or to
+// restore is made above it, apart from MongoDB.
+type snapshotStore interface {
+	// get returns the snapshot with the given ID, or ErrOriginalStateNotFound.
+	get(ctx context.Context, id string) (*DeviceOriginalState, error)
+	// oldestUnrestored returns the oldest unrestored snapshot of a device, or ErrOriginalStateNotFound.
+	oldestUnrestored(ctx context.Context, deviceID string) (*DeviceOriginalState, error)
+	// latest returns the latest snapshot of a device, or ErrOriginalStateNotFound.
+	latest(ctx context.Context, deviceID string) (*DeviceOriginalState, error)
+	// insert stores a snapshot. It returns false, and leaves the stored snapshot, when its ID exists.
+	insert(ctx context.Context, snapshot *DeviceOriginalState) (bool, error)
+	// markRestored sets the restore time of all the unrestored snapshots of a device.
+	markRestored(ctx context.Context, deviceID string, at time.Time) error
+}
+
+// storeSnapshot implements StoreDeviceOriginalState over a snapshot store.
+func storeSnapshot(ctx context.Context, store snapshotStore, deviceID string, transactionID string, originalState *DeviceOriginalState) (*DeviceOriginalState, error) {
+	id := DeviceOriginalStateID(deviceID, transactionID)
+	existing, err := store.get(ctx, id)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrOriginalStateNotFound) {
+		return nil, err
+	}
+
+	snapshot := &DeviceOriginalState{
+		ID:                    id,
+		DeviceID:              deviceID,
+		TransactionID:         transactionID,
+		PpMaximumLatency:      originalState.PpMaximumLatency,
+		PpMaximumResponseTime: originalState.PpMaximumResponseTime,
+		Timestamp:             time.Now(),
+
+		AppliedPpMaximumLatency:      originalState.AppliedPpMaximumLatency,
+		AppliedPpMaximumResponseTime: originalState.AppliedPpMaximumResponseTime,
+	}
+	baseline, err := store.oldestUnrestored(ctx, deviceID)
+	if err == nil {
+		snapshot.PpMaximumLatency = baseline.PpMaximumLatency
+		snapshot.PpMaximumResponseTime = baseline.PpMaximumResponseTime
+		snapshot.InheritedFrom = baseline.TransactionID
+	} else if !errors.Is(err, ErrOriginalStateNotFound) {
+		return nil, err
+	}
+
+	inserted, err := store.insert(ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if !inserted {
+		// Stored by a concurrent delivery of the same request
+		return store.get(ctx, id)
+	}
+	return snapshot, nil
+}
+
+// originalState implements GetDeviceOriginalState over a snapshot store.
+func originalState(ctx context.Context, store snapshotStore, deviceID string, transactionID string) (*DeviceOriginalState, error) {
+	if transactionID != "" {
+		state, err := store.get(ctx, DeviceOriginalStateID(deviceID, transactionID))
+		if !errors.Is(err, ErrOriginalStateNotFound) {
+			return state, err
+		}
+	}
+
+	state, err := store.oldestUnrestored(ctx, deviceID)
+	if !errors.Is(err, ErrOriginalStateNotFound) {
+		return state, err
+	}
+	return store.latest(ctx, deviceID)
+}
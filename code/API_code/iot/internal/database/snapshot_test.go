@@ -0,0 +1,202 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySnapshots is a snapshot store keeping the snapshots in the order they were stored.
+type memorySnapshots struct {
+	snapshots []*DeviceOriginalState
+}
+
+func (s *memorySnapshots) get(ctx context.Context, id string) (*DeviceOriginalState, error) {
+	for _, snapshot := range s.snapshots {
+		if snapshot.ID == id {
+			return snapshot, nil
+		}
+	}
+	return nil, ErrOriginalStateNotFound
+}
+
+func (s *memorySnapshots) oldestUnrestored(ctx context.Context, deviceID string) (*DeviceOriginalState, error) {
+	for _, snapshot := range s.snapshots {
+		if snapshot.DeviceID == deviceID && snapshot.RestoredAt == nil {
+			return snapshot, nil
+		}
+	}
+	return nil, ErrOriginalStateNotFound
+}
+
+func (s *memorySnapshots) latest(ctx context.Context, deviceID string) (*DeviceOriginalState, error) {
+	for i := len(s.snapshots) - 1; i >= 0; i-- {
+		if s.snapshots[i].DeviceID == deviceID {
+			return s.snapshots[i], nil
+		}
+	}
+	return nil, ErrOriginalStateNotFound
+}
+
+func (s *memorySnapshots) insert(ctx context.Context, snapshot *DeviceOriginalState) (bool, error) {
+	if _, err := s.get(ctx, snapshot.ID); err == nil {
+		return false, nil
+	}
+	s.snapshots = append(s.snapshots, snapshot)
+	return true, nil
+}
+
+func (s *memorySnapshots) markRestored(ctx context.Context, deviceID string, at time.Time) error {
+	for _, snapshot := range s.snapshots {
+		if snapshot.DeviceID == deviceID && snapshot.RestoredAt == nil {
+			snapshot.RestoredAt = &at
+		}
+	}
+	return nil
+}
+
+// read returns the snapshot to store for a device configuration read from the backend.
+func read(latency string) *DeviceOriginalState {
+	return &DeviceOriginalState{
+		PpMaximumLatency:             latency,
+		PpMaximumResponseTime:        latency,
+		AppliedPpMaximumLatency:      "20",
+		AppliedPpMaximumResponseTime: "20",
+	}
+}
+
+func TestStoreSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps the configuration read", func(t *testing.T) {
+		store := &memorySnapshots{}
+
+		snapshot, err := storeSnapshot(ctx, store, "dev1", "tx1", read("100"))
+
+		require.NoError(t, err)
+		assert.Equal(t, DeviceOriginalStateID("dev1", "tx1"), snapshot.ID)
+		assert.Equal(t, "100", snapshot.PpMaximumLatency)
+		assert.Empty(t, snapshot.InheritedFrom)
+	})
+
+	t.Run("inherits the baseline of an unrestored snapshot", func(t *testing.T) {
+		store := &memorySnapshots{}
+		_, err := storeSnapshot(ctx, store, "dev1", "tx1", read("100"))
+		require.NoError(t, err)
+
+		// The device holds the power-saving values of tx1 by now
+		snapshot, err := storeSnapshot(ctx, store, "dev1", "tx2", read("20"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "100", snapshot.PpMaximumLatency)
+		assert.Equal(t, "100", snapshot.PpMaximumResponseTime)
+		assert.Equal(t, "20", snapshot.AppliedPpMaximumLatency)
+		assert.Equal(t, "tx1", snapshot.InheritedFrom)
+	})
+
+	t.Run("does not inherit from another device", func(t *testing.T) {
+		store := &memorySnapshots{}
+		_, err := storeSnapshot(ctx, store, "dev1", "tx1", read("100"))
+		require.NoError(t, err)
+
+		snapshot, err := storeSnapshot(ctx, store, "dev2", "tx1", read("50"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "50", snapshot.PpMaximumLatency)
+		assert.Empty(t, snapshot.InheritedFrom)
+	})
+
+	t.Run("keeps the snapshot already taken by the transaction", func(t *testing.T) {
+		store := &memorySnapshots{}
+		first, err := storeSnapshot(ctx, store, "dev1", "tx1", read("100"))
+		require.NoError(t, err)
+
+		// A redelivered START reads the power-saving values it applied
+		again, err := storeSnapshot(ctx, store, "dev1", "tx1", read("20"))
+
+		require.NoError(t, err)
+		assert.Equal(t, first, again)
+		assert.Equal(t, "100", again.PpMaximumLatency)
+		assert.Len(t, store.snapshots, 1)
+	})
+
+	t.Run("reads the device again once restored", func(t *testing.T) {
+		store := &memorySnapshots{}
+		_, err := storeSnapshot(ctx, store, "dev1", "tx1", read("100"))
+		require.NoError(t, err)
+		require.NoError(t, store.markRestored(ctx, "dev1", time.Now()))
+
+		snapshot, err := storeSnapshot(ctx, store, "dev1", "tx2", read("70"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "70", snapshot.PpMaximumLatency)
+		assert.Empty(t, snapshot.InheritedFrom)
+	})
+}
+
+func TestMarkRestoredClearsEverySnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := &memorySnapshots{}
+	for _, id := range []string{"tx1", "tx2"} {
+		_, err := storeSnapshot(ctx, store, "dev1", id, read("100"))
+		require.NoError(t, err)
+	}
+	_, err := storeSnapshot(ctx, store, "dev2", "tx1", read("100"))
+	require.NoError(t, err)
+
+	// Restoring the device for tx2 also marks the snapshot of tx1 as restored
+	require.NoError(t, store.markRestored(ctx, "dev1", time.Now()))
+
+	_, err = store.oldestUnrestored(ctx, "dev1")
+	assert.ErrorIs(t, err, ErrOriginalStateNotFound)
+	other, err := store.oldestUnrestored(ctx, "dev2")
+	require.NoError(t, err)
+	assert.Equal(t, "tx1", other.TransactionID)
+}
+
+func TestOriginalState(t *testing.T) {
+	ctx := context.Background()
+	store := &memorySnapshots{}
+	for _, id := range []string{"tx1", "tx2"} {
+		_, err := storeSnapshot(ctx, store, "dev1", id, read("100"))
+		require.NoError(t, err)
+	}
+
+	state, err := originalState(ctx, store, "dev1", "tx2")
+	require.NoError(t, err)
+	assert.Equal(t, "tx2", state.TransactionID, "snapshot of the transaction")
+
+	state, err = originalState(ctx, store, "dev1", "tx3")
+	require.NoError(t, err)
+	assert.Equal(t, "tx1", state.TransactionID, "oldest unrestored snapshot for a transaction without one")
+
+	state, err = originalState(ctx, store, "dev1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "tx1", state.TransactionID, "baseline of the device")
+
+	require.NoError(t, store.markRestored(ctx, "dev1", time.Now()))
+	state, err = originalState(ctx, store, "dev1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "tx2", state.TransactionID, "latest snapshot once restored")
+
+	_, err = originalState(ctx, store, "dev2", "tx1")
+	assert.ErrorIs(t, err, ErrOriginalStateNotFound)
+}
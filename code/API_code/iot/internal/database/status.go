@@ -0,0 +1,129 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status is the lifecycle status of a transaction.
+type Status string
+
+const (
+	StatusScheduled       Status = "scheduled"        // waiting for its START action
+	StatusStarting        Status = "starting"         // START is being applied to the devices
+	StatusActive          Status = "active"           // START completed, waiting for its END action
+	StatusEnding          Status = "ending"           // END is being applied to the devices
+	StatusCompleted       Status = "completed"        // the last action succeeded on all devices
+	StatusPartiallyFailed Status = "partially-failed" // the last action failed on some devices
+	StatusFailed          Status = "failed"           // the last action failed on all devices, or the transaction could not run
+	StatusCancelled       Status = "cancelled"
+	StatusRolledBack      Status = "rolled-back" // START failed on some devices of an atomic transaction, the others are restored
+)
+
+// activeStatuses are the statuses of the transactions that have not reached a final status.
+var activeStatuses = []Status{StatusScheduled, StatusStarting, StatusActive, StatusEnding}
+
+// finalStatuses are the statuses no transition leaves.
+var finalStatuses = []Status{StatusCompleted, StatusPartiallyFailed, StatusFailed, StatusCancelled, StatusRolledBack}
+
+// transitions lists the statuses a transaction can move to from each status. A recurring transaction is
+// never actuated itself, so it moves straight from scheduled to completed once no occurrence is left.
+var transitions = map[Status][]Status{
+	StatusScheduled: {StatusStarting, StatusCompleted, StatusFailed, StatusCancelled},
+	StatusStarting:  {StatusActive, StatusCompleted, StatusPartiallyFailed, StatusFailed, StatusCancelled, StatusRolledBack},
+	StatusActive:    {StatusEnding, StatusFailed, StatusCancelled},
+	StatusEnding:    {StatusCompleted, StatusPartiallyFailed, StatusFailed, StatusCancelled},
+}
+
+// StatusTransition records when a transaction entered a status.
+type StatusTransition struct {
+	Status Status    `bson:"status" json:"status"`
+	At     time.Time `bson:"at" json:"at"`
+}
+
+// Final reports whether no further transition is allowed from the status.
+func (s Status) Final() bool {
+	return len(transitions[s]) == 0
+}
+
+// CanTransition reports whether a transaction can move from one status to another.
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// sourcesOf returns the statuses a transaction can move to the given status from.
+func sourcesOf(to Status) []Status {
+	var sources []Status
+	for _, from := range activeStatuses {
+		if CanTransition(from, to) {
+			sources = append(sources, from)
+		}
+	}
+	return sources
+}
+
+// completedStatus returns the status a transaction moves to once an action has completed on all its devices.
+// START moves a transaction with an END action to active, unless it failed on all devices, or on any device
+// of an atomic transaction. The final status accounts for the devices that failed either action.
+func completedStatus(transaction *Transaction, action string) Status {
+	failed := 0
+	for _, device := range transaction.Devices {
+		startFailed := device.StartAction != nil && device.StartAction.Status == "failed"
+		endFailed := action == "end" && device.EndAction != nil && device.EndAction.Status == "failed"
+		if startFailed || endFailed {
+			failed++
+		}
+	}
+
+	switch {
+	case failed == len(transaction.Devices):
+		return StatusFailed
+	case action == "start" && transaction.Atomic && failed > 0:
+		return StatusRolledBack
+	case action == "start" && transaction.EndAt != nil:
+		return StatusActive
+	case failed == 0:
+		return StatusCompleted
+	default:
+		return StatusPartiallyFailed
+	}
+}
+
+// failedStartDevices returns the IDs of the devices whose START failed.
+func failedStartDevices(transaction *Transaction) []string {
+	var failed []string
+	for _, device := range transaction.Devices {
+		if device.StartAction != nil && device.StartAction.Status == "failed" {
+			failed = append(failed, device.DeviceID)
+		}
+	}
+	return failed
+}
+
+// rollbackReason describes why an atomic transaction was rolled back, listing the devices whose START failed.
+func rollbackReason(transaction *Transaction) string {
+	failed := failedStartDevices(transaction)
+	return fmt.Sprintf("START failed on %d of %d devices, rolled back: %s",
+		len(failed), len(transaction.Devices), strings.Join(failed, ", "))
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(StatusScheduled, StatusStarting))
+	assert.True(t, CanTransition(StatusStarting, StatusActive))
+	assert.True(t, CanTransition(StatusActive, StatusEnding))
+	assert.True(t, CanTransition(StatusEnding, StatusPartiallyFailed))
+	assert.True(t, CanTransition(StatusActive, StatusCancelled))
+
+	assert.False(t, CanTransition(StatusScheduled, StatusActive))
+	assert.False(t, CanTransition(StatusActive, StatusCompleted))
+	assert.False(t, CanTransition(StatusStarting, StatusScheduled))
+	for _, final := range finalStatuses {
+		assert.True(t, final.Final())
+		assert.False(t, CanTransition(final, StatusCancelled))
+	}
+}
+
+func TestSourcesOf(t *testing.T) {
+	assert.Equal(t, []Status{StatusScheduled}, sourcesOf(StatusStarting))
+	assert.Equal(t, []Status{StatusActive}, sourcesOf(StatusEnding))
+	assert.Equal(t, activeStatuses, sourcesOf(StatusCancelled))
+}
+
+func TestCompletedStatus(t *testing.T) {
+	endAt := time.Now()
+	device := func(start, end string) *TransactionDevice {
+		d := &TransactionDevice{StartAction: &DeviceActionStatus{Status: start}}
+		if end != "" {
+			d.EndAction = &DeviceActionStatus{Status: end}
+		}
+		return d
+	}
+
+	tests := []struct {
+		name        string
+		transaction *Transaction
+		action      string
+		want        Status
+	}{
+		{
+			name:        "start without end",
+			transaction: &Transaction{Devices: []*TransactionDevice{device("success", ""), device("success", "")}},
+			action:      "start",
+			want:        StatusCompleted,
+		},
+		{
+			name:        "start without end, some failed",
+			transaction: &Transaction{Devices: []*TransactionDevice{device("success", ""), device("failed", "")}},
+			action:      "start",
+			want:        StatusPartiallyFailed,
+		},
+		{
+			name:        "start with end",
+			transaction: &Transaction{EndAt: &endAt, Devices: []*TransactionDevice{device("success", ""), device("failed", "")}},
+			action:      "start",
+			want:        StatusActive,
+		},
+		{
+			name:        "start with end, all failed",
+			transaction: &Transaction{EndAt: &endAt, Devices: []*TransactionDevice{device("failed", ""), device("failed", "")}},
+			action:      "start",
+			want:        StatusFailed,
+		},
+		{
+			name:        "end",
+			transaction: &Transaction{EndAt: &endAt, Devices: []*TransactionDevice{device("success", "success"), device("success", "success")}},
+			action:      "end",
+			want:        StatusCompleted,
+		},
+		{
+			name:        "end after a failed start",
+			transaction: &Transaction{EndAt: &endAt, Devices: []*TransactionDevice{device("failed", "success"), device("success", "success")}},
+			action:      "end",
+			want:        StatusPartiallyFailed,
+		},
+		{
+			name:        "atomic start, some failed",
+			transaction: &Transaction{Atomic: true, EndAt: &endAt, Devices: []*TransactionDevice{device("success", ""), device("failed", "")}},
+			action:      "start",
+			want:        StatusRolledBack,
+		},
+		{
+			name:        "atomic start, all failed",
+			transaction: &Transaction{Atomic: true, Devices: []*TransactionDevice{device("failed", ""), device("failed", "")}},
+			action:      "start",
+			want:        StatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, completedStatus(tt.transaction, tt.action))
+		})
+	}
+}
+
+func TestRollbackReason(t *testing.T) {
+	transaction := &Transaction{Atomic: true, Devices: []*TransactionDevice{
+		{DeviceID: "device-1@example.com", StartAction: &DeviceActionStatus{Status: "success"}},
+		{DeviceID: "device-2@example.com", StartAction: &DeviceActionStatus{Status: "failed"}},
+		{DeviceID: "device-3@example.com", StartAction: &DeviceActionStatus{Status: "failed"}},
+	}}
+
+	assert.Equal(t, "START failed on 2 of 3 devices, rolled back: device-2@example.com, device-3@example.com", rollbackReason(transaction))
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/correlator"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
 )
@@ -56,7 +57,7 @@ func (h *Handler) Handle(ctx context.Context, e cloudevents.Event) (*cloudevents
 	case string(event.EventTypePowerSavingError):
 		return nil, h.worker.handleErrorNotification(ctx, e)
 	default:
-		logger.Get().Warn("Unknown event type received", zap.String("eventType", e.Type()))
+		logger.FromContext(ctx).Warn("Unknown event type received", zap.String("eventType", e.Type()))
 		return nil, nil
 	}
 }
@@ -125,7 +126,7 @@ func (w *NotificationWorker) Start() error {
 
 // handleAllDevicesCompleted processes incoming all-devices.completed events.
 func (w *NotificationWorker) handleAllDevicesCompleted(ctx context.Context, ce cloudevents.Event) error {
-	log := logger.Get().With(zap.String("eventId", ce.ID()), zap.String("eventType", ce.Type()))
+	log := logger.FromContext(ctx).With(zap.String("eventId", ce.ID()), zap.String("eventType", ce.Type()))
 	log.Debug("Received all-devices.completed event")
 
 	// Parse event data
@@ -149,10 +150,17 @@ func (w *NotificationWorker) handleAllDevicesCompleted(ctx context.Context, ce c
 		zap.String("action", data.Action))
 
 	// Get all device results for this action
-	devices, err := w.database.GetTransactionDevices(ctx, data.TransactionID, data.Action)
+	transaction, err := w.database.GetTransaction(ctx, data.TransactionID)
 	if err != nil {
-		log.Error("Failed to get transaction devices", zap.Error(err))
-		return fmt.Errorf("failed to get transaction devices: %w", err)
+		log.Error("Failed to get transaction", zap.Error(err))
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+	devices := transaction.Devices
+
+	// A rolled back transaction is reported by its error notification, then by the restore of its devices
+	if data.Action == event.ActionStart && transaction.Status == database.StatusRolledBack {
+		log.Info("Transaction rolled back, skipping START notification")
+		return nil
 	}
 
 	log.Debug("Retrieved transaction devices", zap.Int("deviceCount", len(devices)))
@@ -162,9 +170,12 @@ func (w *NotificationWorker) handleAllDevicesCompleted(ctx context.Context, ce c
 	for _, txDevice := range devices {
 		// Get the appropriate action status
 		var actionStatus *database.DeviceActionStatus
-		if data.Action == event.ActionStart {
+		switch data.Action {
+		case event.ActionStart:
 			actionStatus = txDevice.StartAction
-		} else {
+		case event.ActionCancel:
+			actionStatus = cancelActionStatus(txDevice)
+		default:
 			actionStatus = txDevice.EndAction
 		}
 
@@ -180,6 +191,8 @@ func (w *NotificationWorker) handleAllDevicesCompleted(ctx context.Context, ce c
 				status = models.InProgress
 			case "pending":
 				status = models.Pending
+			case "cancelled":
+				status = models.Cancelled
 			default:
 				log.Warn("Unknown status, defaulting to failed", zap.String("status", actionStatus.Status))
 				status = models.Failed
@@ -189,10 +202,21 @@ func (w *NotificationWorker) handleAllDevicesCompleted(ctx context.Context, ce c
 			status = models.Pending
 		}
 
-		activationStatus = append(activationStatus, models.DeviceStatus{
+		deviceStatus := models.DeviceStatus{
 			Device: &txDevice.Device,
 			Status: &status,
-		})
+		}
+		if actionStatus != nil && actionStatus.ErrorCode != "" {
+			errorCode := models.DeviceErrorCode(actionStatus.ErrorCode)
+			deviceStatus.ErrorCode = &errorCode
+			deviceStatus.ErrorMessage = &actionStatus.ErrorMessage
+		}
+		if actionStatus != nil && actionStatus.Drift != "" {
+			drift := models.DriftDecision(actionStatus.Drift)
+			deviceStatus.Drift = &drift
+		}
+
+		activationStatus = append(activationStatus, deviceStatus)
 	}
 
 	// Build PowerSavingResponse
@@ -201,6 +225,9 @@ func (w *NotificationWorker) handleAllDevicesCompleted(ctx context.Context, ce c
 		ActivationStatus: &activationStatus,
 		TransactionId:    &transactionID,
 	}
+	if transaction.ParentTransactionID != "" {
+		response.ParentTransactionId = &transaction.ParentTransactionID
+	}
 
 	// Create CloudEvent
 	notifEvent := cloudevents.NewEvent()
@@ -229,6 +256,9 @@ func (w *NotificationWorker) handleAllDevicesCompleted(ctx context.Context, ce c
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/cloudevents+json")
+	if c := correlator.FromContext(ctx); c != "" {
+		req.Header.Set(correlator.Header, c)
+	}
 
 	// Add authorization if credential provided
 	if authHeader, ok := data.SubscriptionRequest.SinkCredential.AuthorizationHeader(); ok {
@@ -280,9 +310,24 @@ func (w *NotificationWorker) handleAllDevicesCompleted(ctx context.Context, ce c
 	}
 }
 
+// cancelActionStatus returns the status to report for a device of a cancelled transaction.
+// A successfully restored device and a device whose START never ran are both reported as cancelled.
+func cancelActionStatus(txDevice *database.TransactionDevice) *database.DeviceActionStatus {
+	if txDevice.CancelAction != nil {
+		if txDevice.CancelAction.Status == "success" {
+			return &database.DeviceActionStatus{
+				Status:    "cancelled",
+				Timestamp: txDevice.CancelAction.Timestamp,
+			}
+		}
+		return txDevice.CancelAction
+	}
+	return txDevice.StartAction
+}
+
 // handleErrorNotification processes error notification events and sends them to the consumer.
 func (w *NotificationWorker) handleErrorNotification(ctx context.Context, ce cloudevents.Event) error {
-	log := logger.Get().With(zap.String("eventId", ce.ID()), zap.String("eventType", ce.Type()))
+	log := logger.FromContext(ctx).With(zap.String("eventId", ce.ID()), zap.String("eventType", ce.Type()))
 	log.Debug("Received error notification event")
 
 	// Parse event data
@@ -342,6 +387,9 @@ func (w *NotificationWorker) handleErrorNotification(ctx context.Context, ce clo
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/cloudevents+json")
+	if c := correlator.FromContext(ctx); c != "" {
+		req.Header.Set(correlator.Header, c)
+	}
 
 	// Add authorization if credential provided
 	if authHeader, ok := errorData.SubscriptionRequest.SinkCredential.AuthorizationHeader(); ok {
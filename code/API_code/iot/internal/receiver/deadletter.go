@@ -0,0 +1,95 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// DeadLetterStore keeps the events that the consumers failed to process.
+type DeadLetterStore interface {
+	RecordDeadLetter(ctx context.Context, deadLetter *database.DeadLetter) error
+	DeleteDeadLetter(ctx context.Context, id string) error
+}
+
+// DeadLetters records the events that the handler of a consumer fails to process, with the error and the
+// number of failed deliveries, so that they can be inspected and replayed once the cause is fixed.
+// The dead letter of an event is removed once a later delivery processes it.
+type DeadLetters struct {
+	Store DeadLetterStore
+	// Consumer scopes the events, as several services receive the same events
+	Consumer string
+}
+
+type deadLetterHandler struct {
+	deadLetters DeadLetters
+	next        Handler
+}
+
+// Handler returns a handler recording the events that next fails to process.
+func (d DeadLetters) Handler(next Handler) Handler {
+	return &deadLetterHandler{deadLetters: d, next: next}
+}
+
+// Handle passes the event to the next handler. Its error is still returned, so that the broker retries
+// the delivery when configured to. Deferred events are not recorded.
+func (h *deadLetterHandler) Handle(ctx context.Context, e event.Event) (*event.Event, error) {
+	log := logger.FromContext(ctx).With(
+		zap.String("eventId", e.ID()),
+		zap.String("eventSource", e.Source()),
+		zap.String("eventType", e.Type()))
+	id := database.ProcessedEventID(h.deadLetters.Consumer, e.Source(), e.ID())
+
+	result, err := h.next.Handle(ctx, e)
+	if err == nil {
+		if err := h.deadLetters.Store.DeleteDeadLetter(ctx, id); err != nil && !errors.Is(err, database.ErrDeadLetterNotFound) {
+			log.Warn("Failed to remove dead letter of processed event", zap.Error(err))
+		}
+		return result, nil
+	}
+	if errors.Is(err, ErrDeferred) {
+		return result, err
+	}
+
+	encoded, marshalErr := json.Marshal(e)
+	if marshalErr != nil {
+		log.Error("Failed to encode dead letter", zap.Error(marshalErr))
+		return result, err
+	}
+	recordErr := h.deadLetters.Store.RecordDeadLetter(ctx, &database.DeadLetter{
+		ID:       id,
+		Consumer: h.deadLetters.Consumer,
+		Source:   e.Source(),
+		EventID:  e.ID(),
+		Type:     e.Type(),
+		Event:    string(encoded),
+		Error:    err.Error(),
+	})
+	if recordErr != nil {
+		log.Error("Failed to record dead letter", zap.Error(recordErr), zap.NamedError("eventError", err))
+	} else {
+		log.Warn("Event processing failed, recorded as dead letter", zap.Error(err))
+	}
+	return result, err
+}
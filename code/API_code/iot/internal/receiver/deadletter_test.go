@@ -0,0 +1,86 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package receiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+// memoryDeadLetters keeps the dead letters in memory.
+type memoryDeadLetters struct {
+	deadLetters map[string]*database.DeadLetter
+}
+
+func (s *memoryDeadLetters) RecordDeadLetter(ctx context.Context, deadLetter *database.DeadLetter) error {
+	attempts := 0
+	if existing, ok := s.deadLetters[deadLetter.ID]; ok {
+		attempts = existing.Attempts
+	}
+	deadLetter.Attempts = attempts + 1
+	s.deadLetters[deadLetter.ID] = deadLetter
+	return nil
+}
+
+func (s *memoryDeadLetters) DeleteDeadLetter(ctx context.Context, id string) error {
+	if _, ok := s.deadLetters[id]; !ok {
+		return database.ErrDeadLetterNotFound
+	}
+	delete(s.deadLetters, id)
+	return nil
+}
+
+func TestDeadLetterHandler(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryDeadLetters{deadLetters: map[string]*database.DeadLetter{}}
+	next := &countingHandler{err: errors.New("unmarshal actuation data: unexpected end of JSON input")}
+	handler := DeadLetters{Store: store, Consumer: "worker"}.Handler(next)
+	e := newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler")
+	id := database.ProcessedEventID("worker", "urn:tim:iot-scheduler", "tx-1-start-device-0")
+
+	// Failed deliveries are recorded, and the error still reaches the broker
+	for range 2 {
+		_, err := handler.Handle(ctx, e)
+		assert.Error(t, err)
+	}
+	require.Contains(t, store.deadLetters, id)
+	deadLetter := store.deadLetters[id]
+	assert.Equal(t, 2, deadLetter.Attempts)
+	assert.Equal(t, "worker", deadLetter.Consumer)
+	assert.Equal(t, "it.tim.iot.device.actuation.request", deadLetter.Type)
+	assert.Equal(t, next.err.Error(), deadLetter.Error)
+
+	// The stored event can be replayed as delivered
+	var stored event.Event
+	require.NoError(t, json.Unmarshal([]byte(deadLetter.Event), &stored))
+	assert.Equal(t, e.ID(), stored.ID())
+	assert.Equal(t, e.Source(), stored.Source())
+
+	// A later delivery processing the event removes its dead letter
+	next.err = nil
+	_, err := handler.Handle(ctx, e)
+	require.NoError(t, err)
+	assert.NotContains(t, store.deadLetters, id)
+}
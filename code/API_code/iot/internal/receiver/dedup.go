@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receiver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+const (
+	defaultEventTTL   = 24 * time.Hour
+	defaultEventLease = 5 * time.Minute
+)
+
+// ErrEventInProgress is returned for a redelivered event still being processed by an earlier delivery.
+// The broker delivers it again later, and it is processed then if the earlier delivery failed.
+var ErrEventInProgress = fmt.Errorf("%w: event already in progress", ErrDeferred)
+
+// Ledger records the events processed by the consumers.
+type Ledger interface {
+	ReserveEvent(ctx context.Context, record *database.ProcessedEvent) (*database.ProcessedEvent, error)
+	MarkEventProcessed(ctx context.Context, id string, expiresAt time.Time) error
+	ReleaseEvent(ctx context.Context, id string) error
+}
+
+// Dedup hands each CloudEvent, identified by its source and ID, to the handler of a consumer once.
+// Redeliveries of a processed event are acknowledged without side effects.
+type Dedup struct {
+	Ledger Ledger
+	// Consumer scopes the events, as several services receive the same events
+	Consumer string
+	// TTL is how long a processed event is remembered
+	TTL time.Duration
+	// Lease is how long an event is held by a delivery in flight, so that the event is processed again if
+	// the consumer stops before completing it
+	Lease time.Duration
+}
+
+type dedupHandler struct {
+	dedup Dedup
+	next  Handler
+}
+
+// Handler returns a handler passing the events not processed yet to next.
+func (d Dedup) Handler(next Handler) Handler {
+	if d.TTL == 0 {
+		d.TTL = defaultEventTTL
+	}
+	if d.Lease == 0 {
+		d.Lease = defaultEventLease
+	}
+	return &dedupHandler{dedup: d, next: next}
+}
+
+// Handle reserves the event before processing it. The reservation is released when the processing fails,
+// so that a redelivery processes the event again.
+func (h *dedupHandler) Handle(ctx context.Context, e event.Event) (*event.Event, error) {
+	log := logger.FromContext(ctx).With(
+		zap.String("eventId", e.ID()),
+		zap.String("eventSource", e.Source()),
+		zap.String("eventType", e.Type()))
+
+	now := time.Now()
+	id := database.ProcessedEventID(h.dedup.Consumer, e.Source(), e.ID())
+	existing, err := h.dedup.Ledger.ReserveEvent(ctx, &database.ProcessedEvent{
+		ID:        id,
+		Consumer:  h.dedup.Consumer,
+		Source:    e.Source(),
+		EventID:   e.ID(),
+		Type:      e.Type(),
+		ExpiresAt: now.Add(h.dedup.Lease),
+	})
+	if err != nil {
+		log.Error("Failed to reserve event", zap.Error(err))
+		return nil, fmt.Errorf("reserve event: %w", err)
+	}
+	if existing != nil {
+		if existing.ProcessedAt != nil {
+			log.Info("Event already processed, acknowledging duplicate", zap.Time("processedAt", *existing.ProcessedAt))
+			return nil, nil
+		}
+		log.Info("Event already in progress, deferring duplicate", zap.Time("heldUntil", existing.ExpiresAt))
+		return nil, ErrEventInProgress
+	}
+
+	result, err := h.next.Handle(ctx, e)
+	if err != nil {
+		if releaseErr := h.dedup.Ledger.ReleaseEvent(ctx, id); releaseErr != nil {
+			// The reservation expires after the lease
+			log.Warn("Failed to release event", zap.Error(releaseErr))
+		}
+		return result, err
+	}
+
+	if err := h.dedup.Ledger.MarkEventProcessed(ctx, id, time.Now().Add(h.dedup.TTL)); err != nil {
+		// The event is processed: a redelivery after the lease is processed again, as without the ledger
+		log.Warn("Failed to mark event as processed", zap.Error(err))
+	}
+	return result, nil
+}
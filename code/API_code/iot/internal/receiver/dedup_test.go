@@ -0,0 +1,141 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package receiver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+// memoryLedger keeps the processed events in memory, expiring them like the TTL index.
+type memoryLedger struct {
+	events map[string]database.ProcessedEvent
+}
+
+func (l *memoryLedger) ReserveEvent(ctx context.Context, record *database.ProcessedEvent) (*database.ProcessedEvent, error) {
+	if existing, ok := l.events[record.ID]; ok && existing.ExpiresAt.After(time.Now()) {
+		return &existing, nil
+	}
+	l.events[record.ID] = *record
+	return nil, nil
+}
+
+func (l *memoryLedger) MarkEventProcessed(ctx context.Context, id string, expiresAt time.Time) error {
+	record := l.events[id]
+	now := time.Now()
+	record.ProcessedAt = &now
+	record.ExpiresAt = expiresAt
+	l.events[id] = record
+	return nil
+}
+
+func (l *memoryLedger) ReleaseEvent(ctx context.Context, id string) error {
+	delete(l.events, id)
+	return nil
+}
+
+// countingHandler counts the events it handles and fails with err.
+type countingHandler struct {
+	handled int
+	err     error
+}
+
+func (h *countingHandler) Handle(ctx context.Context, e event.Event) (*event.Event, error) {
+	h.handled++
+	return nil, h.err
+}
+
+func newEvent(id string, source string) event.Event {
+	e := event.New()
+	e.SetID(id)
+	e.SetSource(source)
+	e.SetType("it.tim.iot.device.actuation.request")
+	return e
+}
+
+func TestDedupHandler(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("acknowledges a redelivered event", func(t *testing.T) {
+		ledger := &memoryLedger{events: map[string]database.ProcessedEvent{}}
+		next := &countingHandler{}
+		handler := Dedup{Ledger: ledger, Consumer: "worker"}.Handler(next)
+
+		_, err := handler.Handle(ctx, newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler"))
+		require.NoError(t, err)
+		_, err = handler.Handle(ctx, newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, next.handled)
+
+		// The same ID from another source is another event
+		_, err = handler.Handle(ctx, newEvent("tx-1-start-device-0", "urn:tim:iot-worker"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, next.handled)
+	})
+
+	t.Run("scopes the events to the consumer", func(t *testing.T) {
+		ledger := &memoryLedger{events: map[string]database.ProcessedEvent{}}
+		scheduler, notifier := &countingHandler{}, &countingHandler{}
+
+		_, err := Dedup{Ledger: ledger, Consumer: "scheduler"}.Handler(scheduler).Handle(ctx, newEvent("tx-1-start-all-completed", "urn:tim:iot-worker"))
+		require.NoError(t, err)
+		_, err = Dedup{Ledger: ledger, Consumer: "notifier"}.Handler(notifier).Handle(ctx, newEvent("tx-1-start-all-completed", "urn:tim:iot-worker"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, scheduler.handled)
+		assert.Equal(t, 1, notifier.handled)
+	})
+
+	t.Run("processes an event again after a failure", func(t *testing.T) {
+		ledger := &memoryLedger{events: map[string]database.ProcessedEvent{}}
+		next := &countingHandler{err: errors.New("database down")}
+		handler := Dedup{Ledger: ledger, Consumer: "worker"}.Handler(next)
+
+		_, err := handler.Handle(ctx, newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler"))
+		assert.Error(t, err)
+
+		next.err = nil
+		_, err = handler.Handle(ctx, newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, next.handled)
+	})
+
+	t.Run("defers an event in progress until its lease expires", func(t *testing.T) {
+		ledger := &memoryLedger{events: map[string]database.ProcessedEvent{}}
+		id := database.ProcessedEventID("worker", "urn:tim:iot-scheduler", "tx-1-start-device-0")
+		ledger.events[id] = database.ProcessedEvent{ID: id, ExpiresAt: time.Now().Add(time.Minute)}
+		next := &countingHandler{}
+		handler := Dedup{Ledger: ledger, Consumer: "worker"}.Handler(next)
+
+		_, err := handler.Handle(ctx, newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler"))
+		assert.ErrorIs(t, err, ErrEventInProgress)
+		assert.Equal(t, 0, next.handled)
+
+		// The delivery holding the event stopped before completing it
+		ledger.events[id] = database.ProcessedEvent{ID: id, ExpiresAt: time.Now().Add(-time.Second)}
+		_, err = handler.Handle(ctx, newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, next.handled)
+	})
+}
@@ -17,6 +17,7 @@ package receiver
 
 import (
 	"context"
+	"errors"
 
 	"github.com/cloudevents/sdk-go/v2/event"
 )
@@ -26,3 +27,7 @@ import (
 type Handler interface {
 	Handle(context.Context, event.Event) (*event.Event, error)
 }
+
+// ErrDeferred is wrapped by the errors of the events a handler defers, e.g. when the request is cancelled while
+// waiting for a busy device. The broker delivers them again later; they are not failures of the handler.
+var ErrDeferred = errors.New("event deferred")
@@ -0,0 +1,55 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receiver
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// ReplayExtension is the CloudEvents extension attribute naming the consumer a replayed dead letter is meant
+// for. The broker delivers the replay to every consumer of the event, and only that one processes it.
+const ReplayExtension = "replayconsumer"
+
+// Replays keeps a consumer from processing the dead letters replayed for other consumers.
+type Replays struct {
+	Consumer string
+}
+
+type replayHandler struct {
+	consumer string
+	next     Handler
+}
+
+// Handler returns a handler acknowledging the events replayed for other consumers without passing them to next.
+func (r Replays) Handler(next Handler) Handler {
+	return &replayHandler{consumer: r.Consumer, next: next}
+}
+
+func (h *replayHandler) Handle(ctx context.Context, e event.Event) (*event.Event, error) {
+	if target, ok := e.Extensions()[ReplayExtension].(string); ok && target != h.consumer {
+		logger.FromContext(ctx).Debug("Ignoring event replayed for another consumer",
+			zap.String("eventId", e.ID()),
+			zap.String("eventSource", e.Source()),
+			zap.String("replayConsumer", target))
+		return nil, nil
+	}
+	return h.next.Handle(ctx, e)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayHandler(t *testing.T) {
+	ctx := context.Background()
+	next := &countingHandler{}
+	handler := Replays{Consumer: "worker"}.Handler(next)
+
+	// Events delivered by the broker reach the handler
+	_, err := handler.Handle(ctx, newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, next.handled)
+
+	// A replay reaches the handler of its consumer only
+	e := newEvent("tx-1-start-device-0", "urn:tim:iot-scheduler")
+	e.SetExtension(ReplayExtension, "notifier")
+	_, err = handler.Handle(ctx, e)
+	require.NoError(t, err)
+	assert.Equal(t, 1, next.handled)
+
+	e.SetExtension(ReplayExtension, "worker")
+	_, err = handler.Handle(ctx, e)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.handled)
+}
@@ -0,0 +1,205 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// A canary rollout applies START to a share of the devices first. Once the worker reports that all canary
+// devices have completed, the scheduler either schedules START for the other devices (the rollout), or
+// aborts the transaction and restores the canary devices like for a cancellation.
+
+// rolloutAction names the scheduled action actuating the devices left after a successful canary.
+const rolloutAction = "rollout"
+
+// selectCanary marks the first devices of a transaction, in the requested share, as canary devices.
+// No canary phase takes place when the share includes all the devices.
+func selectCanary(transaction *database.Transaction) {
+	if transaction.Canary == nil || transaction.Recurrence != nil {
+		return
+	}
+
+	size := int(math.Ceil(float64(len(transaction.Devices)) * float64(transaction.Canary.Percentage) / 100))
+	if size >= len(transaction.Devices) {
+		return
+	}
+	for _, device := range transaction.Devices[:size] {
+		device.Canary = true
+	}
+	transaction.CanaryStatus = database.CanaryRunning
+}
+
+// actuatedDevices returns the indexes of the devices to actuate when an action fires. START is applied to
+// the canary devices while the canary runs, then to the other devices once it has passed.
+func actuatedDevices(transaction *database.Transaction, action string) []int {
+	indexes := make([]int, 0, len(transaction.Devices))
+	for i, device := range transaction.Devices {
+		if action == event.ActionStart {
+			switch transaction.CanaryStatus {
+			case database.CanaryRunning:
+				if !device.Canary {
+					continue
+				}
+			case database.CanaryPassed:
+				if device.Canary {
+					continue
+				}
+			case database.CanaryEvaluating, database.CanaryAborted:
+				continue
+			}
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// canaryResult counts the canary devices of a transaction and those whose START failed.
+func canaryResult(transaction *database.Transaction) (total int, failed int) {
+	for _, device := range transaction.Devices {
+		if !device.Canary {
+			continue
+		}
+		total++
+		if device.StartAction != nil && device.StartAction.Status == "failed" {
+			failed++
+		}
+	}
+	return total, failed
+}
+
+// handleCanaryCompleted processes canary.completed events: it rolls START out to the other devices when
+// the failure rate of the canary devices is within the threshold, and aborts the transaction otherwise.
+func (s *Scheduler) handleCanaryCompleted(ctx context.Context, e cloudevents.Event) error {
+	log := logger.FromContext(ctx).With(zap.String("eventId", e.ID()), zap.String("eventType", e.Type()))
+
+	var data event.CanaryCompletedData
+	if err := json.Unmarshal(e.Data(), &data); err != nil {
+		log.Error("Failed to unmarshal canary.completed data", zap.Error(err))
+		return fmt.Errorf("unmarshal data: %w", err)
+	}
+	return s.canaryCompleted(ctx, data.TransactionID)
+}
+
+// canaryCompleted evaluates the canary devices of a transaction once all of them have completed START.
+func (s *Scheduler) canaryCompleted(ctx context.Context, transactionID string) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transactionID))
+
+	transaction, err := s.db.GetTransaction(ctx, transactionID)
+	if err != nil {
+		log.Error("Failed to get transaction", zap.Error(err))
+		return fmt.Errorf("get transaction: %w", err)
+	}
+	if transaction.Status.Final() {
+		// An abort interrupted after the transaction was stopped is completed on redelivery
+		if transaction.Status == database.StatusFailed && transaction.CanaryStatus == database.CanaryAborted {
+			log.Info("Transaction aborted by its canary, completing the abort")
+			return s.completeCanaryAbort(ctx, transaction, transaction.ErrorMessage)
+		}
+		log.Debug("Transaction no longer active, ignoring canary result", zap.String("status", string(transaction.Status)))
+		return nil
+	}
+
+	total, failed := canaryResult(transaction)
+	failureRate := float64(failed) * 100 / float64(total)
+	// An atomic transaction is rolled back once START fails on any device: rolling out after a failing
+	// canary would only actuate the whole fleet to restore it
+	threshold := 0.0
+	if transaction.Canary != nil && transaction.Canary.MaxFailurePercentage != nil && !transaction.Atomic {
+		threshold = *transaction.Canary.MaxFailurePercentage
+	}
+
+	// Decide once: a redelivered event follows the recorded decision
+	if transaction.CanaryStatus == database.CanaryEvaluating {
+		decision := database.CanaryPassed
+		if failureRate > threshold {
+			decision = database.CanaryAborted
+		}
+		if _, err := s.db.SetCanaryStatus(ctx, transaction.TransactionID, database.CanaryEvaluating, decision); err != nil {
+			log.Error("Failed to record canary result", zap.Error(err))
+			return fmt.Errorf("set canary status: %w", err)
+		}
+		if transaction, err = s.db.GetTransaction(ctx, transactionID); err != nil {
+			log.Error("Failed to get transaction", zap.Error(err))
+			return fmt.Errorf("get transaction: %w", err)
+		}
+	}
+
+	log.Info("Canary completed",
+		zap.Int("canaryDevices", total),
+		zap.Int("failedDevices", failed),
+		zap.Float64("failureRate", failureRate),
+		zap.Float64("threshold", threshold),
+		zap.String("canaryStatus", string(transaction.CanaryStatus)))
+
+	switch transaction.CanaryStatus {
+	case database.CanaryPassed:
+		return s.storeAction(ctx, database.ScheduledActionID(transaction.TransactionID, rolloutAction), transaction, event.ActionStart, time.Now())
+	case database.CanaryAborted:
+		reason := fmt.Sprintf("canary aborted: %d of %d canary devices failed (%.1f%%), above the %.1f%% threshold", failed, total, failureRate, threshold)
+		return s.abortCanary(ctx, transaction, reason)
+	default:
+		log.Warn("Unexpected canary status, ignoring canary result")
+		return nil
+	}
+}
+
+// abortCanary moves a transaction whose canary failed to the failed status, restores the canary devices
+// already moved to power-saving and reports the reason to the consumer.
+func (s *Scheduler) abortCanary(ctx context.Context, transaction *database.Transaction, reason string) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transaction.TransactionID))
+
+	aborted, err := s.db.AbortTransaction(ctx, transaction.TransactionID, reason)
+	if errors.Is(err, database.ErrTransactionNotCancellable) {
+		log.Debug("Transaction already stopped")
+		return nil
+	}
+	if err != nil {
+		log.Error("Failed to abort transaction", zap.Error(err))
+		return fmt.Errorf("abort transaction: %w", err)
+	}
+	return s.completeCanaryAbort(ctx, aborted, reason)
+}
+
+// completeCanaryAbort unschedules the actions of a transaction aborted by its canary, reports the reason
+// to the consumer and restores the canary devices. It is run again when a step fails, the events it
+// publishes having stable IDs.
+func (s *Scheduler) completeCanaryAbort(ctx context.Context, aborted *database.Transaction, reason string) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", aborted.TransactionID))
+
+	if err := s.db.DeleteScheduledActions(ctx, aborted.TransactionID); err != nil {
+		log.Error("Failed to unschedule actions", zap.Error(err))
+		return fmt.Errorf("delete scheduled actions: %w", err)
+	}
+
+	log.Warn("Transaction aborted", zap.String("reason", reason))
+	s.sendErrorNotification(ctx, aborted.TransactionID, event.ActionStart, http.StatusInternalServerError, "CANARY_ABORTED", reason, aborted.SubscriptionRequest)
+
+	return s.restoreCancelled(ctx, aborted)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+)
+
+// stoppedDatabase holds a single stopped transaction and counts the unscheduling of its actions.
+type stoppedDatabase struct {
+	database.Interface
+	transaction *database.Transaction
+	unscheduled int
+}
+
+func (d *stoppedDatabase) GetTransaction(ctx context.Context, transactionID string) (*database.Transaction, error) {
+	return d.transaction, nil
+}
+
+func (d *stoppedDatabase) DeleteScheduledActions(ctx context.Context, transactionID string) error {
+	d.unscheduled++
+	return nil
+}
+
+func newCanaryTransaction(devices int, percentage int) *database.Transaction {
+	transaction := &database.Transaction{Canary: &models.Canary{Percentage: percentage}}
+	for i := 0; i < devices; i++ {
+		transaction.Devices = append(transaction.Devices, &database.TransactionDevice{})
+	}
+	return transaction
+}
+
+func TestSelectCanary(t *testing.T) {
+	t.Run("rounds up", func(t *testing.T) {
+		transaction := newCanaryTransaction(10, 15)
+		selectCanary(transaction)
+
+		assert.Equal(t, database.CanaryRunning, transaction.CanaryStatus)
+		assert.Equal(t, []int{0, 1}, actuatedDevices(transaction, event.ActionStart))
+	})
+
+	t.Run("share including all devices", func(t *testing.T) {
+		transaction := newCanaryTransaction(1, 10)
+		selectCanary(transaction)
+
+		assert.Empty(t, transaction.CanaryStatus)
+		assert.False(t, transaction.Devices[0].Canary)
+	})
+
+	t.Run("recurring transaction", func(t *testing.T) {
+		transaction := newCanaryTransaction(10, 50)
+		transaction.Recurrence = &models.Recurrence{}
+		selectCanary(transaction)
+
+		assert.Empty(t, transaction.CanaryStatus)
+	})
+}
+
+func TestActuatedDevices(t *testing.T) {
+	transaction := newCanaryTransaction(4, 50)
+	selectCanary(transaction)
+
+	transaction.CanaryStatus = database.CanaryEvaluating
+	assert.Empty(t, actuatedDevices(transaction, event.ActionStart))
+
+	transaction.CanaryStatus = database.CanaryPassed
+	assert.Equal(t, []int{2, 3}, actuatedDevices(transaction, event.ActionStart))
+	assert.Equal(t, []int{0, 1, 2, 3}, actuatedDevices(transaction, event.ActionEnd))
+}
+
+func TestCanaryResult(t *testing.T) {
+	transaction := newCanaryTransaction(4, 75)
+	selectCanary(transaction)
+	transaction.Devices[0].StartAction = &database.DeviceActionStatus{Status: "success"}
+	transaction.Devices[1].StartAction = &database.DeviceActionStatus{Status: "failed"}
+	transaction.Devices[2].StartAction = &database.DeviceActionStatus{Status: "success"}
+	transaction.Devices[3].StartAction = &database.DeviceActionStatus{Status: "failed"}
+
+	total, failed := canaryResult(transaction)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 1, failed)
+}
+
+func TestCanaryCompletedAfterStop(t *testing.T) {
+	tests := []struct {
+		name            string
+		canaryStatus    database.CanaryStatus
+		wantUnscheduled int
+		wantSent        []string
+	}{
+		{
+			name:            "completes an interrupted abort",
+			canaryStatus:    database.CanaryAborted,
+			wantUnscheduled: 1,
+			wantSent:        []string{"tx-1-start-error", "tx-1-cancel-device-0"},
+		},
+		{
+			name:         "ignores a transaction failed otherwise",
+			canaryStatus: database.CanaryPassed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := newCanaryTransaction(2, 50)
+			selectCanary(transaction)
+			transaction.TransactionID = "tx-1"
+			transaction.Status = database.StatusFailed
+			transaction.CanaryStatus = tt.canaryStatus
+			transaction.SubscriptionRequest = models.SubscriptionRequest{Sink: "https://example.com/notify"}
+			transaction.Devices[0].CancelAction = &database.DeviceActionStatus{Status: "pending"}
+
+			db := &stoppedDatabase{transaction: transaction}
+			sender := &recordingSender{}
+			s := &Scheduler{db: db, sender: sender}
+
+			require.NoError(t, s.canaryCompleted(context.Background(), "tx-1"))
+
+			assert.Equal(t, tt.wantUnscheduled, db.unscheduled)
+			assert.Equal(t, tt.wantSent, sender.events())
+		})
+	}
+}
+
+// evaluatingDatabase holds a single transaction whose canary is being evaluated, and records the actions
+// scheduled for it.
+type evaluatingDatabase struct {
+	stoppedDatabase
+	scheduled []string
+}
+
+func (d *evaluatingDatabase) SetCanaryStatus(ctx context.Context, transactionID string, from database.CanaryStatus, to database.CanaryStatus) (bool, error) {
+	if d.transaction.CanaryStatus != from {
+		return false, nil
+	}
+	d.transaction.CanaryStatus = to
+	return true, nil
+}
+
+func (d *evaluatingDatabase) AbortTransaction(ctx context.Context, transactionID string, reason string) (*database.Transaction, error) {
+	d.transaction.Status = database.StatusFailed
+	d.transaction.ErrorMessage = reason
+	for _, device := range d.transaction.Devices {
+		if device.StartAction != nil && device.StartAction.Status == "success" {
+			device.CancelAction = &database.DeviceActionStatus{Status: "pending"}
+		}
+	}
+	return d.transaction, nil
+}
+
+func (d *evaluatingDatabase) ScheduleAction(ctx context.Context, action *database.ScheduledAction) error {
+	d.scheduled = append(d.scheduled, action.ID)
+	return nil
+}
+
+func TestCanaryCompletedThreshold(t *testing.T) {
+	tests := []struct {
+		name          string
+		atomic        bool
+		wantStatus    database.CanaryStatus
+		wantScheduled []string
+		wantSent      []string
+	}{
+		{
+			name:          "rolls out within the threshold",
+			wantStatus:    database.CanaryPassed,
+			wantScheduled: []string{database.ScheduledActionID("tx-1", rolloutAction)},
+		},
+		{
+			name:       "aborts an atomic transaction on any failure",
+			atomic:     true,
+			wantStatus: database.CanaryAborted,
+			wantSent:   []string{"tx-1-start-error", "tx-1-cancel-device-0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxFailurePercentage := 50.0
+			transaction := newCanaryTransaction(4, 50)
+			selectCanary(transaction)
+			transaction.TransactionID = "tx-1"
+			transaction.Status = database.StatusStarting
+			transaction.Atomic = tt.atomic
+			transaction.Canary.MaxFailurePercentage = &maxFailurePercentage
+			transaction.CanaryStatus = database.CanaryEvaluating
+			transaction.SubscriptionRequest = models.SubscriptionRequest{Sink: "https://example.com/notify"}
+			transaction.Devices[0].StartAction = &database.DeviceActionStatus{Status: "success"}
+			transaction.Devices[1].StartAction = &database.DeviceActionStatus{Status: "failed"}
+
+			db := &evaluatingDatabase{stoppedDatabase: stoppedDatabase{transaction: transaction}}
+			sender := &recordingSender{}
+			s := &Scheduler{db: db, sender: sender}
+
+			require.NoError(t, s.canaryCompleted(context.Background(), "tx-1"))
+
+			assert.Equal(t, tt.wantStatus, transaction.CanaryStatus)
+			assert.Equal(t, tt.wantScheduled, db.scheduled)
+			assert.Equal(t, tt.wantSent, sender.events())
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// actionClaim is a due action claimed for an idle worker of the replica. Its lease is renewed from the
+// claim until the worker is done with the action, however long the fan-out is paced.
+type actionClaim struct {
+	action *database.ScheduledAction
+	// lost is done once the lease has been taken over, or could not be renewed in time
+	lost context.Context
+	// release stops renewing the lease and frees the worker slot
+	release func()
+}
+
+// claimOwner returns a lease owner unique to a claim, so that two claims of the same action by a replica,
+// e.g. after its lease expired, are told apart.
+func (s *Scheduler) claimOwner() string {
+	return s.replicaID + "/" + uuid.NewString()[:8]
+}
+
+// holdLease renews the lease of a claimed action every third of the lease duration, until the claim is
+// released or the scheduler stops. The claim is lost when the lease is taken over, or when it could not be
+// renewed before it expired.
+func (s *Scheduler) holdLease(action *database.ScheduledAction) *actionClaim {
+	lost, markLost := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	claim := &actionClaim{
+		action: action,
+		lost:   lost,
+		release: func() {
+			close(done)
+			markLost()
+			<-s.slots
+		},
+	}
+
+	go func() {
+		log := logger.Get().With(
+			zap.String("transactionId", action.TransactionID),
+			zap.String("action", action.Action),
+			zap.String("leaseOwner", action.LeaseOwner))
+		ticker := time.NewTicker(s.leaseDuration / 3)
+		defer ticker.Stop()
+
+		expiresAt := time.Now().Add(s.leaseDuration)
+		for {
+			select {
+			case <-done:
+				return
+			case <-s.stopCh:
+				markLost()
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), s.leaseDuration/3)
+			err := s.db.ExtendActionLease(ctx, action.ID, action.LeaseOwner, s.leaseDuration)
+			cancel()
+			switch {
+			case err == nil:
+				expiresAt = time.Now().Add(s.leaseDuration)
+			case errors.Is(err, database.ErrLeaseLost):
+				log.Warn("Action lease lost, stopping its fan-out")
+				markLost()
+				return
+			case time.Now().After(expiresAt):
+				log.Warn("Action lease expired before it could be renewed, stopping its fan-out", zap.Error(err))
+				markLost()
+				return
+			default:
+				log.Warn("Failed to renew action lease, retrying", zap.Error(err))
+			}
+		}
+	}()
+	return claim
+}
@@ -0,0 +1,185 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"expvar"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+)
+
+// Fan-out metrics, served by the metrics endpoint
+var (
+	fanOutPublished     = expvar.NewInt("scheduler_fanout_published_total")
+	fanOutFailed        = expvar.NewInt("scheduler_fanout_failed_total")
+	fanOutBackendEvents = expvar.NewMap("scheduler_fanout_backend_published_total")
+	fanOutLastRate      = expvar.NewFloat("scheduler_fanout_last_rate")
+	fanOutLastDevices   = expvar.NewInt("scheduler_fanout_last_devices")
+	fanOutReplicas      = expvar.NewInt("scheduler_fanout_replicas")
+)
+
+// fanOut paces the device actuation requests published when a transaction action fires, so that large
+// fleets do not flood the broker and the network. The global and per-backend limiters are shared by all
+// the transactions fired by the replica, and capped to the share of the replica of the configured rates.
+type fanOut struct {
+	rate         rate.Limit
+	jitter       time.Duration
+	global       *rate.Limiter            // nil without a global rate
+	backends     map[string]*rate.Limiter // by realm of the network access identifier
+	backendRates map[string]rate.Limit    // configured rate of each limiter in backends
+}
+
+// newFanOut creates the pacing of a replica. A zero rate does not cap the requests.
+func newFanOut(eventsPerSecond float64, jitter time.Duration, backendRates map[string]float64) *fanOut {
+	f := &fanOut{
+		rate:         rate.Inf,
+		jitter:       jitter,
+		backends:     make(map[string]*rate.Limiter, len(backendRates)),
+		backendRates: make(map[string]rate.Limit, len(backendRates)),
+	}
+	if eventsPerSecond > 0 {
+		f.rate = rate.Limit(eventsPerSecond)
+		f.global = rate.NewLimiter(f.rate, 1)
+	}
+	for backend, eventsPerSecond := range backendRates {
+		if eventsPerSecond > 0 {
+			backend = strings.ToLower(backend)
+			f.backendRates[backend] = rate.Limit(eventsPerSecond)
+			f.backends[backend] = rate.NewLimiter(rate.Limit(eventsPerSecond), 1)
+		}
+	}
+	return f
+}
+
+// capped reports whether the fan-out caps the global or a backend rate.
+func (f *fanOut) capped() bool {
+	return f.global != nil || len(f.backends) > 0
+}
+
+// share caps the global and backend limiters of the replica to an equal share of the configured rates
+// between the live replicas, so that the rates hold across replicas.
+func (f *fanOut) share(replicas int) {
+	replicas = max(replicas, 1)
+	fanOutReplicas.Set(int64(replicas))
+	if f.global != nil {
+		f.global.SetLimit(f.rate / rate.Limit(replicas))
+	}
+	for backend, limiter := range f.backends {
+		limiter.SetLimit(f.backendRates[backend] / rate.Limit(replicas))
+	}
+}
+
+// pacer paces the fan-out of a single transaction action.
+type pacer struct {
+	fanOut      *fanOut
+	transaction *rate.Limiter // nil when the transaction does not lower the global rate
+	jitter      time.Duration
+	startedAt   time.Time
+}
+
+// pacer returns the pacing of a transaction action. The transaction settings can lower the global rate,
+// and replace the global jitter window.
+func (f *fanOut) pacer(settings *models.FanOut) *pacer {
+	p := &pacer{fanOut: f, jitter: f.jitter, startedAt: time.Now()}
+	if settings == nil {
+		return p
+	}
+	if settings.EventsPerSecond != nil && rate.Limit(*settings.EventsPerSecond) < f.rate {
+		p.transaction = rate.NewLimiter(rate.Limit(*settings.EventsPerSecond), 1)
+	}
+	if settings.JitterWindowSeconds != nil {
+		p.jitter = time.Duration(*settings.JitterWindowSeconds) * time.Second
+	}
+	return p
+}
+
+// schedule returns the order in which count devices are published and the offset of each one from the
+// start of the fan-out, spread randomly over the jitter window.
+func (p *pacer) schedule(count int) (order []int, offsets []time.Duration) {
+	order = make([]int, count)
+	offsets = make([]time.Duration, count)
+	for i := range order {
+		order[i] = i
+		if p.jitter > 0 {
+			offsets[i] = rand.N(p.jitter)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return offsets[order[a]] < offsets[order[b]] })
+	return order, offsets
+}
+
+// wait blocks until the device at offset can be published to backend, within the transaction,
+// global and backend rates. It returns early with the error of ctx.
+func (p *pacer) wait(ctx context.Context, offset time.Duration, backend string) error {
+	if delay := time.Until(p.startedAt.Add(offset)); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	for _, limiter := range []*rate.Limiter{p.transaction, p.fanOut.global, p.fanOut.backends[backend]} {
+		if limiter == nil {
+			continue
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// report records published and failed requests in the metrics and returns the achieved rate,
+// in requests per second.
+func (p *pacer) report(published, failed int, backends map[string]int64) float64 {
+	fanOutPublished.Add(int64(published))
+	fanOutFailed.Add(int64(failed))
+	for backend, count := range backends {
+		fanOutBackendEvents.Add(backend, count)
+	}
+
+	elapsed := time.Since(p.startedAt).Seconds()
+	achieved := float64(published)
+	if elapsed > 0 {
+		achieved = math.Round(float64(published)/elapsed*100) / 100
+	}
+	fanOutLastRate.Set(achieved)
+	fanOutLastDevices.Set(int64(published + failed))
+	return achieved
+}
+
+// backendOf returns the backend serving a device: the realm of its network access identifier.
+func backendOf(device models.Device) string {
+	if device.NetworkAccessIdentifier == nil {
+		return ""
+	}
+	_, realm, ok := strings.Cut(string(*device.NetworkAccessIdentifier), "@")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(realm)
+}
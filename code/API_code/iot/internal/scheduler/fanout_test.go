@@ -0,0 +1,101 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+)
+
+func TestFanOutPacer(t *testing.T) {
+	f := newFanOut(100, time.Minute, map[string]float64{"Example.com": 10})
+	lower, higher := 5.0, 1000.0
+	noJitter := 0
+
+	p := f.pacer(nil)
+	assert.Nil(t, p.transaction)
+	assert.Equal(t, time.Minute, p.jitter)
+
+	// A transaction can lower the global rate but not raise it
+	p = f.pacer(&models.FanOut{EventsPerSecond: &lower, JitterWindowSeconds: &noJitter})
+	require.NotNil(t, p.transaction)
+	assert.Equal(t, rate.Limit(5), p.transaction.Limit())
+	assert.Zero(t, p.jitter)
+
+	p = f.pacer(&models.FanOut{EventsPerSecond: &higher})
+	assert.Nil(t, p.transaction)
+
+	// Backends are matched case-insensitively
+	require.Contains(t, f.backends, "example.com")
+	assert.Equal(t, rate.Limit(10), f.backends["example.com"].Limit())
+}
+
+func TestFanOutSchedule(t *testing.T) {
+	p := newFanOut(0, time.Minute, nil).pacer(nil)
+
+	order, offsets := p.schedule(100)
+	require.Len(t, order, 100)
+	require.Len(t, offsets, 100)
+
+	seen := make(map[int]bool)
+	for n, i := range order {
+		assert.False(t, seen[i], "device %d scheduled twice", i)
+		seen[i] = true
+		assert.GreaterOrEqual(t, offsets[i], time.Duration(0))
+		assert.Less(t, offsets[i], time.Minute)
+		if n > 0 {
+			assert.LessOrEqual(t, offsets[order[n-1]], offsets[i], "devices must be published by offset")
+		}
+	}
+
+	// Without jitter, devices are published in order right away
+	order, offsets = newFanOut(0, 0, nil).pacer(nil).schedule(3)
+	assert.Equal(t, []int{0, 1, 2}, order)
+	assert.Equal(t, []time.Duration{0, 0, 0}, offsets)
+}
+
+func TestFanOutWaitRate(t *testing.T) {
+	p := newFanOut(50, 0, nil).pacer(nil)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		require.NoError(t, p.wait(context.Background(), 0, ""))
+	}
+	// The first request is published right away, the next ones every 20ms
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, p.wait(ctx, time.Hour, ""))
+}
+
+func TestBackendOf(t *testing.T) {
+	nai := func(v string) models.Device {
+		id := models.NetworkAccessIdentifier(v)
+		return models.Device{NetworkAccessIdentifier: &id}
+	}
+
+	assert.Equal(t, "example.com", backendOf(nai("device@Example.com")))
+	assert.Equal(t, "", backendOf(nai("device")))
+	assert.Equal(t, "", backendOf(models.Device{}))
+}
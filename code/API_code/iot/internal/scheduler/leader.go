@@ -0,0 +1,151 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// leaderLeaseName is the lease electing the scheduler replica that runs the singleton tasks.
+const leaderLeaseName = "scheduler-leader"
+
+// leader elects one scheduler replica through a lease in the database. The elected replica runs the
+// singleton tasks, such as the retention cleanup and the restore of pending schedules; another replica
+// takes over once the lease expires, e.g. when the leader dies.
+type leader struct {
+	db            database.Interface
+	holder        string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+	onElected     func(ctx context.Context, token int64)
+
+	mu       sync.RWMutex
+	token    int64
+	validity time.Time // local deadline of the lease, zero when not leader
+	tasks    sync.WaitGroup
+}
+
+// Token returns the fencing token of the leader lease while this replica is the leader.
+// Tasks check it with database.CheckLease before acting, so that a replica that lost the lease
+// without noticing does not act alongside the new leader.
+func (l *leader) Token() (int64, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if time.Now().Before(l.validity) {
+		return l.token, true
+	}
+	return 0, false
+}
+
+// run renews the lease every renewInterval until stopCh is closed, then releases it.
+func (l *leader) run(stopCh <-chan struct{}) {
+	log := logger.Get()
+	log.Info("Starting leader election",
+		zap.String("holder", l.holder),
+		zap.Duration("leaseDuration", l.leaseDuration),
+		zap.Duration("renewInterval", l.renewInterval))
+
+	ticker := time.NewTicker(l.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			l.release()
+			return
+		case <-ticker.C:
+			l.renew()
+		}
+	}
+}
+
+// renew acquires or renews the lease, and starts onElected when this replica becomes the leader.
+func (l *leader) renew() {
+	log := logger.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.renewInterval)
+	defer cancel()
+
+	requestedAt := time.Now()
+	lease, err := l.db.AcquireLease(ctx, leaderLeaseName, l.holder, l.leaseDuration)
+
+	l.mu.Lock()
+	wasLeader := requestedAt.Before(l.validity)
+	previousToken := l.token
+	if errors.Is(err, database.ErrLeaseHeld) {
+		l.validity = time.Time{}
+		l.mu.Unlock()
+		if wasLeader {
+			log.Warn("Leader lease taken over by another replica")
+		}
+		return
+	}
+	if err != nil {
+		// Keep the role until the lease expires, the next renewal may succeed
+		l.mu.Unlock()
+		log.Error("Failed to renew leader lease", zap.Error(err))
+		return
+	}
+	// The lease is counted from the request, as the database may have granted it a bit later
+	l.token = lease.Token
+	l.validity = requestedAt.Add(l.leaseDuration)
+	l.mu.Unlock()
+
+	if wasLeader && lease.Token == previousToken {
+		return
+	}
+
+	log.Info("Elected scheduler leader", zap.Int64("token", lease.Token))
+	l.tasks.Add(1)
+	go func() {
+		defer l.tasks.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), l.leaseDuration)
+		defer cancel()
+		l.onElected(ctx, lease.Token)
+	}()
+}
+
+// release waits for the election tasks and gives the lease up, so that another replica takes over
+// without waiting for it to expire.
+func (l *leader) release() {
+	l.tasks.Wait()
+
+	if _, ok := l.Token(); !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := l.db.ReleaseLease(ctx, leaderLeaseName, l.holder); err != nil {
+		logger.Get().Error("Failed to release leader lease", zap.Error(err))
+		return
+	}
+
+	l.mu.Lock()
+	l.validity = time.Time{}
+	l.mu.Unlock()
+	logger.Get().Info("Leader lease released")
+}
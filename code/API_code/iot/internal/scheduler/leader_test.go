@@ -0,0 +1,182 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+// leaseResult is the outcome of an AcquireLease call.
+type leaseResult struct {
+	token int64
+	err   error
+}
+
+// leaseDatabase answers AcquireLease with the given results in turn, and CheckLease with checkErr.
+// It records the calls of the singleton tasks.
+type leaseDatabase struct {
+	database.Interface
+	mu       sync.Mutex
+	results  []leaseResult
+	checkErr error
+	calls    []string
+}
+
+func (d *leaseDatabase) AcquireLease(ctx context.Context, name string, holder string, duration time.Duration) (*database.Lease, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := d.results[0]
+	d.results = d.results[1:]
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &database.Lease{Name: name, Holder: holder, Token: result.token, ExpiresAt: time.Now().Add(duration)}, nil
+}
+
+func (d *leaseDatabase) CheckLease(ctx context.Context, name string, token int64) error {
+	return d.checkErr
+}
+
+func (d *leaseDatabase) GetPendingCancellations(ctx context.Context, cancelledBefore time.Time) ([]*database.Transaction, error) {
+	d.record("GetPendingCancellations")
+	return nil, nil
+}
+
+func (d *leaseDatabase) DeleteOldTransactions(ctx context.Context, olderThan time.Time) (int64, error) {
+	d.record("DeleteOldTransactions")
+	return 0, nil
+}
+
+func (d *leaseDatabase) GetPendingTransactions(ctx context.Context) ([]*database.Transaction, error) {
+	d.record("GetPendingTransactions")
+	return nil, nil
+}
+
+func (d *leaseDatabase) record(call string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, call)
+}
+
+// newTestLeader returns a leader recording the tokens it was elected with.
+func newTestLeader(db database.Interface, leaseDuration time.Duration) (*leader, func() []int64) {
+	var mu sync.Mutex
+	var elected []int64
+	l := &leader{
+		db:            db,
+		holder:        "replica-a",
+		leaseDuration: leaseDuration,
+		renewInterval: leaseDuration / 3,
+		onElected: func(ctx context.Context, token int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			elected = append(elected, token)
+		},
+	}
+	return l, func() []int64 {
+		l.tasks.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return elected
+	}
+}
+
+func TestLeaderElection(t *testing.T) {
+	t.Run("runs onElected once on the first acquisition", func(t *testing.T) {
+		db := &leaseDatabase{results: []leaseResult{{token: 1}, {token: 1}, {token: 1}}}
+		l, elected := newTestLeader(db, time.Minute)
+
+		l.renew()
+		l.renew()
+		l.renew()
+
+		assert.Equal(t, []int64{1}, elected())
+		token, ok := l.Token()
+		assert.True(t, ok)
+		assert.Equal(t, int64(1), token)
+	})
+
+	t.Run("runs onElected again when elected with a new token", func(t *testing.T) {
+		db := &leaseDatabase{results: []leaseResult{{token: 1}, {err: database.ErrLeaseHeld}, {token: 3}}}
+		l, elected := newTestLeader(db, time.Minute)
+
+		l.renew()
+		l.renew()
+		l.renew()
+
+		// onElected runs in the background, in any order
+		assert.ElementsMatch(t, []int64{1, 3}, elected())
+	})
+
+	t.Run("drops the role when the lease is held by another replica", func(t *testing.T) {
+		db := &leaseDatabase{results: []leaseResult{{token: 1}, {err: database.ErrLeaseHeld}}}
+		l, elected := newTestLeader(db, time.Minute)
+
+		l.renew()
+		l.renew()
+
+		_, ok := l.Token()
+		assert.False(t, ok)
+		assert.Equal(t, []int64{1}, elected())
+	})
+
+	t.Run("keeps the role on a transient error until the lease expires", func(t *testing.T) {
+		db := &leaseDatabase{results: []leaseResult{{token: 1}, {err: errors.New("connection reset")}}}
+		l, elected := newTestLeader(db, 100*time.Millisecond)
+
+		l.renew()
+		l.renew()
+
+		token, ok := l.Token()
+		assert.True(t, ok)
+		assert.Equal(t, int64(1), token)
+		assert.Equal(t, []int64{1}, elected())
+
+		require.Eventually(t, func() bool {
+			_, ok := l.Token()
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestSingletonTasksFencedByLease(t *testing.T) {
+	db := &leaseDatabase{results: []leaseResult{{token: 1}}, checkErr: database.ErrLeaseLost}
+	s := New(db, nil, nil, &Config{ReplicaID: "replica-a"})
+	l, _ := newTestLeader(db, time.Minute)
+	s.leader = l
+
+	// This replica still believes it is the leader, while another one has taken the lease over
+	l.renew()
+	_, ok := l.Token()
+	require.True(t, ok)
+
+	s.runCleanup()
+	err := s.loadPendingSchedules(context.Background(), 1)
+
+	assert.ErrorIs(t, err, database.ErrLeaseLost)
+	assert.Empty(t, db.calls)
+}
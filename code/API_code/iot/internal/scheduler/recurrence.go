@@ -0,0 +1,224 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/recurrence"
+)
+
+// A recurring transaction is not actuated itself: the scheduler creates one occurrence transaction at a time
+// and schedules its START like for any other transaction. The next occurrence is created once the current
+// one has ended or has been cancelled.
+
+// scheduleNextOccurrence creates the first occurrence of a recurring transaction starting at or after from
+// and schedules its START. The recurring transaction is completed when no occurrence is left in its time period.
+func (s *Scheduler) scheduleNextOccurrence(ctx context.Context, parent *database.Transaction, from time.Time) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", parent.TransactionID))
+
+	rule, err := recurrence.Parse(parent.Recurrence.Schedule, parent.Recurrence.TimeZone, parent.Recurrence.DurationMinutes)
+	if err != nil {
+		// The API validates the rule, so this only happens with a corrupted record
+		log.Error("Invalid recurrence", zap.Error(err))
+		_ = s.db.MarkTransactionFailed(ctx, parent.TransactionID, fmt.Sprintf("invalid recurrence: %v", err))
+		return fmt.Errorf("parse recurrence: %w", err)
+	}
+
+	// Occurrences never start in the past nor before the time period of the transaction
+	if now := time.Now(); now.After(from) {
+		from = now
+	}
+	if parent.StartAt.After(from) {
+		from = parent.StartAt
+	}
+
+	for {
+		start, end, ok := rule.Next(from)
+		if !ok || (parent.EndAt != nil && !start.Before(*parent.EndAt)) {
+			if err := s.db.MarkTransactionCompleted(ctx, parent.TransactionID); err != nil {
+				log.Error("Failed to mark recurring transaction as completed", zap.Error(err))
+				return fmt.Errorf("mark transaction completed: %w", err)
+			}
+			log.Info("No further occurrence, recurring transaction completed")
+			return nil
+		}
+		if parent.EndAt != nil && end.After(*parent.EndAt) {
+			end = *parent.EndAt
+		}
+
+		occurrence := newOccurrence(parent, start, end)
+		err := s.db.CreateTransaction(ctx, occurrence)
+		if errors.Is(err, database.ErrTransactionExists) {
+			// The occurrence was created by an earlier delivery of the same event or before a restart
+			existing, err := s.db.GetTransaction(ctx, occurrence.TransactionID)
+			if err != nil {
+				log.Error("Failed to get occurrence", zap.Error(err))
+				return fmt.Errorf("get occurrence: %w", err)
+			}
+			if !existing.Status.Final() {
+				log.Debug("Occurrence already created", zap.String("occurrenceId", existing.TransactionID))
+				// Its START may not have been scheduled yet; scheduling it again leaves it unchanged
+				if existing.Status == database.StatusScheduled {
+					return s.scheduleAction(ctx, existing, event.ActionStart, existing.StartAt)
+				}
+				return nil
+			}
+			// The occurrence already ran or was cancelled, look for the following one
+			from = start.Add(time.Minute)
+			continue
+		}
+		if err != nil {
+			log.Error("Failed to create occurrence", zap.Error(err))
+			s.sendErrorNotification(ctx, parent.TransactionID, event.ActionStart, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create transaction in database", parent.SubscriptionRequest)
+			return fmt.Errorf("create occurrence: %w", err)
+		}
+
+		if err := s.scheduleAction(ctx, occurrence, event.ActionStart, occurrence.StartAt); err != nil {
+			return err
+		}
+
+		log.Info("Occurrence scheduled",
+			zap.String("occurrenceId", occurrence.TransactionID),
+			zap.Time("startAt", start),
+			zap.Time("endAt", end))
+		return nil
+	}
+}
+
+// continueRecurrence schedules the next occurrence of the recurring transaction of an occurrence that has
+// ended or has been cancelled. It does nothing for other transactions.
+func (s *Scheduler) continueRecurrence(ctx context.Context, occurrence *database.Transaction) error {
+	if occurrence.ParentTransactionID == "" {
+		return nil
+	}
+	log := logger.FromContext(ctx).With(
+		zap.String("transactionId", occurrence.ParentTransactionID),
+		zap.String("occurrenceId", occurrence.TransactionID))
+
+	parent, err := s.db.GetTransaction(ctx, occurrence.ParentTransactionID)
+	if err != nil {
+		log.Error("Failed to get recurring transaction", zap.Error(err))
+		return fmt.Errorf("get recurring transaction: %w", err)
+	}
+	if parent.Status.Final() {
+		log.Debug("Recurring transaction no longer active, no further occurrence", zap.String("status", string(parent.Status)))
+		return nil
+	}
+
+	// A cancelled occurrence keeps the rest of its window free
+	from := time.Now()
+	if occurrence.EndAt != nil && occurrence.EndAt.After(from) {
+		from = *occurrence.EndAt
+	}
+	return s.scheduleNextOccurrence(ctx, parent, from)
+}
+
+// resumeRecurrence schedules the next occurrence of a recurring transaction left without an active occurrence,
+// e.g. when the scheduler stopped between the end of an occurrence and the creation of the next one.
+func (s *Scheduler) resumeRecurrence(ctx context.Context, parent *database.Transaction) error {
+	_, err := s.db.GetActiveOccurrence(ctx, parent.TransactionID)
+	if err == nil {
+		// The active occurrence is restored with the other pending transactions
+		return nil
+	}
+	if !errors.Is(err, database.ErrTransactionNotFound) {
+		return fmt.Errorf("get active occurrence: %w", err)
+	}
+	return s.scheduleNextOccurrence(ctx, parent, time.Now())
+}
+
+// cancelActiveOccurrence cancels the running occurrence of a cancelled recurring transaction, if any.
+func (s *Scheduler) cancelActiveOccurrence(ctx context.Context, parent *database.Transaction) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", parent.TransactionID))
+
+	occurrence, err := s.db.GetActiveOccurrence(ctx, parent.TransactionID)
+	if errors.Is(err, database.ErrTransactionNotFound) {
+		return nil
+	}
+	if err != nil {
+		log.Error("Failed to get active occurrence", zap.Error(err))
+		return fmt.Errorf("get active occurrence: %w", err)
+	}
+
+	if err := s.db.DeleteScheduledActions(ctx, occurrence.TransactionID); err != nil {
+		log.Error("Failed to unschedule occurrence actions", zap.Error(err))
+		return fmt.Errorf("delete scheduled actions: %w", err)
+	}
+
+	occurrence, err = s.db.CancelTransaction(ctx, occurrence.TransactionID, occurrence.Owner)
+	if errors.Is(err, database.ErrTransactionNotCancellable) {
+		// The occurrence ended in the meantime
+		return nil
+	}
+	if err != nil {
+		log.Error("Failed to cancel active occurrence", zap.Error(err))
+		return fmt.Errorf("cancel occurrence: %w", err)
+	}
+
+	log.Info("Active occurrence cancelled", zap.String("occurrenceId", occurrence.TransactionID))
+	if err := s.restoreCancelled(ctx, occurrence); err != nil {
+		return err
+	}
+	return s.db.CompleteCancelRequest(ctx, occurrence.TransactionID)
+}
+
+// newOccurrence builds the occurrence of a recurring transaction running from start to end.
+// Its ID is derived from the recurring transaction and the start time, so each occurrence is created once.
+func newOccurrence(parent *database.Transaction, start, end time.Time) *database.Transaction {
+	devices := make([]*database.TransactionDevice, 0, len(parent.Devices))
+	for _, device := range parent.Devices {
+		devices = append(devices, &database.TransactionDevice{
+			DeviceID: device.DeviceID,
+			Device:   device.Device,
+			StartAction: &database.DeviceActionStatus{
+				Status:    "pending",
+				Timestamp: time.Now(),
+			},
+		})
+	}
+
+	name := parent.TransactionID + "/" + start.UTC().Format(time.RFC3339)
+	occurrence := &database.Transaction{
+		TransactionID:       uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String(),
+		ParentTransactionID: parent.TransactionID,
+		StartAt:             start,
+		EndAt:               &end,
+		Enabled:             parent.Enabled,
+		Profile:             parent.Profile,
+		ProfileSettings:     parent.ProfileSettings,
+		FanOut:              parent.FanOut,
+		Canary:              parent.Canary,
+		Atomic:              parent.Atomic,
+		SubscriptionRequest: parent.SubscriptionRequest,
+		Owner:               parent.Owner,
+		XCorrelator:         parent.XCorrelator,
+		Status:              database.StatusScheduled,
+		Devices:             devices,
+	}
+	selectCanary(occurrence)
+	return occurrence
+}
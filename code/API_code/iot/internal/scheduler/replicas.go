@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// replicaLeasePrefix prefixes the leases through which the live scheduler replicas announce themselves,
+// so that they share the fan-out rates.
+const replicaLeasePrefix = "scheduler-replica/"
+
+// announce renews the lease announcing the replica, and shares the fan-out rates between the replicas
+// holding one. The share is kept when the replicas cannot be counted.
+func (s *Scheduler) announce() {
+	log := logger.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.leader.renewInterval)
+	defer cancel()
+
+	if _, err := s.db.AcquireLease(ctx, replicaLeasePrefix+s.replicaID, s.replicaID, s.leader.leaseDuration); err != nil {
+		log.Error("Failed to announce scheduler replica", zap.Error(err))
+		return
+	}
+	replicas, err := s.db.CountLeases(ctx, replicaLeasePrefix)
+	if err != nil {
+		log.Error("Failed to count scheduler replicas", zap.Error(err))
+		return
+	}
+	if replicas != s.replicas {
+		log.Info("Sharing fan-out rates between scheduler replicas", zap.Int("replicas", replicas))
+		s.replicas = replicas
+	}
+	s.fanOut.share(replicas)
+}
+
+// runAnnouncements announces the replica every leader renewal interval until stopCh is closed, then
+// withdraws it, so that the other replicas raise their share right away.
+func (s *Scheduler) runAnnouncements() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.leader.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.db.ReleaseLease(ctx, replicaLeasePrefix+s.replicaID, s.replicaID); err != nil {
+				logger.Get().Error("Failed to withdraw scheduler replica", zap.Error(err))
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			s.announce()
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+)
+
+// replicasDatabase holds the leases announcing the replicas, which never expire unless released.
+type replicasDatabase struct {
+	database.Interface
+	mu       sync.Mutex
+	leases   map[string]bool
+	countErr error
+}
+
+func (d *replicasDatabase) AcquireLease(ctx context.Context, name string, holder string, duration time.Duration) (*database.Lease, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.leases[name] = true
+	return &database.Lease{Name: name, Holder: holder}, nil
+}
+
+func (d *replicasDatabase) ReleaseLease(ctx context.Context, name string, holder string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.leases, name)
+	return nil
+}
+
+func (d *replicasDatabase) CountLeases(ctx context.Context, prefix string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.countErr != nil {
+		return 0, d.countErr
+	}
+	count := 0
+	for name := range d.leases {
+		if strings.HasPrefix(name, prefix) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestFanOutSharedBetweenReplicas(t *testing.T) {
+	db := &replicasDatabase{leases: map[string]bool{"scheduler-leader": true}}
+	newReplica := func(id string) *Scheduler {
+		return New(db, &recordingSender{}, nil, &Config{
+			WorkerCount:        1,
+			ReplicaID:          id,
+			FanOutRate:         30,
+			FanOutBackendRates: map[string]float64{"example.com": 6},
+		})
+	}
+	a, b, c := newReplica("replica-a"), newReplica("replica-b"), newReplica("replica-c")
+
+	a.announce()
+	assert.Equal(t, rate.Limit(30), a.fanOut.global.Limit())
+
+	// Each replica caps its requests to its share once it counted the others
+	b.announce()
+	c.announce()
+	for _, s := range []*Scheduler{a, b, c} {
+		s.announce()
+		assert.Equal(t, rate.Limit(10), s.fanOut.global.Limit())
+		assert.Equal(t, rate.Limit(2), s.fanOut.backends["example.com"].Limit())
+	}
+
+	// The share is kept when the replicas cannot be counted
+	db.countErr = errors.New("database unavailable")
+	a.announce()
+	assert.Equal(t, rate.Limit(10), a.fanOut.global.Limit())
+	db.countErr = nil
+
+	// A stopped replica withdraws, and the others raise their share
+	c.wg.Add(1)
+	go c.runAnnouncements()
+	close(c.stopCh)
+	c.wg.Wait()
+	a.announce()
+	assert.Equal(t, rate.Limit(15), a.fanOut.global.Limit())
+	assert.Equal(t, rate.Limit(3), a.fanOut.backends["example.com"].Limit())
+}
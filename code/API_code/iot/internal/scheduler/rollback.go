@@ -0,0 +1,49 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// An atomic transaction applies START to all its devices or to none. When START completes with a failure on
+// some devices, the update completing it moves the transaction to rolled-back and flags the devices whose START
+// succeeded for restore; the scheduler then restores them like for a cancellation.
+
+// completeRollback unschedules the actions of a rolled back transaction, reports the failing devices to the
+// consumer in a single error notification and restores the other devices. It is run again when a step fails,
+// the events it publishes having stable IDs.
+func (s *Scheduler) completeRollback(ctx context.Context, transaction *database.Transaction) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transaction.TransactionID))
+
+	if err := s.db.DeleteScheduledActions(ctx, transaction.TransactionID); err != nil {
+		log.Error("Failed to unschedule actions", zap.Error(err))
+		return fmt.Errorf("delete scheduled actions: %w", err)
+	}
+
+	log.Warn("Transaction rolled back", zap.String("reason", transaction.ErrorMessage))
+	s.sendErrorNotification(ctx, transaction.TransactionID, event.ActionStart, http.StatusConflict, "ROLLED_BACK", transaction.ErrorMessage, transaction.SubscriptionRequest)
+
+	return s.restoreCancelled(ctx, transaction)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+)
+
+func TestStartCompletedRollsBack(t *testing.T) {
+	endAt := time.Now().Add(time.Hour)
+	transaction := &database.Transaction{
+		TransactionID:       "tx-1",
+		EndAt:               &endAt,
+		Atomic:              true,
+		Status:              database.StatusRolledBack,
+		ErrorMessage:        "START failed on 1 of 3 devices, rolled back: device-2",
+		SubscriptionRequest: models.SubscriptionRequest{Sink: "https://example.com/notify"},
+		Devices: []*database.TransactionDevice{
+			{DeviceID: "device-0", StartAction: &database.DeviceActionStatus{Status: "success"}, CancelAction: &database.DeviceActionStatus{Status: "pending"}},
+			{DeviceID: "device-1", StartAction: &database.DeviceActionStatus{Status: "success"}, CancelAction: &database.DeviceActionStatus{Status: "pending"}},
+			{DeviceID: "device-2", StartAction: &database.DeviceActionStatus{Status: "failed"}},
+		},
+	}
+
+	db := &stoppedDatabase{transaction: transaction}
+	sender := &recordingSender{}
+	s := &Scheduler{db: db, sender: sender}
+
+	require.NoError(t, s.actionCompleted(context.Background(), event.AllDevicesCompletedData{TransactionID: "tx-1", Action: event.ActionStart}))
+
+	// END is not scheduled: a single error notification is sent and the started devices are restored
+	assert.Equal(t, 1, db.unscheduled)
+	assert.Equal(t, []string{"tx-1-start-error", "tx-1-cancel-device-0", "tx-1-cancel-device-1"}, sender.events())
+
+	// Devices rejecting START are not an internal error
+	notification, ok := sender.data[0].(event.ErrorNotificationData)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, notification.Status)
+	assert.Equal(t, "ROLLED_BACK", notification.Code)
+}
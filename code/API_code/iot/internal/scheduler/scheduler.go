@@ -18,46 +18,69 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
 	"sync"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/correlator"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
 )
 
 const (
 	defaultWorkerCount     = 10
-	defaultChannelSize     = 100
 	defaultRetentionPeriod = 168 * time.Hour // 7 days
 	defaultCleanupInterval = 1 * time.Hour
+	defaultPollInterval    = 1 * time.Second
+	defaultLeaseDuration   = 1 * time.Minute
+
+	defaultLeaderLeaseDuration = 15 * time.Second
+	defaultLeaderRenewInterval = 5 * time.Second
+
+	defaultWatchdogInterval  = 1 * time.Minute
+	defaultActionTimeout     = 5 * time.Minute
+	defaultActionMaxAttempts = 3
 )
 
-// scheduleAction represents a scheduled action with its subscription details
-type scheduleAction struct {
-	TransactionID       string
-	Action              string
-	SubscriptionRequest models.SubscriptionRequest
-}
+// errFanOutInterrupted is returned when the fan-out of an action stops before all devices are published,
+// or when its lease is lost. The action is not marked as fired, so that it is fired again once its lease expires.
+var errFanOutInterrupted = errors.New("fan-out interrupted")
 
 // Scheduler handles scheduling and firing of device actuation requests.
+// START and END actions are stored in the database with their due time, so that any number of
+// replicas can share them: each replica polls for due actions and claims them with a lease.
+// Singleton tasks run on the replica elected as leader.
 type Scheduler struct {
 	db              database.Interface
 	sender          event.Sender
 	receiver        event.Receiver
-	fireChan        chan scheduleAction // buffered channel for schedule actions to fire
+	fireChan        chan *actionClaim // claimed actions handed over to the workers
+	slots           chan struct{}     // one per worker busy with a claimed action
 	workerCount     int
-	mu              sync.RWMutex
-	timers          map[string]*time.Timer // track active timers for cleanup
 	stopCh          chan struct{}
 	wg              sync.WaitGroup
 	retentionPeriod time.Duration
 	cleanupInterval time.Duration
+	replicaID       string
+	pollInterval    time.Duration
+	leaseDuration   time.Duration
+	leader          *leader
+	fanOut          *fanOut
+	replicas        int // live replicas the fan-out rates were last shared between
+	stopWorkers     context.CancelFunc
+
+	watchdogInterval  time.Duration
+	actionTimeout     time.Duration
+	actionMaxAttempts int
 }
 
 // Handler implements receiver.Handler interface for CloudEvents.
@@ -72,6 +95,10 @@ func (h *Handler) Handle(ctx context.Context, e cloudevents.Event) (*cloudevents
 		return nil, h.scheduler.handleScheduleRequested(ctx, e)
 	case string(event.EventTypeAllDevicesCompleted):
 		return nil, h.scheduler.handleAllDevicesCompleted(ctx, e)
+	case string(event.EventTypeCancelRequested):
+		return nil, h.scheduler.handleCancelRequested(ctx, e)
+	case string(event.EventTypeCanaryCompleted):
+		return nil, h.scheduler.handleCanaryCompleted(ctx, e)
 	default:
 		return nil, fmt.Errorf("unknown event type: %s", e.Type())
 	}
@@ -80,9 +107,26 @@ func (h *Handler) Handle(ctx context.Context, e cloudevents.Event) (*cloudevents
 // Config holds scheduler configuration.
 type Config struct {
 	WorkerCount     int
-	ChannelSize     int
 	RetentionPeriod time.Duration
 	CleanupInterval time.Duration
+	PollInterval    time.Duration
+	LeaseDuration   time.Duration
+	// ReplicaID identifies the replica holding a lease. It defaults to the host name and a random suffix.
+	ReplicaID string
+	// LeaderLeaseDuration and LeaderRenewInterval configure the election of the leader replica.
+	LeaderLeaseDuration time.Duration
+	LeaderRenewInterval time.Duration
+	// FanOutRate caps the device actuation requests published per second, 0 for no cap. FanOutBackendRates
+	// caps them per backend. Both are shared between the live replicas. FanOutJitter spreads the requests of
+	// an action randomly over a window.
+	FanOutRate         float64
+	FanOutJitter       time.Duration
+	FanOutBackendRates map[string]float64
+	// WatchdogInterval is how often the leader looks for device actions without result for ActionTimeout.
+	// They are published again, up to ActionMaxAttempts times in all, then marked as failed.
+	WatchdogInterval  time.Duration
+	ActionTimeout     time.Duration
+	ActionMaxAttempts int
 }
 
 // New creates a new Scheduler instance.
@@ -90,57 +134,123 @@ func New(db database.Interface, sender event.Sender, receiver event.Receiver, cf
 	if cfg == nil {
 		cfg = &Config{
 			WorkerCount:     defaultWorkerCount,
-			ChannelSize:     defaultChannelSize,
 			RetentionPeriod: defaultRetentionPeriod,
 			CleanupInterval: defaultCleanupInterval,
 		}
 	}
 
 	// Apply defaults for zero values
+	if cfg.WorkerCount == 0 {
+		cfg.WorkerCount = defaultWorkerCount
+	}
 	if cfg.RetentionPeriod == 0 {
 		cfg.RetentionPeriod = defaultRetentionPeriod
 	}
 	if cfg.CleanupInterval == 0 {
 		cfg.CleanupInterval = defaultCleanupInterval
 	}
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.LeaseDuration == 0 {
+		cfg.LeaseDuration = defaultLeaseDuration
+	}
+	if cfg.LeaderLeaseDuration == 0 {
+		cfg.LeaderLeaseDuration = defaultLeaderLeaseDuration
+	}
+	if cfg.LeaderRenewInterval == 0 {
+		cfg.LeaderRenewInterval = defaultLeaderRenewInterval
+	}
+	if cfg.WatchdogInterval == 0 {
+		cfg.WatchdogInterval = defaultWatchdogInterval
+	}
+	if cfg.ActionTimeout == 0 {
+		cfg.ActionTimeout = defaultActionTimeout
+	}
+	if cfg.ActionMaxAttempts == 0 {
+		cfg.ActionMaxAttempts = defaultActionMaxAttempts
+	}
+	if cfg.ReplicaID == "" {
+		hostname, _ := os.Hostname()
+		cfg.ReplicaID = hostname + "-" + uuid.NewString()[:8]
+	}
 
-	return &Scheduler{
+	s := &Scheduler{
 		db:              db,
 		sender:          sender,
 		receiver:        receiver,
-		fireChan:        make(chan scheduleAction, cfg.ChannelSize),
+		fireChan:        make(chan *actionClaim, cfg.WorkerCount),
+		slots:           make(chan struct{}, cfg.WorkerCount),
 		workerCount:     cfg.WorkerCount,
-		timers:          make(map[string]*time.Timer),
 		stopCh:          make(chan struct{}),
 		retentionPeriod: cfg.RetentionPeriod,
 		cleanupInterval: cfg.CleanupInterval,
-	}
+		replicaID:       cfg.ReplicaID,
+		pollInterval:    cfg.PollInterval,
+		leaseDuration:   cfg.LeaseDuration,
+		fanOut:          newFanOut(cfg.FanOutRate, cfg.FanOutJitter, cfg.FanOutBackendRates),
+
+		watchdogInterval:  cfg.WatchdogInterval,
+		actionTimeout:     cfg.ActionTimeout,
+		actionMaxAttempts: cfg.ActionMaxAttempts,
+	}
+	s.leader = &leader{
+		db:            db,
+		holder:        cfg.ReplicaID,
+		leaseDuration: cfg.LeaderLeaseDuration,
+		renewInterval: cfg.LeaderRenewInterval,
+		onElected:     s.onElected,
+	}
+	return s
 }
 
 // Start begins processing schedule.requested events and starts the worker pool.
 func (s *Scheduler) Start(ctx context.Context) error {
-	log := logger.Get()
+	log := logger.FromContext(ctx)
 	log.Info("Starting scheduler",
 		zap.Int("workers", s.workerCount),
+		zap.String("replicaId", s.replicaID),
+		zap.Duration("pollInterval", s.pollInterval),
+		zap.Duration("leaseDuration", s.leaseDuration),
 		zap.Duration("retentionPeriod", s.retentionPeriod),
 		zap.Duration("cleanupInterval", s.cleanupInterval))
 
-	// Load pending schedules from database on startup
-	if err := s.loadPendingSchedules(ctx); err != nil {
-		log.Error("Failed to load pending schedules on startup", zap.Error(err))
-		// Continue anyway - new schedules will still work
+	// Run for leader right away, so that a single replica is elected without waiting for a renewal.
+	// The leader schedules the actions of pending transactions that may have been missed.
+	s.leader.renew()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.leader.run(s.stopCh)
+	}()
+
+	// Share the fan-out rates with the other replicas before any action is fired
+	if s.fanOut.capped() {
+		s.announce()
+		s.wg.Add(1)
+		go s.runAnnouncements()
 	}
 
-	// Start worker pool
+	// Start worker pool. Stopping the workers interrupts the fan-outs they are pacing.
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	s.stopWorkers = stopWorkers
 	for i := 0; i < s.workerCount; i++ {
 		s.wg.Add(1)
-		go s.worker(ctx, i)
+		go s.worker(workerCtx, i)
 	}
 
+	// Start poller claiming due actions
+	s.wg.Add(1)
+	go s.poller()
+
 	// Start cleanup goroutine for old transactions
 	s.wg.Add(1)
 	go s.cleanupWorker(ctx)
 
+	// Start watchdog recovering stuck device actions
+	s.wg.Add(1)
+	go s.watchdog(ctx)
+
 	// Start event receiver with handler
 	handler := &Handler{scheduler: s}
 	return s.receiver.Start(handler)
@@ -148,31 +258,80 @@ func (s *Scheduler) Start(ctx context.Context) error {
 
 // Stop gracefully shuts down the scheduler.
 func (s *Scheduler) Stop(ctx context.Context) error {
-	log := logger.Get()
+	log := logger.FromContext(ctx)
 	log.Info("Stopping scheduler")
 
+	// Stop polling and wait for workers. Claimed actions that were not fired are taken over by
+	// other replicas once their lease expires.
 	close(s.stopCh)
-
-	// Cancel all active timers
-	s.mu.Lock()
-	for scheduleID, timer := range s.timers {
-		timer.Stop()
-		delete(s.timers, scheduleID)
+	if s.stopWorkers != nil {
+		s.stopWorkers()
 	}
-	s.mu.Unlock()
-
-	// Close fire channel and wait for workers
-	close(s.fireChan)
 	s.wg.Wait()
 
 	log.Info("Scheduler stopped")
 	return nil
 }
 
-// cleanupWorker periodically removes old completed/failed transactions.
-func (s *Scheduler) cleanupWorker(ctx context.Context) {
+// poller periodically claims the due actions and hands them over to the workers.
+func (s *Scheduler) poller() {
 	defer s.wg.Done()
 	log := logger.Get()
+	log.Info("Starting poller", zap.Duration("interval", s.pollInterval))
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			log.Debug("Poller stopping")
+			return
+		case <-ticker.C:
+			s.claimDueActions()
+		}
+	}
+}
+
+// claimDueActions claims a due action for each idle worker, so that no claimed action waits for a worker.
+// The lease of a claimed action is renewed from the claim until its worker is done with it.
+func (s *Scheduler) claimDueActions() {
+	log := logger.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for {
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			// All workers are busy
+			return
+		}
+
+		action, err := s.db.ClaimDueAction(ctx, s.claimOwner(), s.leaseDuration)
+		if err != nil || action == nil {
+			<-s.slots
+			if err != nil {
+				log.Error("Failed to claim due action", zap.Error(err))
+			}
+			return
+		}
+
+		log.Debug("Action claimed",
+			zap.String("transactionId", action.TransactionID),
+			zap.String("action", action.Action),
+			zap.String("leaseOwner", action.LeaseOwner),
+			zap.Time("dueAt", action.DueAt))
+		// The channel has room for a claim per worker slot
+		s.fireChan <- s.holdLease(action)
+	}
+}
+
+// cleanupWorker periodically removes old completed/failed transactions, when this replica is the leader.
+func (s *Scheduler) cleanupWorker(ctx context.Context) {
+	defer s.wg.Done()
+	log := logger.FromContext(ctx)
 	log.Info("Starting cleanup worker",
 		zap.Duration("interval", s.cleanupInterval),
 		zap.Duration("retentionPeriod", s.retentionPeriod))
@@ -197,11 +356,21 @@ func (s *Scheduler) cleanupWorker(ctx context.Context) {
 
 // runCleanup performs the actual cleanup of old transactions.
 func (s *Scheduler) runCleanup() {
-	log := logger.Get()
-
 	// Use background context with timeout for cleanup operations
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
+	log := logger.FromContext(ctx)
+
+	token, ok := s.leader.Token()
+	if !ok {
+		log.Debug("Not the leader, skipping cleanup")
+		return
+	}
+
+	if err := s.db.CheckLease(ctx, leaderLeaseName, token); err != nil {
+		log.Warn("Leader lease lost, skipping cleanup", zap.Error(err))
+		return
+	}
 
 	cutoffTime := time.Now().Add(-s.retentionPeriod)
 	log.Debug("Running transaction cleanup",
@@ -223,7 +392,7 @@ func (s *Scheduler) runCleanup() {
 
 // handleScheduleRequested processes incoming schedule.requested events
 func (s *Scheduler) handleScheduleRequested(ctx context.Context, e cloudevents.Event) error {
-	log := logger.Get().With(zap.String("eventId", e.ID()), zap.String("eventType", e.Type()))
+	log := logger.FromContext(ctx).With(zap.String("eventId", e.ID()), zap.String("eventType", e.Type()))
 
 	var data event.ScheduleRequestedData
 	if err := json.Unmarshal(e.Data(), &data); err != nil {
@@ -260,56 +429,78 @@ func (s *Scheduler) handleScheduleRequested(ctx context.Context, e cloudevents.E
 		EndAt:               data.EndAt,
 		Enabled:             data.Payload.Enabled,
 		SubscriptionRequest: data.Payload.SubscriptionRequest,
-		Status:              database.StatusPending,
+		Owner:               data.Payload.Owner,
+		XCorrelator:         correlator.FromContext(ctx),
+		Recurrence:          data.Payload.Recurrence,
+		Profile:             data.Payload.Profile,
+		ProfileSettings:     data.Payload.ProfileSettings,
+		FanOut:              data.Payload.FanOut,
+		Canary:              data.Payload.Canary,
+		Atomic:              data.Payload.Atomic,
+		Status:              database.StatusScheduled,
 		Devices:             devices,
 	}
+	selectCanary(transaction)
 
 	// Create transaction in MongoDB
-	if err := s.db.CreateTransaction(ctx, transaction); err != nil {
+	err := s.db.CreateTransaction(ctx, transaction)
+	if errors.Is(err, database.ErrTransactionExists) {
+		// Redelivered event: the transaction is stored but its START may not have been scheduled
+		transaction, err = s.db.GetTransaction(ctx, data.Payload.TransactionID)
+		if err != nil {
+			log.Error("Failed to get transaction", zap.Error(err), zap.String("transactionId", data.Payload.TransactionID))
+			return fmt.Errorf("get transaction: %w", err)
+		}
+		if transaction.Status.Final() {
+			log.Debug("Transaction already created and no longer active", zap.String("transactionId", transaction.TransactionID))
+			return nil
+		}
+		log.Info("Transaction already created, scheduling it again", zap.String("transactionId", transaction.TransactionID))
+	} else if err != nil {
 		log.Error("Failed to create transaction", zap.Error(err), zap.String("transactionId", data.Payload.TransactionID))
 
 		// Send error notification to consumer
-		s.sendErrorNotification(ctx, data.Payload.TransactionID, event.ActionStart, "INTERNAL_ERROR", "Failed to create transaction in database", data.Payload.SubscriptionRequest)
+		s.sendErrorNotification(ctx, data.Payload.TransactionID, event.ActionStart, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create transaction in database", data.Payload.SubscriptionRequest)
 
 		return fmt.Errorf("create transaction: %w", err)
 	}
 
-	// Calculate delay until start
-	startDelay := time.Until(data.StartAt)
-	if startDelay < 0 {
-		startDelay = 0 // execute immediately if in the past
+	// A recurring transaction is actuated through its occurrences
+	if transaction.Recurrence != nil {
+		return s.scheduleNextOccurrence(ctx, transaction, transaction.StartAt)
 	}
 
-	log.Debug("Arming start timer",
-		zap.String("transactionId", data.Payload.TransactionID),
-		zap.Duration("startDelay", startDelay))
-
-	// Arm time.AfterFunc timer for start
-	startTimer := time.AfterFunc(startDelay, func() {
-		s.enqueueScheduleAction(data.Payload.TransactionID, event.ActionStart, data.Payload.SubscriptionRequest)
-	})
-
-	// Track start timer for cleanup
-	startTimerKey := data.Payload.TransactionID + "-start"
-	s.mu.Lock()
-	s.timers[startTimerKey] = startTimer
-	s.mu.Unlock()
-
-	// Note: END timer will be armed after START action completes
+	// Note: END is scheduled after START action completes
 	// This is handled in handleAllDevicesCompleted
-
-	return nil
+	return s.scheduleAction(ctx, transaction, event.ActionStart, transaction.StartAt)
 }
 
-// handleAllDevicesCompleted processes all-devices.completed events to arm END timer after START completes
+// handleAllDevicesCompleted processes all-devices.completed events to schedule END after START completes
 func (s *Scheduler) handleAllDevicesCompleted(ctx context.Context, e cloudevents.Event) error {
-	log := logger.Get().With(zap.String("eventId", e.ID()), zap.String("eventType", e.Type()))
+	log := logger.FromContext(ctx).With(zap.String("eventId", e.ID()), zap.String("eventType", e.Type()))
 
 	var data event.AllDevicesCompletedData
 	if err := json.Unmarshal(e.Data(), &data); err != nil {
 		log.Error("Failed to unmarshal all-devices.completed data", zap.Error(err))
 		return fmt.Errorf("unmarshal data: %w", err)
 	}
+	return s.actionCompleted(ctx, data)
+}
+
+// actionCompleted schedules END once START has completed on all devices, and moves recurring transactions
+// to their next occurrence once an occurrence has ended or has been cancelled.
+func (s *Scheduler) actionCompleted(ctx context.Context, data event.AllDevicesCompletedData) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", data.TransactionID))
+
+	// The end or cancellation of an occurrence moves its recurring transaction to the next occurrence
+	if data.Action == event.ActionEnd || data.Action == event.ActionCancel {
+		transaction, err := s.db.GetTransaction(ctx, data.TransactionID)
+		if err != nil {
+			log.Error("Failed to get transaction", zap.Error(err))
+			return fmt.Errorf("get transaction: %w", err)
+		}
+		return s.continueRecurrence(ctx, transaction)
+	}
 
 	// Check only if START action has completed
 	if data.Action != event.ActionStart {
@@ -317,7 +508,7 @@ func (s *Scheduler) handleAllDevicesCompleted(ctx context.Context, e cloudevents
 		return nil
 	}
 
-	log.Debug("START action completed, checking if END timer should be armed",
+	log.Debug("START action completed, checking if END should be scheduled",
 		zap.String("transactionId", data.TransactionID))
 
 	// Get transaction to check if endAt is defined
@@ -327,41 +518,188 @@ func (s *Scheduler) handleAllDevicesCompleted(ctx context.Context, e cloudevents
 		return fmt.Errorf("get transaction: %w", err)
 	}
 
+	// A cancelled transaction must not be ended by its schedule, and one whose START failed on all
+	// devices has nothing to end
+	if transaction.Status.Final() {
+		log.Debug("Transaction no longer active, skipping END",
+			zap.String("transactionId", data.TransactionID),
+			zap.String("status", string(transaction.Status)))
+		switch transaction.Status {
+		case database.StatusFailed:
+			return s.continueRecurrence(ctx, transaction)
+		case database.StatusRolledBack:
+			// The recurrence moves on once the devices are restored
+			return s.completeRollback(ctx, transaction)
+		}
+		return nil
+	}
+
 	// If no endAt defined, nothing to do
 	if transaction.EndAt == nil {
-		log.Debug("No END time defined, skipping END timer",
+		log.Debug("No END time defined, skipping END",
 			zap.String("transactionId", data.TransactionID))
 		return nil
 	}
 
-	// Calculate delay from NOW until endAt
-	endDelay := time.Until(*transaction.EndAt)
-	if endDelay < 0 {
-		endDelay = 0 // execute immediately if in the past
+	// Schedule end (restore)
+	return s.scheduleAction(ctx, transaction, event.ActionEnd, *transaction.EndAt)
+}
+
+// handleCancelRequested processes cancel.requested events: it unschedules the transaction actions and
+// restores devices that were already moved to power-saving by the START action.
+func (s *Scheduler) handleCancelRequested(ctx context.Context, e cloudevents.Event) error {
+	log := logger.FromContext(ctx).With(zap.String("eventId", e.ID()), zap.String("eventType", e.Type()))
+
+	var data event.CancelRequestedData
+	if err := json.Unmarshal(e.Data(), &data); err != nil {
+		log.Error("Failed to unmarshal cancel.requested data", zap.Error(err))
+		return fmt.Errorf("unmarshal data: %w", err)
 	}
 
-	log.Debug("Arming END timer after START completion",
-		zap.String("transactionId", data.TransactionID),
-		zap.Duration("endDelay", endDelay),
-		zap.Time("endAt", *transaction.EndAt))
+	log.Info("Received cancel request", zap.String("transactionId", data.TransactionID))
+	return s.cancelRequested(ctx, data.TransactionID)
+}
+
+// cancelRequested unschedules the pending actions of a transaction cancelled by the API and restores its devices.
+// The cancel request is completed last, so that the cleanup run handles it again after a failure.
+func (s *Scheduler) cancelRequested(ctx context.Context, transactionID string) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transactionID))
 
-	// Arm timer for end (restore)
-	endTimer := time.AfterFunc(endDelay, func() {
-		s.enqueueScheduleAction(data.TransactionID, event.ActionEnd, transaction.SubscriptionRequest)
-	})
+	if err := s.db.DeleteScheduledActions(ctx, transactionID); err != nil {
+		log.Error("Failed to unschedule actions", zap.Error(err))
+		return fmt.Errorf("delete scheduled actions: %w", err)
+	}
+
+	// The API has already moved the transaction to cancelled and flagged the devices to restore
+	transaction, err := s.db.GetTransaction(ctx, transactionID)
+	if err != nil {
+		log.Error("Failed to get transaction", zap.Error(err))
+		return fmt.Errorf("get transaction: %w", err)
+	}
+
+	if err := s.restoreCancelled(ctx, transaction); err != nil {
+		return err
+	}
 
-	// Track end timer for cleanup
-	endTimerKey := data.TransactionID + "-end"
-	s.mu.Lock()
-	s.timers[endTimerKey] = endTimer
-	s.mu.Unlock()
+	// Cancelling a recurring transaction also cancels its running occurrence
+	if transaction.Recurrence != nil {
+		if err := s.cancelActiveOccurrence(ctx, transaction); err != nil {
+			return err
+		}
+	}
 
+	if err := s.db.CompleteCancelRequest(ctx, transactionID); err != nil {
+		log.Error("Failed to complete cancel request", zap.Error(err))
+		return fmt.Errorf("complete cancel request: %w", err)
+	}
 	return nil
 }
 
-// loadPendingSchedules loads all pending transactions from database and arms their timers
-func (s *Scheduler) loadPendingSchedules(ctx context.Context) error {
-	log := logger.Get()
+// restoreCancelled publishes the restore requests for the devices of a stopped transaction that were
+// already moved to power-saving, or the final completion event when there is nothing to restore.
+func (s *Scheduler) restoreCancelled(ctx context.Context, transaction *database.Transaction) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transaction.TransactionID))
+
+	restoreCount := 0
+	for i, txDevice := range transaction.Devices {
+		if txDevice.CancelAction == nil {
+			continue
+		}
+		restoreCount++
+		if txDevice.CancelAction.Status == "awaiting-start" {
+			// The worker running the START restores the device once it completes
+			continue
+		}
+
+		actuationData := event.DeviceActuationRequestData{
+			Device:              txDevice.Device,
+			Enabled:             false,
+			TransactionID:       transaction.TransactionID,
+			Action:              event.ActionCancel,
+			SubscriptionRequest: transaction.SubscriptionRequest,
+		}
+
+		eventID := fmt.Sprintf("%s-%s-device-%d", transaction.TransactionID, event.ActionCancel, i)
+		if err := s.sender.Send(ctx, eventID, event.EventTypeDeviceActuationRequest, event.SourceiotScheduler, actuationData); err != nil {
+			log.Error("Failed to publish restore request for device",
+				zap.Error(err),
+				zap.Int("deviceIndex", i))
+		}
+	}
+
+	if restoreCount > 0 {
+		log.Info("Restore requests published for cancelled transaction", zap.Int("deviceCount", restoreCount))
+		// The worker sends the final all-devices.completed event once every device is restored
+		return nil
+	}
+
+	// Nothing to restore: emit the final completion event so the consumer is notified right away
+	completedData := event.AllDevicesCompletedData{
+		TransactionID:       transaction.TransactionID,
+		Action:              event.ActionCancel,
+		CompletedAt:         time.Now(),
+		SubscriptionRequest: transaction.SubscriptionRequest,
+	}
+	eventID := fmt.Sprintf("%s-%s-all-completed", transaction.TransactionID, event.ActionCancel)
+	if err := s.sender.Send(ctx, eventID, event.EventTypeAllDevicesCompleted, event.SourceiotScheduler, completedData); err != nil {
+		log.Error("Failed to send all-devices.completed event for cancelled transaction", zap.Error(err))
+		return fmt.Errorf("send all-devices.completed event: %w", err)
+	}
+
+	log.Info("Transaction cancelled, no device to restore")
+
+	// The completion event is not routed back to the scheduler, move a recurring transaction on here
+	return s.continueRecurrence(ctx, transaction)
+}
+
+// onElected runs the singleton tasks due when this replica becomes the leader.
+func (s *Scheduler) onElected(ctx context.Context, token int64) {
+	if err := s.loadPendingSchedules(ctx, token); err != nil {
+		logger.FromContext(ctx).Error("Failed to load pending schedules", zap.Error(err))
+		// Continue anyway - new schedules will still work
+	}
+}
+
+// scheduleAction stores an action of a transaction to be fired at dueAt by any replica.
+// An action in the past is fired right away.
+func (s *Scheduler) scheduleAction(ctx context.Context, transaction *database.Transaction, action string, dueAt time.Time) error {
+	return s.storeAction(ctx, database.ScheduledActionID(transaction.TransactionID, action), transaction, action, dueAt)
+}
+
+// storeAction stores a scheduled action with the given ID, for actions fired more than once per transaction.
+func (s *Scheduler) storeAction(ctx context.Context, id string, transaction *database.Transaction, action string, dueAt time.Time) error {
+	log := logger.FromContext(ctx).With(
+		zap.String("transactionId", transaction.TransactionID),
+		zap.String("action", action),
+		zap.String("scheduledActionId", id))
+
+	err := s.db.ScheduleAction(ctx, &database.ScheduledAction{
+		ID:                  id,
+		TransactionID:       transaction.TransactionID,
+		Action:              action,
+		DueAt:               dueAt,
+		SubscriptionRequest: transaction.SubscriptionRequest,
+		XCorrelator:         transaction.XCorrelator,
+	})
+	if err != nil {
+		log.Error("Failed to schedule action", zap.Error(err))
+		return fmt.Errorf("schedule %s action: %w", action, err)
+	}
+
+	log.Debug("Action scheduled", zap.Time("dueAt", dueAt))
+	return nil
+}
+
+// loadPendingSchedules schedules the actions of all pending transactions, in case the scheduler stopped
+// between storing a transaction and scheduling its actions. Actions already scheduled, fired or not,
+// are left unchanged. It runs on the replica elected as leader with the given lease token.
+func (s *Scheduler) loadPendingSchedules(ctx context.Context, token int64) error {
+	log := logger.FromContext(ctx).With(zap.Int64("leaderToken", token))
+
+	if err := s.db.CheckLease(ctx, leaderLeaseName, token); err != nil {
+		return fmt.Errorf("check leader lease: %w", err)
+	}
+
 	log.Info("Loading pending schedules from database")
 
 	transactions, err := s.db.GetPendingTransactions(ctx)
@@ -377,47 +715,28 @@ func (s *Scheduler) loadPendingSchedules(ctx context.Context) error {
 	log.Info("Restoring pending schedules", zap.Int("count", len(transactions)))
 
 	for _, tx := range transactions {
-		// Check if START action needs to be scheduled
-		if !tx.StartActionCompleted {
-			startDelay := time.Until(tx.StartAt)
-			if startDelay < 0 {
-				startDelay = 0 // Execute immediately if in the past
+		// Recurring transactions are actuated through their occurrences, which are handled as any other transaction
+		if tx.Recurrence != nil {
+			if err := s.resumeRecurrence(correlator.NewContext(ctx, tx.XCorrelator), tx); err != nil {
+				log.Error("Failed to resume recurring transaction",
+					zap.String("transactionId", tx.TransactionID),
+					zap.Error(err))
 			}
-
-			log.Debug("Arming START timer for pending transaction",
-				zap.String("transactionId", tx.TransactionID),
-				zap.Duration("delay", startDelay))
-
-			startTimer := time.AfterFunc(startDelay, func() {
-				s.enqueueScheduleAction(tx.TransactionID, event.ActionStart, tx.SubscriptionRequest)
-			})
-
-			startTimerKey := tx.TransactionID + "-start"
-			s.mu.Lock()
-			s.timers[startTimerKey] = startTimer
-			s.mu.Unlock()
+			continue
 		}
 
-		// Check if END action needs to be scheduled
-		// END is only scheduled if START is already completed and END time is defined
-		if tx.EndAt != nil && tx.StartActionCompleted && !tx.EndActionCompleted {
-			endDelay := time.Until(*tx.EndAt)
-			if endDelay < 0 {
-				endDelay = 0 // Execute immediately if in the past
+		switch tx.Status {
+		case database.StatusScheduled:
+			if err := s.scheduleAction(ctx, tx, event.ActionStart, tx.StartAt); err != nil {
+				return err
+			}
+		case database.StatusActive:
+			// END is only scheduled once START has completed
+			if tx.EndAt != nil {
+				if err := s.scheduleAction(ctx, tx, event.ActionEnd, *tx.EndAt); err != nil {
+					return err
+				}
 			}
-
-			log.Debug("Arming END timer for pending transaction",
-				zap.String("transactionId", tx.TransactionID),
-				zap.Duration("delay", endDelay))
-
-			endTimer := time.AfterFunc(endDelay, func() {
-				s.enqueueScheduleAction(tx.TransactionID, event.ActionEnd, tx.SubscriptionRequest)
-			})
-
-			endTimerKey := tx.TransactionID + "-end"
-			s.mu.Lock()
-			s.timers[endTimerKey] = endTimer
-			s.mu.Unlock()
 		}
 	}
 
@@ -425,45 +744,10 @@ func (s *Scheduler) loadPendingSchedules(ctx context.Context) error {
 	return nil
 }
 
-// enqueueScheduleAction helper to enqueue a schedule action (start or end) to the fire channel
-func (s *Scheduler) enqueueScheduleAction(transactionID string, action string, subscriptionRequest models.SubscriptionRequest) {
-	log := logger.Get().With(
-		zap.String("transactionId", transactionID),
-		zap.String("action", action))
-
-	// Create schedule action with subscription details
-	schedAction := scheduleAction{
-		TransactionID:       transactionID,
-		Action:              action,
-		SubscriptionRequest: subscriptionRequest,
-	}
-
-	// Enqueue to fire channel (non-blocking callback)
-	select {
-	case s.fireChan <- schedAction:
-		log.Debug("Schedule action enqueued for firing")
-	case <-s.stopCh:
-		log.Debug("Scheduler stopped, skipping fire")
-	default:
-		log.Warn("Fire channel full, schedule may be delayed")
-		// Try again with blocking send
-		select {
-		case s.fireChan <- schedAction:
-		case <-s.stopCh:
-		}
-	}
-
-	// Remove timer from map
-	timerKey := transactionID + "-" + action
-	s.mu.Lock()
-	delete(s.timers, timerKey)
-	s.mu.Unlock()
-}
-
-// worker processes fired schedules from the channel
+// worker fires the claimed actions from the channel
 func (s *Scheduler) worker(ctx context.Context, id int) {
 	defer s.wg.Done()
-	log := logger.Get().With(zap.Int("workerId", id))
+	log := logger.FromContext(ctx).With(zap.Int("workerId", id))
 	log.Debug("Scheduler worker started")
 
 	for {
@@ -474,32 +758,60 @@ func (s *Scheduler) worker(ctx context.Context, id int) {
 		case <-s.stopCh:
 			log.Debug("Worker stopping")
 			return
-		case schedAction, ok := <-s.fireChan:
-			if !ok {
-				log.Debug("Worker stopping, channel closed")
-				return
-			}
-
-			if err := s.fireSchedule(ctx, schedAction); err != nil {
-				log.Error("Failed to fire schedule", zap.Error(err), zap.String("scheduleId", schedAction.TransactionID))
-			}
+		case claim := <-s.fireChan:
+			s.fire(ctx, claim)
 		}
 	}
 }
 
+// fire fires a claimed action, and marks it as fired unless its fan-out was interrupted. Losing the lease
+// of the action interrupts its fan-out, as another replica may fire it again.
+func (s *Scheduler) fire(ctx context.Context, claim *actionClaim) {
+	defer claim.release()
+	schedAction := claim.action
+	ctx = correlator.NewContext(ctx, schedAction.XCorrelator)
+	log := logger.FromContext(ctx).With(zap.String("scheduleId", schedAction.TransactionID))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(claim.lost, cancel)
+	defer stop()
+
+	err := s.fireSchedule(ctx, schedAction)
+	if errors.Is(err, errFanOutInterrupted) {
+		log.Warn("Fan-out interrupted, action left to be fired again", zap.Error(err))
+		return
+	}
+	if err != nil {
+		log.Error("Failed to fire schedule", zap.Error(err))
+	}
+
+	// A failed action has been reported to the consumer, it is not fired again
+	if err := s.db.MarkActionFired(ctx, schedAction.ID, schedAction.LeaseOwner); err != nil {
+		log.Error("Failed to mark action as fired", zap.Error(err))
+	}
+}
+
 // fireSchedule atomically claims and publishes individual device.actuation.request events
-func (s *Scheduler) fireSchedule(ctx context.Context, schedAction scheduleAction) error {
-	log := logger.Get().With(
+func (s *Scheduler) fireSchedule(ctx context.Context, schedAction *database.ScheduledAction) error {
+	// Restore the correlator of the originating request for logs and published events
+	ctx = correlator.NewContext(ctx, schedAction.XCorrelator)
+	log := logger.FromContext(ctx).With(
 		zap.String("transactionId", schedAction.TransactionID),
 		zap.String("action", schedAction.Action))
 
+	// The lease may have expired while the action was handed over: another replica may be firing it
+	if err := s.db.ExtendActionLease(ctx, schedAction.ID, schedAction.LeaseOwner, s.leaseDuration); err != nil {
+		return fmt.Errorf("%w before publishing: extend lease: %w", errFanOutInterrupted, err)
+	}
+
 	// Atomically claim transaction for this specific action
 	claimed, err := s.db.ClaimTransaction(ctx, schedAction.TransactionID, schedAction.Action)
 	if err != nil {
 		log.Error("Failed to claim transaction", zap.Error(err))
 
 		// Send error notification to consumer using cached SubscriptionRequest
-		s.sendErrorNotification(ctx, schedAction.TransactionID, schedAction.Action, "INTERNAL_ERROR", "Failed to claim transaction in database", schedAction.SubscriptionRequest)
+		s.sendErrorNotification(ctx, schedAction.TransactionID, schedAction.Action, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to claim transaction in database", schedAction.SubscriptionRequest)
 
 		return fmt.Errorf("claim transaction: %w", err)
 	}
@@ -518,7 +830,7 @@ func (s *Scheduler) fireSchedule(ctx context.Context, schedAction scheduleAction
 		_ = s.db.MarkTransactionFailed(ctx, schedAction.TransactionID, "failed to retrieve transaction data")
 
 		// Send error notification to consumer using cached SubscriptionRequest
-		s.sendErrorNotification(ctx, schedAction.TransactionID, schedAction.Action, "INTERNAL_ERROR", "Failed to retrieve transaction data from database", schedAction.SubscriptionRequest)
+		s.sendErrorNotification(ctx, schedAction.TransactionID, schedAction.Action, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve transaction data from database", schedAction.SubscriptionRequest)
 
 		return fmt.Errorf("get transaction: %w", err)
 	}
@@ -529,17 +841,37 @@ func (s *Scheduler) fireSchedule(ctx context.Context, schedAction scheduleAction
 		enabledValue = !transaction.Enabled // Invert for end action
 	}
 
-	// Publish individual device.actuation.request event for each device
+	// Publish individual device.actuation.request event for each device, paced by the fan-out settings
+	indexes := actuatedDevices(transaction, schedAction.Action)
+	pacer := s.fanOut.pacer(transaction.FanOut)
+	order, offsets := pacer.schedule(len(indexes))
 	log.Debug("Publishing device actuation requests",
-		zap.Int("deviceCount", len(transaction.Devices)),
-		zap.Bool("enabled", enabledValue))
-	for i, txDevice := range transaction.Devices {
+		zap.Int("deviceCount", len(indexes)),
+		zap.String("canaryStatus", string(transaction.CanaryStatus)),
+		zap.Bool("enabled", enabledValue),
+		zap.Duration("jitterWindow", pacer.jitter))
+
+	// The lease of the action is renewed while pacing, however long the jitter window: losing it cancels ctx
+	published, failed := 0, 0
+	backends := make(map[string]int64)
+	for _, n := range order {
+		// Event IDs use the position of the device in the transaction
+		i := indexes[n]
+		txDevice := transaction.Devices[i]
+		backend := backendOf(txDevice.Device)
+		if err := pacer.wait(ctx, offsets[n], backend); err != nil {
+			pacer.report(published, failed, backends)
+			return fmt.Errorf("%w after %d devices: %w", errFanOutInterrupted, published+failed, err)
+		}
+
 		actuationData := event.DeviceActuationRequestData{
 			Device:              txDevice.Device,
 			Enabled:             enabledValue,
 			TransactionID:       transaction.TransactionID,
 			Action:              schedAction.Action,
 			SubscriptionRequest: schedAction.SubscriptionRequest,
+			Profile:             transaction.Profile,
+			ProfileSettings:     transaction.ProfileSettings,
 		}
 
 		// Use unique event ID for each device
@@ -556,16 +888,28 @@ func (s *Scheduler) fireSchedule(ctx context.Context, schedAction scheduleAction
 				zap.Error(err),
 				zap.Int("deviceIndex", i))
 			// Continue with other devices even if one fails
+			failed++
+			continue
+		}
+		published++
+		if backend != "" {
+			backends[backend]++
 		}
 	}
 
-	log.Debug("Device actuation requests published successfully")
+	achievedRate := pacer.report(published, failed, backends)
+	log.Info("Device actuation requests published",
+		zap.Int("published", published),
+		zap.Int("failed", failed),
+		zap.Duration("duration", time.Since(pacer.startedAt)),
+		zap.Float64("achievedRate", achievedRate))
 	return nil
 }
 
-// sendErrorNotification sends a CloudEventError to the consumer's notification sink
-func (s *Scheduler) sendErrorNotification(ctx context.Context, transactionID string, action string, errorCode string, errorMessage string, subscriptionRequest models.SubscriptionRequest) {
-	log := logger.Get().With(
+// sendErrorNotification sends a CloudEventError to the consumer's notification sink, with the HTTP status
+// of the error: 500 for an internal error, a 4xx status for devices rejecting an action
+func (s *Scheduler) sendErrorNotification(ctx context.Context, transactionID string, action string, status int, errorCode string, errorMessage string, subscriptionRequest models.SubscriptionRequest) {
+	log := logger.FromContext(ctx).With(
 		zap.String("transactionId", transactionID),
 		zap.String("errorCode", errorCode))
 
@@ -578,7 +922,7 @@ func (s *Scheduler) sendErrorNotification(ctx context.Context, transactionID str
 	// Prepare error notification data
 	errorData := event.ErrorNotificationData{
 		TransactionID:       transactionID,
-		Status:              500,
+		Status:              status,
 		Code:                errorCode,
 		Message:             errorMessage,
 		Action:              action,
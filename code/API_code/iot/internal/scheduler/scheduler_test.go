@@ -0,0 +1,432 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+)
+
+// actionsDatabase stores scheduled actions in memory with the semantics of the MongoDB implementation,
+// and holds the transaction they belong to.
+type actionsDatabase struct {
+	database.Interface
+	mu          sync.Mutex
+	actions     map[string]*database.ScheduledAction
+	transaction *database.Transaction
+}
+
+func newActionsDatabase(transaction *database.Transaction) *actionsDatabase {
+	return &actionsDatabase{actions: map[string]*database.ScheduledAction{}, transaction: transaction}
+}
+
+func (d *actionsDatabase) ScheduleAction(ctx context.Context, action *database.ScheduledAction) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// An action already scheduled, fired or not, is left unchanged
+	if _, ok := d.actions[action.ID]; !ok {
+		stored := *action
+		stored.CreatedAt = time.Now()
+		d.actions[action.ID] = &stored
+	}
+	return nil
+}
+
+func (d *actionsDatabase) ClaimDueAction(ctx context.Context, owner string, leaseDuration time.Duration) (*database.ScheduledAction, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	var due *database.ScheduledAction
+	for _, action := range d.actions {
+		if action.FiredAt != nil || action.DueAt.After(now) {
+			continue
+		}
+		if action.LeaseExpiresAt != nil && action.LeaseExpiresAt.After(now) {
+			continue
+		}
+		if due == nil || action.DueAt.Before(due.DueAt) {
+			due = action
+		}
+	}
+	if due == nil {
+		return nil, nil
+	}
+
+	expiresAt := now.Add(leaseDuration)
+	due.LeaseOwner = owner
+	due.LeaseExpiresAt = &expiresAt
+	claimed := *due
+	return &claimed, nil
+}
+
+func (d *actionsDatabase) ExtendActionLease(ctx context.Context, id string, owner string, leaseDuration time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	action, ok := d.actions[id]
+	if !ok || action.LeaseOwner != owner || action.FiredAt != nil {
+		return database.ErrLeaseLost
+	}
+	expiresAt := time.Now().Add(leaseDuration)
+	action.LeaseExpiresAt = &expiresAt
+	return nil
+}
+
+func (d *actionsDatabase) MarkActionFired(ctx context.Context, id string, owner string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if action, ok := d.actions[id]; ok && action.LeaseOwner == owner {
+		firedAt := time.Now()
+		action.FiredAt = &firedAt
+	}
+	return nil
+}
+
+func (d *actionsDatabase) GetPendingTransactions(ctx context.Context) ([]*database.Transaction, error) {
+	return []*database.Transaction{d.transaction}, nil
+}
+
+func (d *actionsDatabase) CheckLease(ctx context.Context, name string, token int64) error {
+	return nil
+}
+
+// ClaimTransaction always succeeds, so that only the action leases keep an action from firing twice.
+func (d *actionsDatabase) ClaimTransaction(ctx context.Context, transactionID string, action string) (bool, error) {
+	return true, nil
+}
+
+func (d *actionsDatabase) GetTransaction(ctx context.Context, transactionID string) (*database.Transaction, error) {
+	return d.transaction, nil
+}
+
+// action returns a copy of a scheduled action.
+func (d *actionsDatabase) action(id string) database.ScheduledAction {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return *d.actions[id]
+}
+
+// expireLeases makes the leases of all actions expire, as when their holder stopped long ago.
+func (d *actionsDatabase) expireLeases() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expired := time.Now().Add(-time.Second)
+	for _, action := range d.actions {
+		if action.LeaseExpiresAt != nil {
+			action.LeaseExpiresAt = &expired
+		}
+	}
+}
+
+// recordingSender records the IDs and data of the events sent.
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []string
+	data []any
+}
+
+func (s *recordingSender) Send(ctx context.Context, requestID string, eventType event.EventType, source event.Source, data any, opts ...event.Option) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, requestID)
+	s.data = append(s.data, data)
+	return nil
+}
+
+func (s *recordingSender) events() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sent...)
+}
+
+func newTestTransaction() *database.Transaction {
+	return &database.Transaction{
+		TransactionID: "tx-1",
+		StartAt:       time.Now().Add(-time.Minute),
+		Enabled:       true,
+		Status:        database.StatusScheduled,
+		Devices: []*database.TransactionDevice{
+			{DeviceID: "dev-1", Device: models.Device{}},
+			{DeviceID: "dev-2", Device: models.Device{}},
+		},
+	}
+}
+
+func newTestScheduler(db database.Interface, sender event.Sender, replicaID string) *Scheduler {
+	return New(db, sender, nil, &Config{WorkerCount: 1, ReplicaID: replicaID})
+}
+
+// startWorker runs a worker of the scheduler until ctx is done or the returned function is called.
+func startWorker(ctx context.Context, s *Scheduler) (stop func()) {
+	s.wg.Add(1)
+	go s.worker(ctx, 0)
+	return func() {
+		close(s.stopCh)
+		s.wg.Wait()
+	}
+}
+
+// waitFired waits until the scheduled action has been marked as fired.
+func waitFired(t *testing.T, db *actionsDatabase, id string) {
+	t.Helper()
+	require.Eventually(t, func() bool { return db.action(id).FiredAt != nil }, time.Second, time.Millisecond)
+}
+
+func TestDueActionFiredOnceByCompetingReplicas(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	sender := &recordingSender{}
+	replicas := []*Scheduler{
+		newTestScheduler(db, sender, "replica-a"),
+		newTestScheduler(db, sender, "replica-b"),
+	}
+	require.NoError(t, replicas[0].scheduleAction(context.Background(), transaction, event.ActionStart, transaction.StartAt))
+
+	var pollers sync.WaitGroup
+	for _, s := range replicas {
+		stop := startWorker(context.Background(), s)
+		defer stop()
+
+		pollers.Add(1)
+		go func(s *Scheduler) {
+			defer pollers.Done()
+			for i := 0; i < 20; i++ {
+				s.claimDueActions()
+			}
+		}(s)
+	}
+	pollers.Wait()
+	waitFired(t, db, database.ScheduledActionID("tx-1", event.ActionStart))
+
+	assert.ElementsMatch(t, []string{"tx-1-start-device-0", "tx-1-start-device-1"}, sender.events())
+}
+
+func TestExpiredClaimTakenOver(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	sender := &recordingSender{}
+	s := newTestScheduler(db, sender, "replica-b")
+	id := database.ScheduledActionID("tx-1", event.ActionStart)
+	require.NoError(t, s.scheduleAction(context.Background(), transaction, event.ActionStart, transaction.StartAt))
+
+	// Another replica claims the action and stops before firing it
+	claimed, err := db.ClaimDueAction(context.Background(), "replica-a", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+
+	s.claimDueActions()
+	assert.Empty(t, s.fireChan, "an action leased by another replica must not be claimed")
+
+	db.expireLeases()
+	s.claimDueActions()
+	require.Len(t, s.fireChan, 1)
+
+	stop := startWorker(context.Background(), s)
+	waitFired(t, db, id)
+	stop()
+
+	// The replica that stopped cannot mark the action once its lease is taken over
+	require.NoError(t, db.MarkActionFired(context.Background(), id, "replica-a"))
+	assert.True(t, strings.HasPrefix(db.action(id).LeaseOwner, "replica-b/"))
+	assert.ElementsMatch(t, []string{"tx-1-start-device-0", "tx-1-start-device-1"}, sender.events())
+}
+
+func TestScheduleActionIdempotent(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	sender := &recordingSender{}
+	s := newTestScheduler(db, sender, "replica-a")
+	ctx := context.Background()
+
+	require.NoError(t, s.loadPendingSchedules(ctx, 1))
+	require.NoError(t, s.scheduleAction(ctx, transaction, event.ActionStart, transaction.StartAt.Add(time.Hour)))
+	require.Len(t, db.actions, 1)
+	assert.Equal(t, transaction.StartAt, db.action(database.ScheduledActionID("tx-1", event.ActionStart)).DueAt)
+
+	s.claimDueActions()
+	stop := startWorker(context.Background(), s)
+	waitFired(t, db, database.ScheduledActionID("tx-1", event.ActionStart))
+	stop()
+
+	// Scheduling the action again, e.g. on a restart before START completed, does not fire it again
+	require.NoError(t, s.loadPendingSchedules(ctx, 1))
+	s.claimDueActions()
+	assert.Empty(t, s.fireChan)
+	assert.Len(t, db.actions, 1)
+	assert.ElementsMatch(t, []string{"tx-1-start-device-0", "tx-1-start-device-1"}, sender.events())
+}
+
+func TestInterruptedFanOutFiredAgain(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	sender := &recordingSender{}
+	id := database.ScheduledActionID("tx-1", event.ActionStart)
+
+	// One request per second: the second device waits for the rate when the replica stops
+	paced := New(db, sender, nil, &Config{WorkerCount: 1, ReplicaID: "replica-a", FanOutRate: 1})
+	require.NoError(t, paced.scheduleAction(context.Background(), transaction, event.ActionStart, transaction.StartAt))
+	paced.claimDueActions()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := startWorker(ctx, paced)
+	require.Eventually(t, func() bool { return len(sender.events()) == 1 }, time.Second, time.Millisecond)
+	cancel()
+	stop()
+
+	assert.Nil(t, db.action(id).FiredAt, "an interrupted action must not be marked as fired")
+
+	// Another replica fires the action again once the lease expires
+	db.expireLeases()
+	s := newTestScheduler(db, sender, "replica-b")
+	s.claimDueActions()
+	require.Len(t, s.fireChan, 1)
+
+	stop = startWorker(context.Background(), s)
+	waitFired(t, db, id)
+	stop()
+
+	assert.Equal(t, []string{"tx-1-start-device-0", "tx-1-start-device-0", "tx-1-start-device-1"}, sender.events())
+}
+
+func TestClaimsOnlyForIdleWorkers(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	s := newTestScheduler(db, &recordingSender{}, "replica-a")
+	ctx := context.Background()
+	require.NoError(t, s.scheduleAction(ctx, transaction, event.ActionStart, transaction.StartAt))
+	require.NoError(t, s.scheduleAction(ctx, transaction, event.ActionEnd, transaction.StartAt))
+
+	// A single worker: the second due action is left to other replicas
+	s.claimDueActions()
+	s.claimDueActions()
+	require.Len(t, s.fireChan, 1)
+	claimed := 0
+	for _, id := range []string{database.ScheduledActionID("tx-1", event.ActionStart), database.ScheduledActionID("tx-1", event.ActionEnd)} {
+		if db.action(id).LeaseOwner != "" {
+			claimed++
+		}
+	}
+	assert.Equal(t, 1, claimed)
+	close(s.stopCh)
+}
+
+func TestClaimLeaseRenewedUntilFired(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	sender := &recordingSender{}
+	s := New(db, sender, nil, &Config{WorkerCount: 1, ReplicaID: "replica-a", LeaseDuration: 30 * time.Millisecond})
+	id := database.ScheduledActionID("tx-1", event.ActionStart)
+	require.NoError(t, s.scheduleAction(context.Background(), transaction, event.ActionStart, transaction.StartAt))
+
+	// The claim outlives several lease durations before a worker takes it
+	s.claimDueActions()
+	require.Len(t, s.fireChan, 1)
+	time.Sleep(100 * time.Millisecond)
+	other, err := db.ClaimDueAction(context.Background(), "replica-b/1", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, other, "a claimed action must keep its lease until it is fired")
+
+	stop := startWorker(context.Background(), s)
+	waitFired(t, db, id)
+	stop()
+	assert.ElementsMatch(t, []string{"tx-1-start-device-0", "tx-1-start-device-1"}, sender.events())
+}
+
+func TestLostLeaseInterruptsFanOut(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	sender := &recordingSender{}
+	id := database.ScheduledActionID("tx-1", event.ActionStart)
+
+	// One request per second: the second device is still paced when the lease is taken over
+	s := New(db, sender, nil, &Config{WorkerCount: 1, ReplicaID: "replica-a", LeaseDuration: 30 * time.Millisecond, FanOutRate: 1})
+	require.NoError(t, s.scheduleAction(context.Background(), transaction, event.ActionStart, transaction.StartAt))
+	s.claimDueActions()
+	stop := startWorker(context.Background(), s)
+	require.Eventually(t, func() bool { return len(sender.events()) == 1 }, time.Second, time.Millisecond)
+
+	db.mu.Lock()
+	db.actions[id].LeaseOwner = "replica-b/1"
+	db.mu.Unlock()
+
+	// The worker gives the action up without marking it as fired
+	require.Eventually(t, func() bool { return len(s.slots) == 0 }, time.Second, time.Millisecond)
+	stop()
+	assert.Nil(t, db.action(id).FiredAt)
+	assert.Equal(t, []string{"tx-1-start-device-0"}, sender.events())
+}
+
+func TestLeaseKeptWhilePacing(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	sender := &recordingSender{}
+	id := database.ScheduledActionID("tx-1", event.ActionStart)
+
+	// The second device waits several lease durations for the rate
+	s := New(db, sender, nil, &Config{WorkerCount: 1, ReplicaID: "replica-a", LeaseDuration: 30 * time.Millisecond, FanOutRate: 5})
+	other := New(db, sender, nil, &Config{WorkerCount: 1, ReplicaID: "replica-b", LeaseDuration: 30 * time.Millisecond})
+	require.NoError(t, s.scheduleAction(context.Background(), transaction, event.ActionStart, transaction.StartAt))
+	s.claimDueActions()
+	stop := startWorker(context.Background(), s)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for db.action(id).FiredAt == nil {
+		require.True(t, time.Now().Before(deadline), "action not fired")
+		other.claimDueActions()
+		require.Empty(t, other.fireChan, "an action being paced must not be claimed by another replica")
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+	close(other.stopCh)
+	assert.ElementsMatch(t, []string{"tx-1-start-device-0", "tx-1-start-device-1"}, sender.events())
+}
+
+func TestEndRequestsRestoreOfActivation(t *testing.T) {
+	transaction := newTestTransaction()
+	db := newActionsDatabase(transaction)
+	sender := &recordingSender{}
+	s := newTestScheduler(db, sender, "replica-a")
+	require.NoError(t, s.scheduleAction(context.Background(), transaction, event.ActionEnd, transaction.StartAt))
+	action, err := db.ClaimDueAction(context.Background(), "replica-a", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, s.fireSchedule(context.Background(), action))
+
+	// END of a power-saving activation is requested with enabled=false, which the worker handles by restoring
+	// the original configuration of the devices
+	require.Len(t, sender.data, 2)
+	for _, data := range sender.data {
+		request, ok := data.(event.DeviceActuationRequestData)
+		require.True(t, ok)
+		assert.Equal(t, event.ActionEnd, request.Action)
+		assert.False(t, request.Enabled)
+	}
+}
@@ -0,0 +1,307 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/correlator"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// The watchdog recovers device actions that got no result, e.g. when a worker stopped while actuating a
+// device or an actuation request was lost. A device action is stuck when it is still pending or in progress
+// after the action timeout, counted from the last publication of its request. Its request is published
+// again, and once the attempts are exhausted the action is marked as failed, so that the transaction
+// completes and the consumer is notified.
+
+// stuckAction is a device action to recover.
+type stuckAction struct {
+	index  int
+	action string
+	status *database.DeviceActionStatus // nil for an END not started
+}
+
+// watchdog periodically recovers stuck device actions, when this replica is the leader.
+func (s *Scheduler) watchdog(ctx context.Context) {
+	defer s.wg.Done()
+	log := logger.FromContext(ctx)
+	log.Info("Starting watchdog",
+		zap.Duration("interval", s.watchdogInterval),
+		zap.Duration("actionTimeout", s.actionTimeout),
+		zap.Int("actionMaxAttempts", s.actionMaxAttempts))
+
+	ticker := time.NewTicker(s.watchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			log.Debug("Watchdog stopping")
+			return
+		case <-ticker.C:
+			s.runWatchdog()
+		}
+	}
+}
+
+// runWatchdog looks for stuck device actions in the transactions being actuated.
+func (s *Scheduler) runWatchdog() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.watchdogInterval)
+	defer cancel()
+	log := logger.FromContext(ctx)
+
+	token, ok := s.leader.Token()
+	if !ok {
+		log.Debug("Not the leader, skipping watchdog")
+		return
+	}
+
+	if err := s.db.CheckLease(ctx, leaderLeaseName, token); err != nil {
+		log.Warn("Leader lease lost, skipping watchdog", zap.Error(err))
+		return
+	}
+
+	transactions, err := s.db.GetInFlightTransactions(ctx)
+	if err != nil {
+		log.Error("Failed to get in-flight transactions", zap.Error(err))
+		return
+	}
+
+	for _, transaction := range transactions {
+		ctx := correlator.NewContext(ctx, transaction.XCorrelator)
+		if err := s.recoverTransaction(ctx, transaction); err != nil {
+			logger.FromContext(ctx).Error("Failed to recover stuck device actions",
+				zap.String("transactionId", transaction.TransactionID),
+				zap.Error(err))
+		}
+	}
+
+	s.recoverCancellations(ctx)
+}
+
+// recoverCancellations handles the cancellations whose cancel.requested event was not handled within the action
+// timeout, e.g. when the API failed to publish it after cancelling the transaction.
+func (s *Scheduler) recoverCancellations(ctx context.Context) {
+	log := logger.FromContext(ctx)
+
+	transactions, err := s.db.GetPendingCancellations(ctx, time.Now().Add(-s.actionTimeout))
+	if err != nil {
+		log.Error("Failed to get pending cancellations", zap.Error(err))
+		return
+	}
+
+	for _, transaction := range transactions {
+		ctx := correlator.NewContext(ctx, transaction.XCorrelator)
+		log := logger.FromContext(ctx)
+		log.Warn("Cancel request not handled in time, handling it again", zap.String("transactionId", transaction.TransactionID))
+		if err := s.cancelRequested(ctx, transaction.TransactionID); err != nil {
+			log.Error("Failed to recover cancellation",
+				zap.String("transactionId", transaction.TransactionID),
+				zap.Error(err))
+		}
+	}
+}
+
+// recoverTransaction publishes again, or fails, the stuck device actions of a transaction.
+func (s *Scheduler) recoverTransaction(ctx context.Context, transaction *database.Transaction) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transaction.TransactionID))
+
+	actions, err := s.db.GetScheduledActions(ctx, transaction.TransactionID)
+	if err != nil {
+		return fmt.Errorf("get scheduled actions: %w", err)
+	}
+	firedAt := make(map[string]time.Time, len(actions))
+	for _, action := range actions {
+		if action.FiredAt != nil {
+			firedAt[action.ID] = *action.FiredAt
+		}
+	}
+
+	stuck := stuckActions(transaction, firedAt, time.Now().Add(-s.actionTimeout))
+	if len(stuck) == 0 {
+		return nil
+	}
+	log.Warn("Stuck device actions found", zap.Int("count", len(stuck)))
+
+	for _, sa := range stuck {
+		txDevice := transaction.Devices[sa.index]
+		attempts := 1
+		if sa.status != nil && sa.status.Attempts > 0 {
+			attempts = sa.status.Attempts
+		}
+		deviceLog := log.With(
+			zap.String("deviceId", txDevice.DeviceID),
+			zap.String("action", sa.action),
+			zap.Int("attempts", attempts))
+
+		if attempts >= s.actionMaxAttempts {
+			deviceLog.Warn("Device action without result after the last attempt, marking it as failed")
+			if err := s.expireDeviceAction(ctx, transaction, sa, attempts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		requeued, err := s.db.RequeueDeviceAction(ctx, transaction.TransactionID, txDevice.DeviceID, sa.action, sa.status)
+		if err != nil {
+			return fmt.Errorf("requeue device action: %w", err)
+		}
+		if !requeued {
+			deviceLog.Debug("Device action progressed in the meantime")
+			continue
+		}
+
+		data := actuationRequest(transaction, txDevice, sa.action)
+		data.Attempt = attempts + 1
+		eventID := fmt.Sprintf("%s-%s-device-%d-attempt-%d", transaction.TransactionID, sa.action, sa.index, data.Attempt)
+		if err := s.sender.Send(ctx, eventID, event.EventTypeDeviceActuationRequest, event.SourceiotScheduler, data); err != nil {
+			// The action is stuck again once the timeout elapses
+			deviceLog.Error("Failed to publish actuation request again", zap.Error(err))
+			continue
+		}
+		deviceLog.Info("Actuation request published again", zap.Int("attempt", data.Attempt))
+	}
+	return nil
+}
+
+// expireDeviceAction marks a stuck device action as failed and reports the completion of the action or of
+// the canary devices, like the worker does.
+func (s *Scheduler) expireDeviceAction(ctx context.Context, transaction *database.Transaction, sa stuckAction, attempts int) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transaction.TransactionID), zap.String("action", sa.action))
+	txDevice := transaction.Devices[sa.index]
+
+	status := &database.DeviceActionStatus{
+		Status:       "failed",
+		ErrorCode:    string(models.ErrorCodeActionTimeout),
+		ErrorMessage: fmt.Sprintf("no result after %d attempts", attempts),
+		Attempts:     attempts,
+	}
+	if sa.status != nil && sa.status.ErrorCode != "" {
+		// The worker requeued the action, e.g. while the backend was deemed unavailable: its reason tells more
+		status.ErrorCode = sa.status.ErrorCode
+		status.ErrorMessage = fmt.Sprintf("%s, after %d attempts", sa.status.ErrorMessage, attempts)
+	}
+	progress, expired, err := s.db.ExpireDeviceAction(ctx, transaction.TransactionID, txDevice.DeviceID, sa.action, sa.status, status)
+	if err != nil {
+		return fmt.Errorf("expire device action: %w", err)
+	}
+	if !expired {
+		log.Debug("Device action progressed in the meantime", zap.String("deviceId", txDevice.DeviceID))
+		return nil
+	}
+
+	if progress.CanaryCompleted {
+		log.Info("All canary devices completed")
+		if err := s.canaryCompleted(ctx, transaction.TransactionID); err != nil {
+			return err
+		}
+	}
+
+	if progress.AllCompleted {
+		log.Info("All devices completed, sending notification event")
+		completedData := event.AllDevicesCompletedData{
+			TransactionID:       transaction.TransactionID,
+			Action:              sa.action,
+			CompletedAt:         time.Now(),
+			SubscriptionRequest: transaction.SubscriptionRequest,
+		}
+		eventID := fmt.Sprintf("%s-%s-all-completed", transaction.TransactionID, sa.action)
+		if err := s.sender.Send(ctx, eventID, event.EventTypeAllDevicesCompleted, event.SourceiotScheduler, completedData); err != nil {
+			return fmt.Errorf("send all-devices.completed event: %w", err)
+		}
+		// The event is routed to the notifier only, so the scheduler follows up on it here
+		return s.actionCompleted(ctx, completedData)
+	}
+	return nil
+}
+
+// stuckActions returns the device actions of a transaction without result since before. The timeout
+// counts from the last publication of a request: the last status change of the device action, or the
+// end of the fan-out of the scheduled action, whichever is later.
+func stuckActions(transaction *database.Transaction, firedAt map[string]time.Time, before time.Time) []stuckAction {
+	var stuck []stuckAction
+	add := func(index int, action string, status *database.DeviceActionStatus, publishedAt time.Time, published bool) {
+		if !published {
+			// The fan-out of the action has not completed, it is fired again when interrupted
+			return
+		}
+		if status != nil {
+			if status.Status != "pending" && status.Status != "in-progress" && status.Status != "awaiting-start" {
+				return
+			}
+			if status.Timestamp.After(publishedAt) {
+				publishedAt = status.Timestamp
+			}
+		}
+		if publishedAt.Before(before) {
+			stuck = append(stuck, stuckAction{index: index, action: action, status: status})
+		}
+	}
+
+	for i, txDevice := range transaction.Devices {
+		switch {
+		case txDevice.CancelAction != nil:
+			// Restore requests are published when the transaction is stopped. A restore awaiting a START
+			// whose worker was lost is published after the same timeout
+			stoppedAt := transaction.UpdatedAt
+			if n := len(transaction.StatusHistory); n > 0 {
+				stoppedAt = transaction.StatusHistory[n-1].At
+			}
+			add(i, event.ActionCancel, txDevice.CancelAction, stoppedAt, true)
+		case transaction.Status == database.StatusStarting:
+			// Devices left after a canary are published by the rollout action
+			id := database.ScheduledActionID(transaction.TransactionID, event.ActionStart)
+			if transaction.CanaryStatus != "" && !txDevice.Canary {
+				id = database.ScheduledActionID(transaction.TransactionID, rolloutAction)
+			}
+			at, fired := firedAt[id]
+			add(i, event.ActionStart, txDevice.StartAction, at, fired)
+		case transaction.Status == database.StatusEnding:
+			at, fired := firedAt[database.ScheduledActionID(transaction.TransactionID, event.ActionEnd)]
+			add(i, event.ActionEnd, txDevice.EndAction, at, fired)
+		}
+	}
+	return stuck
+}
+
+// actuationRequest builds the actuation request of a device action, as published when the action fired.
+func actuationRequest(transaction *database.Transaction, txDevice *database.TransactionDevice, action string) event.DeviceActuationRequestData {
+	data := event.DeviceActuationRequestData{
+		Device:              txDevice.Device,
+		TransactionID:       transaction.TransactionID,
+		Action:              action,
+		SubscriptionRequest: transaction.SubscriptionRequest,
+	}
+	switch action {
+	case event.ActionStart:
+		data.Enabled = transaction.Enabled
+		data.Profile = transaction.Profile
+		data.ProfileSettings = transaction.ProfileSettings
+	case event.ActionEnd:
+		data.Enabled = !transaction.Enabled
+		data.Profile = transaction.Profile
+		data.ProfileSettings = transaction.ProfileSettings
+	}
+	return data
+}
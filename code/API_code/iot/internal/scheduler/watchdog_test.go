@@ -0,0 +1,123 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+)
+
+func TestStuckActions(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-5 * time.Minute)
+	old := now.Add(-10 * time.Minute)
+	startID := database.ScheduledActionID("tx", event.ActionStart)
+
+	status := func(s string, at time.Time) *database.DeviceActionStatus {
+		return &database.DeviceActionStatus{Status: s, Timestamp: at}
+	}
+
+	t.Run("start", func(t *testing.T) {
+		transaction := &database.Transaction{
+			TransactionID: "tx",
+			Status:        database.StatusStarting,
+			Devices: []*database.TransactionDevice{
+				{StartAction: status("in-progress", old)},
+				{StartAction: status("in-progress", now)},
+				{StartAction: status("success", old)},
+				{StartAction: status("pending", old)},
+			},
+		}
+
+		stuck := stuckActions(transaction, map[string]time.Time{startID: old}, before)
+		assert.Equal(t, []stuckAction{
+			{index: 0, action: event.ActionStart, status: transaction.Devices[0].StartAction},
+			{index: 3, action: event.ActionStart, status: transaction.Devices[3].StartAction},
+		}, stuck)
+	})
+
+	t.Run("fan-out not completed", func(t *testing.T) {
+		transaction := &database.Transaction{
+			TransactionID: "tx",
+			Status:        database.StatusStarting,
+			Devices:       []*database.TransactionDevice{{StartAction: status("pending", old)}},
+		}
+
+		assert.Empty(t, stuckActions(transaction, map[string]time.Time{}, before))
+		assert.Empty(t, stuckActions(transaction, map[string]time.Time{startID: now}, before))
+	})
+
+	t.Run("canary", func(t *testing.T) {
+		transaction := &database.Transaction{
+			TransactionID: "tx",
+			Status:        database.StatusStarting,
+			CanaryStatus:  database.CanaryRunning,
+			Devices: []*database.TransactionDevice{
+				{Canary: true, StartAction: status("in-progress", old)},
+				{StartAction: status("pending", old)},
+			},
+		}
+
+		stuck := stuckActions(transaction, map[string]time.Time{startID: old}, before)
+		assert.Len(t, stuck, 1)
+		assert.Equal(t, 0, stuck[0].index)
+	})
+
+	t.Run("end not started", func(t *testing.T) {
+		transaction := &database.Transaction{
+			TransactionID: "tx",
+			Status:        database.StatusEnding,
+			Devices:       []*database.TransactionDevice{{StartAction: status("success", old)}},
+		}
+
+		endID := database.ScheduledActionID("tx", event.ActionEnd)
+		stuck := stuckActions(transaction, map[string]time.Time{endID: old}, before)
+		assert.Equal(t, []stuckAction{{index: 0, action: event.ActionEnd}}, stuck)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		transaction := &database.Transaction{
+			TransactionID: "tx",
+			Status:        database.StatusCancelled,
+			StatusHistory: []database.StatusTransition{{Status: database.StatusCancelled, At: old}},
+			Devices: []*database.TransactionDevice{
+				{StartAction: status("success", old), CancelAction: status("pending", old)},
+				{StartAction: status("cancelled", old)},
+			},
+		}
+
+		stuck := stuckActions(transaction, nil, before)
+		assert.Equal(t, []stuckAction{{index: 0, action: event.ActionCancel, status: transaction.Devices[0].CancelAction}}, stuck)
+	})
+	t.Run("cancel awaiting a lost START", func(t *testing.T) {
+		transaction := &database.Transaction{
+			TransactionID: "tx",
+			Status:        database.StatusCancelled,
+			StatusHistory: []database.StatusTransition{{Status: database.StatusCancelled, At: old}},
+			Devices: []*database.TransactionDevice{
+				{StartAction: status("in-progress", old), CancelAction: status("awaiting-start", old)},
+			},
+		}
+
+		stuck := stuckActions(transaction, nil, before)
+		assert.Equal(t, []stuckAction{{index: 0, action: event.ActionCancel, status: transaction.Devices[0].CancelAction}}, stuck)
+	})
+}
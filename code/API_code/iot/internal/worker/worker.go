@@ -18,20 +18,43 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/receiver"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/easyapi"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
 )
 
+const (
+	defaultMaxConcurrency      = 10
+	defaultDeviceLeaseDuration = 2 * time.Minute
+	defaultDeviceWait          = 1 * time.Minute
+
+	// minDeviceWaitInterval and maxDeviceWaitInterval bound the interval between two attempts to reserve a busy device
+	minDeviceWaitInterval = 100 * time.Millisecond
+	maxDeviceWaitInterval = 5 * time.Second
+)
+
+// errDeviceBusy is returned when a device stayed reserved by other actuations for the whole wait. It does not
+// wrap receiver.ErrDeferred, so that the request is recorded as a dead letter if its redeliveries fail too.
+var errDeviceBusy = errors.New("device busy")
+
+// errDeviceLeaseLost is returned when the lease of a device was lost during its actuation.
+var errDeviceLeaseLost = errors.New("device lease lost")
+
 // ActuationWorker handles device actuation requests.
 type ActuationWorker struct {
 	database     database.Interface
@@ -39,6 +62,29 @@ type ActuationWorker struct {
 	sender       event.Sender
 	receiver     event.Receiver
 	config       config.PowerSaving
+	// requeueWhenOpen leaves the device actions pending while the circuit breaker of the device client is open
+	requeueWhenOpen bool
+	// verify reads the configuration of a device back after changing it
+	verify bool
+
+	// slots holds a token for each device actuation in progress, up to the maximum concurrency
+	slots chan struct{}
+	// replicaID and deviceLeaseDuration identify and bound the leases reserving the devices to this replica
+	replicaID           string
+	deviceLeaseDuration time.Duration
+	// deviceWait bounds how long an actuation waits for a device reserved by another actuation
+	deviceWait time.Duration
+}
+
+// Limits bound the device actuations of a worker replica.
+type Limits struct {
+	// MaxConcurrency caps the devices actuated at once.
+	MaxConcurrency int
+	// DeviceLeaseDuration is how long a device is reserved to the replica actuating it, renewed while the
+	// actuation runs, so that the actuations of a device never run in parallel across replicas.
+	DeviceLeaseDuration time.Duration
+	// DeviceWait is how long an actuation waits for a device reserved by another actuation before failing.
+	DeviceWait time.Duration
 }
 
 // Handler implements receiver.Handler interface for CloudEvents.
@@ -51,14 +97,33 @@ func (h *Handler) Handle(ctx context.Context, e cloudevents.Event) (*cloudevents
 	return nil, err
 }
 
-// New creates a new ActuationWorker.
-func New(db database.Interface, deviceClient easyapi.Client, sender event.Sender, receiver event.Receiver, powerSavingConfig config.PowerSaving) *ActuationWorker {
+// New creates a new ActuationWorker. The EasyAPI configuration tells whether the device actions failing fast
+// on an open circuit breaker are requeued, and whether the configuration of a device is read back after a change.
+func New(db database.Interface, deviceClient easyapi.Client, sender event.Sender, receiver event.Receiver, powerSavingConfig config.PowerSaving, easyAPIConfig config.EasyAPI, limits Limits) *ActuationWorker {
+	if limits.MaxConcurrency <= 0 {
+		limits.MaxConcurrency = defaultMaxConcurrency
+	}
+	if limits.DeviceLeaseDuration == 0 {
+		limits.DeviceLeaseDuration = defaultDeviceLeaseDuration
+	}
+	if limits.DeviceWait == 0 {
+		limits.DeviceWait = defaultDeviceWait
+	}
+	hostname, _ := os.Hostname()
+
 	return &ActuationWorker{
-		database:     db,
-		deviceClient: deviceClient,
-		sender:       sender,
-		receiver:     receiver,
-		config:       powerSavingConfig,
+		database:        db,
+		deviceClient:    deviceClient,
+		sender:          sender,
+		receiver:        receiver,
+		config:          powerSavingConfig,
+		requeueWhenOpen: easyAPIConfig.BreakerRequeue,
+		verify:          easyAPIConfig.Verify,
+
+		slots:               make(chan struct{}, limits.MaxConcurrency),
+		replicaID:           hostname + "-" + uuid.NewString()[:8],
+		deviceLeaseDuration: limits.DeviceLeaseDuration,
+		deviceWait:          limits.DeviceWait,
 	}
 }
 
@@ -73,7 +138,7 @@ func (w *ActuationWorker) Start() error {
 
 // handleActuationRequest processes incoming device.actuation.request events.
 func (w *ActuationWorker) handleActuationRequest(ctx context.Context, e cloudevents.Event) error {
-	log := logger.Get().With(zap.String("eventId", e.ID()), zap.String("eventType", e.Type()))
+	log := logger.FromContext(ctx).With(zap.String("eventId", e.ID()), zap.String("eventType", e.Type()))
 
 	var data event.DeviceActuationRequestData
 	if err := json.Unmarshal(e.Data(), &data); err != nil {
@@ -87,9 +152,22 @@ func (w *ActuationWorker) handleActuationRequest(ctx context.Context, e cloudeve
 		zap.String("deviceId", deviceID),
 		zap.String("transactionId", data.TransactionID),
 		zap.Bool("enabled", data.Enabled),
-		zap.String("action", data.Action))
+		zap.String("action", data.Action),
+		zap.Int("attempt", data.Attempt))
 
-	if err := w.processDevice(ctx, data.TransactionID, data.Device, deviceID, data.Action, data.Enabled, data.SubscriptionRequest); err != nil {
+	reservedCtx, release, err := w.reserveDevice(ctx, deviceID)
+	if err != nil {
+		log.Warn("Device actuation not started", zap.Error(err), zap.String("deviceId", deviceID))
+		return err
+	}
+	defer release()
+
+	err = w.processDevice(reservedCtx, data.TransactionID, data.Device, deviceID, data.Action, data.Enabled, data.Profile, data.ProfileSettings, data.Attempt, data.SubscriptionRequest)
+	if err != nil && reservedCtx.Err() != nil && ctx.Err() == nil {
+		// The actuation was stopped: the request is processed again on redelivery
+		err = fmt.Errorf("%w: %w", errDeviceLeaseLost, err)
+	}
+	if err != nil {
 		log.Error("Failed to process device", zap.Error(err), zap.String("deviceId", deviceID))
 		return err
 	}
@@ -98,52 +176,188 @@ func (w *ActuationWorker) handleActuationRequest(ctx context.Context, e cloudeve
 	return nil
 }
 
+// reserveDevice takes the lease of the device, waiting with backoff while another actuation holds it, then a
+// processing slot of the replica. The lease is renewed until the returned function releases both, and the
+// returned context is cancelled once the lease is lost, so that the actuation stops.
+// It returns an error wrapping errDeviceBusy when the device stayed busy for the whole wait, and one wrapping
+// receiver.ErrDeferred when the request is cancelled while waiting, so that the broker delivers it again later.
+func (w *ActuationWorker) reserveDevice(ctx context.Context, deviceID string) (reserved context.Context, release func(), err error) {
+	name := deviceLeaseName(deviceID)
+	holder := w.replicaID + "/" + uuid.NewString()
+	if err := w.waitDeviceLease(ctx, name, holder); err != nil {
+		return nil, nil, fmt.Errorf("device %s: %w", deviceID, err)
+	}
+
+	reserved, lost := context.WithCancel(ctx)
+	done := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		w.renewDeviceLease(ctx, name, holder, done, lost)
+	}()
+	releaseLease := func() {
+		close(done)
+		<-renewed
+		lost()
+		// Release the lease even when the request is done, so that the next actuation does not wait for it to expire
+		if err := w.database.ReleaseLease(context.WithoutCancel(ctx), name, holder); err != nil {
+			logger.FromContext(ctx).Warn("Failed to release device lease", zap.Error(err), zap.String("deviceId", deviceID))
+		}
+	}
+
+	select {
+	case w.slots <- struct{}{}:
+	case <-reserved.Done():
+		releaseLease()
+		return nil, nil, fmt.Errorf("%w: no processing slot: %w", receiver.ErrDeferred, reserved.Err())
+	}
+
+	return reserved, func() {
+		releaseLease()
+		<-w.slots
+	}, nil
+}
+
+// waitDeviceLease acquires the lease of a device for holder, retrying with backoff while another actuation
+// holds it, for up to the device wait.
+func (w *ActuationWorker) waitDeviceLease(ctx context.Context, name string, holder string) error {
+	deadline := time.Now().Add(w.deviceWait)
+	interval := minDeviceWaitInterval
+	for {
+		_, err := w.database.AcquireLease(ctx, name, holder, w.deviceLeaseDuration)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, database.ErrLeaseHeld) {
+			return fmt.Errorf("acquire device lease: %w", err)
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%w for %s", errDeviceBusy, w.deviceWait)
+		}
+		timer := time.NewTimer(min(interval, remaining))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: waiting for busy device: %w", receiver.ErrDeferred, ctx.Err())
+		}
+		interval = min(interval*2, maxDeviceWaitInterval)
+	}
+}
+
+// renewDeviceLease renews the lease of a device until done is closed. It calls lost once the lease is held by
+// another actuation, or could not be renewed before it expired.
+func (w *ActuationWorker) renewDeviceLease(ctx context.Context, name string, holder string, done <-chan struct{}, lost context.CancelFunc) {
+	log := logger.FromContext(ctx).With(zap.String("lease", name))
+	ticker := time.NewTicker(w.deviceLeaseDuration / 3)
+	defer ticker.Stop()
+
+	expiresAt := time.Now().Add(w.deviceLeaseDuration)
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		_, err := w.database.AcquireLease(context.WithoutCancel(ctx), name, holder, w.deviceLeaseDuration)
+		switch {
+		case err == nil:
+			expiresAt = time.Now().Add(w.deviceLeaseDuration)
+		case errors.Is(err, database.ErrLeaseHeld):
+			log.Warn("Device lease lost, stopping the actuation")
+			lost()
+			return
+		case time.Now().After(expiresAt):
+			log.Warn("Device lease expired before it could be renewed, stopping the actuation", zap.Error(err))
+			lost()
+			return
+		default:
+			log.Warn("Failed to renew device lease, retrying", zap.Error(err))
+		}
+	}
+}
+
+// deviceLeaseName returns the name of the lease reserving a device to the actuation in progress.
+func deviceLeaseName(deviceID string) string {
+	return "device/" + deviceID
+}
+
 // processDevice handles actuation for a single device based on action type.
-func (w *ActuationWorker) processDevice(ctx context.Context, transactionID string, device models.Device, deviceID string, action string, enabled bool, subscriptionRequest models.SubscriptionRequest) error {
-	log := logger.Get().With(
+// The attempt number is kept in the device status, so that the watchdog knows how many requests were published,
+// along with the number of requests made to the backend, retries included.
+func (w *ActuationWorker) processDevice(ctx context.Context, transactionID string, device models.Device, deviceID string, action string, enabled bool, profile string, profileSettings *config.Profile, attempt int, subscriptionRequest models.SubscriptionRequest) error {
+	log := logger.FromContext(ctx).With(
 		zap.String("transactionId", transactionID),
 		zap.String("deviceId", deviceID),
 		zap.String("action", action),
-		zap.Bool("enabled", enabled))
+		zap.Bool("enabled", enabled),
+		zap.String("profile", profile))
+
+	if action == event.ActionStart {
+		// A START requested before the transaction was stopped must not apply power-saving anymore
+		transaction, err := w.database.GetTransaction(ctx, transactionID)
+		if err != nil {
+			log.Error("Failed to get transaction", zap.Error(err))
+			return fmt.Errorf("get transaction: %w", err)
+		}
+		if txDevice := transactionDevice(transaction, deviceID); transaction.Status.Final() ||
+			(txDevice != nil && txDevice.StartAction != nil && txDevice.StartAction.Status == "cancelled") {
+			log.Info("Transaction stopped before START, skipping device", zap.String("status", string(transaction.Status)))
+			return nil
+		}
+	}
 
-	if _, err := w.database.UpdateDeviceActionStatus(ctx, transactionID, deviceID, action, "in-progress"); err != nil {
+	_, err := w.database.UpdateDeviceActionStatus(ctx, transactionID, deviceID, action, &database.DeviceActionStatus{Status: "in-progress", Attempts: attempt})
+	if errors.Is(err, database.ErrDeviceActionCancelled) {
+		log.Info("Device action cancelled, skipping device")
+		return nil
+	}
+	if err != nil {
 		log.Error("Failed to update status to in-progress", zap.Error(err))
 		return fmt.Errorf("update status to in-progress: %w", err)
 	}
 
-	finalStatus := "success"
+	ctx, backendAttempts := easyapi.WithAttemptCounter(ctx)
+	finalStatus := &database.DeviceActionStatus{Status: "success"}
+
+	// Settings of the profile, used by the actions applying power-saving
+	powerSavingConfig, profileErr := w.powerSavingConfig(profile, profileSettings)
 
 	if action == event.ActionStart {
 		if enabled {
 			log.Debug("Processing start action - applying power-saving", zap.String("deviceId", deviceID))
 
-			currentConfig, err := w.deviceClient.GetDeviceConfig(ctx, device)
-			if err != nil {
+			if profileErr != nil {
+				log.Error("Failed to resolve power-saving profile", zap.Error(profileErr))
+				finalStatus = failedStatus(models.ErrorCodeProfileNotFound, profileErr)
+			} else if currentConfig, err := w.deviceClient.GetDeviceConfig(ctx, device); err != nil {
 				log.Error("Failed to get device config", zap.Error(err), zap.String("deviceId", deviceID))
-				finalStatus = "failed"
+				finalStatus = w.backendFailedStatus(err)
 			} else {
 				originalState := &database.DeviceOriginalState{
-					PpMaximumLatency:      currentConfig.PpMaximumLatency,
-					PpMaximumResponseTime: currentConfig.PpMaximumResponseTime,
+					PpMaximumLatency:             currentConfig.PpMaximumLatency,
+					PpMaximumResponseTime:        currentConfig.PpMaximumResponseTime,
+					AppliedPpMaximumLatency:      powerSavingConfig.PpMaximumLatency,
+					AppliedPpMaximumResponseTime: powerSavingConfig.PpMaximumResponseTime,
 				}
 
-				if err := w.database.StoreDeviceOriginalState(ctx, deviceID, originalState); err != nil {
+				// The snapshot kept holds the baseline of a device not restored since an earlier transaction
+				if storedState, err := w.database.StoreDeviceOriginalState(ctx, deviceID, transactionID, originalState); err != nil {
 					log.Error("Failed to store device original state", zap.Error(err), zap.String("deviceId", deviceID))
-					finalStatus = "failed"
+					finalStatus = failedStatus(models.ErrorCodeInternal, err)
 				} else {
 					log.Debug("Stored original device configuration",
 						zap.String("deviceId", deviceID),
-						zap.String("ppMaximumLatency", currentConfig.PpMaximumLatency),
-						zap.String("ppMaximumResponseTime", currentConfig.PpMaximumResponseTime))
-
-					powerSavingConfig := &easyapi.DeviceConfig{
-						PpMaximumLatency:      w.config.MaxLatency,
-						PpMaximumResponseTime: w.config.MaxResponseTime,
-					}
+						zap.String("ppMaximumLatency", storedState.PpMaximumLatency),
+						zap.String("ppMaximumResponseTime", storedState.PpMaximumResponseTime),
+						zap.String("inheritedFrom", storedState.InheritedFrom))
 
-					if err := w.deviceClient.SetDeviceConfig(ctx, device, powerSavingConfig); err != nil {
+					if err := w.applyDeviceConfig(ctx, device, powerSavingConfig); err != nil {
 						log.Error("Failed to set device config", zap.Error(err), zap.String("deviceId", deviceID))
-						finalStatus = "failed"
+						finalStatus = w.backendFailedStatus(err)
 					} else {
 						log.Debug("Device actuation successful - power-saving applied",
 							zap.String("deviceId", deviceID))
@@ -153,81 +367,88 @@ func (w *ActuationWorker) processDevice(ctx context.Context, transactionID strin
 		} else {
 			log.Debug("Processing start action - restoring original config", zap.String("deviceId", deviceID))
 
-			storedState, err := w.database.GetDeviceOriginalState(ctx, deviceID)
+			storedState, err := w.database.GetDeviceOriginalState(ctx, deviceID, "")
 			if err != nil {
 				log.Error("No original state found for device - cannot restore",
 					zap.String("deviceId", deviceID),
 					zap.Error(err))
-				finalStatus = "failed"
+				finalStatus = failedStatus(originalStateErrorCode(err), err)
 			} else {
 				log.Debug("Retrieved original device configuration",
 					zap.String("deviceId", deviceID),
 					zap.String("ppMaximumLatency", storedState.PpMaximumLatency),
 					zap.String("ppMaximumResponseTime", storedState.PpMaximumResponseTime))
 
-				originalConfig := &easyapi.DeviceConfig{
-					PpMaximumLatency:      storedState.PpMaximumLatency,
-					PpMaximumResponseTime: storedState.PpMaximumResponseTime,
-				}
-
-				if err := w.deviceClient.SetDeviceConfig(ctx, device, originalConfig); err != nil {
-					log.Error("Failed to restore device config", zap.Error(err), zap.String("deviceId", deviceID))
-					finalStatus = "failed"
-				} else {
+				finalStatus = w.restoreDevice(ctx, device, deviceID, transactionID, storedState)
+				if finalStatus.Status == "success" {
 					log.Debug("Device actuation successful - original config restored",
-						zap.String("deviceId", deviceID))
+						zap.String("deviceId", deviceID),
+						zap.String("drift", finalStatus.Drift))
 				}
 			}
 		}
 
 	} else if action == event.ActionEnd {
-		if enabled {
+		// The scheduler sends the inverse of the transaction's enabled flag for END:
+		// enabled=false undoes a power-saving activation by restoring the original config.
+		if !enabled {
 			log.Debug("Processing end action - restoring original config", zap.String("deviceId", deviceID))
 
-			storedState, err := w.database.GetDeviceOriginalState(ctx, deviceID)
+			storedState, err := w.database.GetDeviceOriginalState(ctx, deviceID, transactionID)
 			if err != nil {
 				log.Error("No original state found for device - START action likely failed",
 					zap.String("deviceId", deviceID),
 					zap.Error(err))
-				finalStatus = "failed"
+				finalStatus = failedStatus(originalStateErrorCode(err), err)
 			} else {
 				log.Debug("Retrieved original device configuration",
 					zap.String("deviceId", deviceID),
 					zap.String("ppMaximumLatency", storedState.PpMaximumLatency),
 					zap.String("ppMaximumResponseTime", storedState.PpMaximumResponseTime))
 
-				originalConfig := &easyapi.DeviceConfig{
-					PpMaximumLatency:      storedState.PpMaximumLatency,
-					PpMaximumResponseTime: storedState.PpMaximumResponseTime,
-				}
-
-				if err := w.deviceClient.SetDeviceConfig(ctx, device, originalConfig); err != nil {
-					log.Error("Failed to restore device config", zap.Error(err), zap.String("deviceId", deviceID))
-					finalStatus = "failed"
-				} else {
+				finalStatus = w.restoreDevice(ctx, device, deviceID, transactionID, storedState)
+				if finalStatus.Status == "success" {
 					log.Debug("Device actuation successful - original config restored",
-						zap.String("deviceId", deviceID))
+						zap.String("deviceId", deviceID),
+						zap.String("drift", finalStatus.Drift))
 				}
 			}
 		} else {
 			log.Debug("Processing end action - applying power-saving", zap.String("deviceId", deviceID))
 
-			powerSavingConfig := &easyapi.DeviceConfig{
-				PpMaximumLatency:      w.config.MaxLatency,
-				PpMaximumResponseTime: w.config.MaxResponseTime,
-			}
-
-			if err := w.deviceClient.SetDeviceConfig(ctx, device, powerSavingConfig); err != nil {
+			if profileErr != nil {
+				log.Error("Failed to resolve power-saving profile", zap.Error(profileErr))
+				finalStatus = failedStatus(models.ErrorCodeProfileNotFound, profileErr)
+			} else if err := w.applyDeviceConfig(ctx, device, powerSavingConfig); err != nil {
 				log.Error("Failed to set device config", zap.Error(err), zap.String("deviceId", deviceID))
-				finalStatus = "failed"
+				finalStatus = w.backendFailedStatus(err)
 			} else {
 				log.Debug("Device actuation successful - power-saving applied",
 					zap.String("deviceId", deviceID))
 			}
 		}
+	} else if action == event.ActionCancel {
+		log.Debug("Processing cancel action - restoring original config", zap.String("deviceId", deviceID))
+
+		storedState, err := w.database.GetDeviceOriginalState(ctx, deviceID, transactionID)
+		if err != nil {
+			log.Error("No original state found for device - cannot restore cancelled transaction",
+				zap.String("deviceId", deviceID),
+				zap.Error(err))
+			finalStatus = failedStatus(originalStateErrorCode(err), err)
+		} else {
+			finalStatus = w.restoreDevice(ctx, device, deviceID, transactionID, storedState)
+			if finalStatus.Status == "success" {
+				log.Debug("Device actuation successful - original config restored after cancellation",
+					zap.String("deviceId", deviceID),
+					zap.String("drift", finalStatus.Drift))
+			}
+		}
 	}
 
-	allComplete, err := w.database.UpdateDeviceActionStatus(ctx, transactionID, deviceID, action, finalStatus)
+	finalStatus.Attempts = attempt
+	finalStatus.BackendAttempts = backendAttempts.Count()
+	progress, err := w.database.UpdateDeviceActionStatus(ctx, transactionID, deviceID, action, finalStatus)
 	if err != nil {
 		log.Error("Failed to update device status", zap.Error(err))
 		return fmt.Errorf("update device status: %w", err)
@@ -235,10 +456,43 @@ func (w *ActuationWorker) processDevice(ctx context.Context, transactionID strin
 
 	log.Debug("Device status updated",
 		zap.String("deviceId", deviceID),
-		zap.String("status", finalStatus),
-		zap.Bool("allComplete", allComplete))
+		zap.String("status", finalStatus.Status),
+		zap.String("errorCode", finalStatus.ErrorCode),
+		zap.Int("backendAttempts", finalStatus.BackendAttempts),
+		zap.Bool("allComplete", progress.AllCompleted),
+		zap.Bool("canaryComplete", progress.CanaryCompleted))
+
+	if err := w.reportProgress(ctx, transactionID, action, progress, subscriptionRequest); err != nil {
+		return err
+	}
+
+	if action == event.ActionStart {
+		return w.restoreIfStopped(ctx, transactionID, device, deviceID, finalStatus, subscriptionRequest)
+	}
+	return nil
+}
+
+// reportProgress publishes the completion of an action on the canary devices, or on all the devices.
+func (w *ActuationWorker) reportProgress(ctx context.Context, transactionID string, action string, progress database.ActionProgress, subscriptionRequest models.SubscriptionRequest) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transactionID), zap.String("action", action))
+
+	if progress.CanaryCompleted {
+		log.Info("All canary devices completed, sending canary.completed event",
+			zap.String("transactionId", transactionID))
+
+		canaryCompletedData := event.CanaryCompletedData{
+			TransactionID: transactionID,
+			CompletedAt:   time.Now(),
+		}
 
-	if allComplete {
+		eventID := fmt.Sprintf("%s-canary-completed", transactionID)
+		if err := w.sender.Send(ctx, eventID, event.EventTypeCanaryCompleted, event.SourceiotWorker, canaryCompletedData); err != nil {
+			log.Error("Failed to send canary.completed event", zap.Error(err))
+			return fmt.Errorf("send canary.completed event: %w", err)
+		}
+	}
+
+	if progress.AllCompleted {
 		log.Info("All devices completed, sending notification event",
 			zap.String("transactionId", transactionID),
 			zap.String("action", action))
@@ -257,41 +511,214 @@ func (w *ActuationWorker) processDevice(ctx context.Context, transactionID strin
 		}
 
 		log.Debug("All-devices.completed event sent successfully")
-
-		if err := w.markTransactionCompleteIfDone(ctx, transactionID, action); err != nil {
-			log.Error("Failed to mark transaction as completed", zap.Error(err))
-		}
 	}
 
 	return nil
 }
 
-// markTransactionCompleteIfDone marks the transaction as completed when all actions finish.
-func (w *ActuationWorker) markTransactionCompleteIfDone(ctx context.Context, transactionID string, action string) error {
-	log := logger.Get().With(zap.String("transactionId", transactionID), zap.String("action", action))
+// restoreIfStopped restores a device whose START was in progress when the transaction was cancelled. Its cancel
+// action awaited the START, so that the restore cannot interleave with it. A START that failed applied nothing:
+// its failure is recorded as the result of the cancel action.
+func (w *ActuationWorker) restoreIfStopped(ctx context.Context, transactionID string, device models.Device, deviceID string, startStatus *database.DeviceActionStatus, subscriptionRequest models.SubscriptionRequest) error {
+	log := logger.FromContext(ctx).With(zap.String("transactionId", transactionID), zap.String("deviceId", deviceID))
 
 	transaction, err := w.database.GetTransaction(ctx, transactionID)
 	if err != nil {
 		log.Error("Failed to get transaction", zap.Error(err))
-		return err
+		return fmt.Errorf("get transaction: %w", err)
+	}
+	txDevice := transactionDevice(transaction, deviceID)
+	if txDevice == nil || txDevice.CancelAction == nil || txDevice.CancelAction.Status != "awaiting-start" {
+		return nil
 	}
 
-	shouldComplete := false
-	if action == event.ActionStart && transaction.EndAt == nil {
-		shouldComplete = true
-		log.Debug("START action completed and no END scheduled - marking transaction as completed")
-	} else if action == event.ActionEnd {
-		shouldComplete = true
-		log.Debug("END action completed - marking transaction as completed")
+	if startStatus.Status == "success" {
+		log.Info("Transaction cancelled during START, restoring device")
+		return w.processDevice(ctx, transactionID, device, deviceID, event.ActionCancel, false, "", nil, 1, subscriptionRequest)
 	}
 
-	if shouldComplete {
-		if err := w.database.MarkTransactionCompleted(ctx, transactionID); err != nil {
-			log.Error("Failed to mark transaction as completed", zap.Error(err))
-			return err
+	log.Info("Transaction cancelled during a failed START, nothing to restore")
+	status := *startStatus
+	progress, err := w.database.UpdateDeviceActionStatus(ctx, transactionID, deviceID, event.ActionCancel, &status)
+	if err != nil {
+		log.Error("Failed to update device status", zap.Error(err))
+		return fmt.Errorf("update device status: %w", err)
+	}
+	return w.reportProgress(ctx, transactionID, event.ActionCancel, progress, subscriptionRequest)
+}
+
+// transactionDevice returns the device of a transaction, nil when the transaction has no such device.
+func transactionDevice(transaction *database.Transaction, deviceID string) *database.TransactionDevice {
+	for _, txDevice := range transaction.Devices {
+		if txDevice.DeviceID == deviceID {
+			return txDevice
 		}
-		log.Info("Transaction marked as completed")
+	}
+	return nil
+}
+
+// restoreDevice restores the configuration of a device from a snapshot and returns the status of the action.
+// When the snapshot was taken by the transaction, the device is read first: a configuration changed outside
+// the service since power-saving was applied is handled by the drift policy, and the decision is recorded.
+func (w *ActuationWorker) restoreDevice(ctx context.Context, device models.Device, deviceID string, transactionID string, storedState *database.DeviceOriginalState) *database.DeviceActionStatus {
+	log := logger.FromContext(ctx).With(zap.String("deviceId", deviceID))
+
+	drift := ""
+	if storedState.TransactionID == transactionID && storedState.AppliedPpMaximumLatency != "" {
+		applied := &easyapi.DeviceConfig{
+			PpMaximumLatency:      storedState.AppliedPpMaximumLatency,
+			PpMaximumResponseTime: storedState.AppliedPpMaximumResponseTime,
+		}
+		current, err := w.deviceClient.GetDeviceConfig(ctx, device)
+		if err != nil {
+			log.Error("Failed to read device config before restoring it", zap.Error(err))
+			return w.backendFailedStatus(fmt.Errorf("read device config: %w", err))
+		}
+
+		if !sameDeviceConfig(current, applied) {
+			log.Warn("Device config changed outside the service since power-saving was applied",
+				zap.String("ppMaximumLatency", current.PpMaximumLatency),
+				zap.String("ppMaximumResponseTime", current.PpMaximumResponseTime),
+				zap.String("driftPolicy", w.config.DriftPolicy))
+
+			switch w.config.DriftPolicy {
+			case config.DriftSkip:
+				// The configuration now belongs to another system, so no snapshot of the device is the one to restore
+				// anymore, including the unrestored snapshots of other transactions
+				w.markRestored(ctx, deviceID)
+				return &database.DeviceActionStatus{Status: "success", Drift: string(models.DriftSkipped)}
+			case config.DriftFail:
+				status := failedStatus(models.ErrorCodeDriftDetected, fmt.Errorf(
+					"configuration changed outside the service: ppMaximumLatency %q and ppMaximumResponseTime %q found, %q and %q applied",
+					current.PpMaximumLatency, current.PpMaximumResponseTime,
+					applied.PpMaximumLatency, applied.PpMaximumResponseTime))
+				status.Drift = string(models.DriftFailed)
+				return status
+			default:
+				drift = string(models.DriftRestored)
+			}
+		}
+	}
+
+	originalConfig := &easyapi.DeviceConfig{
+		PpMaximumLatency:      storedState.PpMaximumLatency,
+		PpMaximumResponseTime: storedState.PpMaximumResponseTime,
+	}
+	if err := w.applyDeviceConfig(ctx, device, originalConfig); err != nil {
+		log.Error("Failed to restore device config", zap.Error(err))
+		status := w.backendFailedStatus(err)
+		status.Drift = drift
+		return status
+	}
+
+	w.markRestored(ctx, deviceID)
+	return &database.DeviceActionStatus{Status: "success", Drift: drift}
+}
+
+// markRestored records that a device is back to its baseline, so that the next transaction backs up its
+// configuration again. The device is restored either way, so a failure is only logged.
+func (w *ActuationWorker) markRestored(ctx context.Context, deviceID string) {
+	if err := w.database.MarkDeviceRestored(ctx, deviceID); err != nil {
+		logger.FromContext(ctx).Error("Failed to mark device as restored", zap.String("deviceId", deviceID), zap.Error(err))
+	}
+}
+
+// errVerificationFailed is returned when the configuration read back from a device differs from the one applied.
+var errVerificationFailed = errors.New("verification failed")
+
+// applyDeviceConfig changes the configuration of a device. With verification enabled, the configuration is read
+// back, as the backend may accept a change without it taking effect.
+func (w *ActuationWorker) applyDeviceConfig(ctx context.Context, device models.Device, deviceConfig *easyapi.DeviceConfig) error {
+	if err := w.deviceClient.SetDeviceConfig(ctx, device, deviceConfig); err != nil {
+		return err
+	}
+	if !w.verify {
+		return nil
 	}
 
+	actual, err := w.deviceClient.GetDeviceConfig(ctx, device)
+	if err != nil {
+		return fmt.Errorf("read back device config: %w", err)
+	}
+	if !sameDeviceConfig(actual, deviceConfig) {
+		return fmt.Errorf("%w: ppMaximumLatency %q and ppMaximumResponseTime %q read back, %q and %q applied",
+			errVerificationFailed,
+			actual.PpMaximumLatency, actual.PpMaximumResponseTime,
+			deviceConfig.PpMaximumLatency, deviceConfig.PpMaximumResponseTime)
+	}
 	return nil
 }
+
+// sameDeviceConfig reports whether two configurations hold the same values. The backend returns numbers,
+// so values are compared as numbers when they are.
+func sameDeviceConfig(a, b *easyapi.DeviceConfig) bool {
+	return sameValue(a.PpMaximumLatency, b.PpMaximumLatency) && sameValue(a.PpMaximumResponseTime, b.PpMaximumResponseTime)
+}
+
+func sameValue(a, b string) bool {
+	x, errX := strconv.Atoi(strings.TrimSpace(a))
+	y, errY := strconv.Atoi(strings.TrimSpace(b))
+	if errX == nil && errY == nil {
+		return x == y
+	}
+	return a == b
+}
+
+// failedStatus returns the status of a device action that failed with err, with the error code reported to the consumer.
+func failedStatus(code models.DeviceErrorCode, err error) *database.DeviceActionStatus {
+	return &database.DeviceActionStatus{
+		Status:       "failed",
+		ErrorCode:    string(code),
+		ErrorMessage: err.Error(),
+	}
+}
+
+// backendFailedStatus returns the status of a device action that failed calling the device client.
+// While the circuit breaker is open, the action is requeued if enabled: it is left pending, with the reason.
+func (w *ActuationWorker) backendFailedStatus(err error) *database.DeviceActionStatus {
+	status := failedStatus(backendErrorCode(err), err)
+	if w.requeueWhenOpen && errors.Is(err, easyapi.ErrCircuitOpen) {
+		status.Status = "pending"
+	}
+	return status
+}
+
+// backendErrorCode classifies an error returned by the device client.
+func backendErrorCode(err error) models.DeviceErrorCode {
+	switch {
+	case errors.Is(err, easyapi.ErrDeviceNotFound):
+		return models.ErrorCodeDeviceNotFound
+	case errors.Is(err, errVerificationFailed):
+		return models.ErrorCodeVerificationFailed
+	case errors.Is(err, easyapi.ErrBackendUnavailable), errors.Is(err, context.DeadlineExceeded):
+		return models.ErrorCodeBackendUnavailable
+	default:
+		return models.ErrorCodeBackendError
+	}
+}
+
+// originalStateErrorCode classifies an error returned when reading the backed up configuration of a device.
+func originalStateErrorCode(err error) models.DeviceErrorCode {
+	if errors.Is(err, database.ErrOriginalStateNotFound) {
+		return models.ErrorCodeOriginalStateMissing
+	}
+	return models.ErrorCodeInternal
+}
+
+// powerSavingConfig returns the device configuration of a power-saving profile, from the settings resolved when
+// the transaction was requested, or else from the configuration of the worker.
+func (w *ActuationWorker) powerSavingConfig(profile string, resolved *config.Profile) (*easyapi.DeviceConfig, error) {
+	var settings config.Profile
+	if resolved != nil {
+		settings = *resolved
+	} else {
+		var err error
+		if settings, err = w.config.Profile(profile); err != nil {
+			return nil, err
+		}
+	}
+	return &easyapi.DeviceConfig{
+		PpMaximumLatency:      settings.MaxLatency,
+		PpMaximumResponseTime: settings.MaxResponseTime,
+	}, nil
+}
@@ -0,0 +1,491 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/database"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/receiver"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/easyapi"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/event"
+)
+
+// ignoringClient accepts every change and keeps returning the same configuration, or readErr.
+type ignoringClient struct {
+	config  easyapi.DeviceConfig
+	readErr error
+	reads   int
+	writes  int
+	written easyapi.DeviceConfig // last configuration set
+}
+
+func (c *ignoringClient) GetDeviceConfig(ctx context.Context, device models.Device) (*easyapi.DeviceConfig, error) {
+	c.reads++
+	if c.readErr != nil {
+		return nil, c.readErr
+	}
+	config := c.config
+	return &config, nil
+}
+
+func (c *ignoringClient) SetDeviceConfig(ctx context.Context, device models.Device, config *easyapi.DeviceConfig) error {
+	c.writes++
+	c.written = *config
+	return nil
+}
+
+// restoreDatabase records the devices marked as restored.
+type restoreDatabase struct {
+	database.Interface
+	restored []string
+}
+
+func (d *restoreDatabase) MarkDeviceRestored(ctx context.Context, deviceID string) error {
+	d.restored = append(d.restored, deviceID)
+	return nil
+}
+
+// stoppingDatabase holds a single transaction and records the device action updates. When stop is set, the
+// transaction is stopped with that status as soon as the START of its device is in progress.
+type stoppingDatabase struct {
+	database.Interface
+	transaction *database.Transaction
+	stop        database.Status
+	snapshot    *database.DeviceOriginalState
+	updates     []string
+}
+
+func (d *stoppingDatabase) GetTransaction(ctx context.Context, transactionID string) (*database.Transaction, error) {
+	return d.transaction, nil
+}
+
+func (d *stoppingDatabase) UpdateDeviceActionStatus(ctx context.Context, transactionID string, deviceID string, action string, status *database.DeviceActionStatus) (database.ActionProgress, error) {
+	txDevice := transactionDevice(d.transaction, deviceID)
+	switch action {
+	case event.ActionStart:
+		if txDevice.StartAction.Status == "cancelled" {
+			return database.ActionProgress{}, database.ErrDeviceActionCancelled
+		}
+		txDevice.StartAction = status
+	case event.ActionEnd:
+		txDevice.EndAction = status
+	default:
+		txDevice.CancelAction = status
+	}
+	d.updates = append(d.updates, action+":"+status.Status)
+
+	if action == event.ActionStart && status.Status == "in-progress" && d.stop != "" {
+		d.transaction.Status = d.stop
+		txDevice.CancelAction = &database.DeviceActionStatus{Status: "awaiting-start"}
+	}
+	return database.ActionProgress{}, nil
+}
+
+func (d *stoppingDatabase) StoreDeviceOriginalState(ctx context.Context, deviceID string, transactionID string, originalState *database.DeviceOriginalState) (*database.DeviceOriginalState, error) {
+	originalState.DeviceID = deviceID
+	originalState.TransactionID = transactionID
+	d.snapshot = originalState
+	return originalState, nil
+}
+
+func (d *stoppingDatabase) GetDeviceOriginalState(ctx context.Context, deviceID string, transactionID string) (*database.DeviceOriginalState, error) {
+	if d.snapshot == nil {
+		return nil, database.ErrOriginalStateNotFound
+	}
+	return d.snapshot, nil
+}
+
+func (d *stoppingDatabase) MarkDeviceRestored(ctx context.Context, deviceID string) error {
+	return nil
+}
+
+func TestProcessDeviceStopped(t *testing.T) {
+	nai := "device@example.com"
+	device := models.Device{NetworkAccessIdentifier: &nai}
+
+	tests := []struct {
+		name        string
+		status      database.Status
+		startStatus string
+		stop        database.Status
+		readErr     error
+		wantUpdates []string
+		wantWrites  int
+	}{
+		{
+			name:        "skips the START of a stopped transaction",
+			status:      database.StatusFailed,
+			startStatus: "pending",
+		},
+		{
+			name:        "skips a cancelled START",
+			status:      database.StatusStarting,
+			startStatus: "cancelled",
+		},
+		{
+			name:        "applies the START of a running transaction",
+			status:      database.StatusStarting,
+			startStatus: "pending",
+			wantUpdates: []string{"start:in-progress", "start:success"},
+			wantWrites:  1,
+		},
+		{
+			name:        "restores a device cancelled during its START",
+			status:      database.StatusStarting,
+			startStatus: "pending",
+			stop:        database.StatusCancelled,
+			wantUpdates: []string{"start:in-progress", "start:success", "cancel:in-progress", "cancel:success"},
+			wantWrites:  2,
+		},
+		{
+			name:        "records a failed START as the restore",
+			status:      database.StatusStarting,
+			startStatus: "pending",
+			stop:        database.StatusCancelled,
+			readErr:     errors.New("device unreachable"),
+			wantUpdates: []string{"start:in-progress", "start:failed", "cancel:failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &stoppingDatabase{
+				transaction: &database.Transaction{
+					TransactionID: "tx-1",
+					Enabled:       true,
+					Status:        tt.status,
+					Devices: []*database.TransactionDevice{{
+						DeviceID:    nai,
+						Device:      device,
+						StartAction: &database.DeviceActionStatus{Status: tt.startStatus},
+					}},
+				},
+				stop: tt.stop,
+			}
+			client := &ignoringClient{config: easyapi.DeviceConfig{PpMaximumLatency: "100", PpMaximumResponseTime: "200"}, readErr: tt.readErr}
+			w := &ActuationWorker{
+				database:     db,
+				deviceClient: client,
+				config:       config.PowerSaving{MaxLatency: "20", MaxResponseTime: "20"},
+			}
+
+			err := w.processDevice(context.Background(), "tx-1", device, nai, event.ActionStart, true, "", nil, 1, models.SubscriptionRequest{})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUpdates, db.updates)
+			assert.Equal(t, tt.wantWrites, client.writes)
+		})
+	}
+}
+
+func TestProcessDeviceEnd(t *testing.T) {
+	nai := "device@example.com"
+	device := models.Device{NetworkAccessIdentifier: &nai}
+	original := easyapi.DeviceConfig{PpMaximumLatency: "100", PpMaximumResponseTime: "200"}
+	powerSaving := easyapi.DeviceConfig{PpMaximumLatency: "20", PpMaximumResponseTime: "20"}
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		wantWritten easyapi.DeviceConfig
+	}{
+		{
+			name:        "restores the original state at the end of a power-saving activation",
+			enabled:     true,
+			wantWritten: original,
+		},
+		{
+			name:        "applies power-saving at the end of a deactivation",
+			enabled:     false,
+			wantWritten: powerSaving,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &stoppingDatabase{
+				transaction: &database.Transaction{
+					TransactionID: "tx-1",
+					Enabled:       tt.enabled,
+					Status:        database.StatusStarting,
+					Devices: []*database.TransactionDevice{{
+						DeviceID:    nai,
+						Device:      device,
+						StartAction: &database.DeviceActionStatus{Status: "success"},
+					}},
+				},
+				snapshot: &database.DeviceOriginalState{
+					DeviceID:              nai,
+					PpMaximumLatency:      original.PpMaximumLatency,
+					PpMaximumResponseTime: original.PpMaximumResponseTime,
+				},
+			}
+			client := &ignoringClient{config: powerSaving}
+			w := &ActuationWorker{
+				database:     db,
+				deviceClient: client,
+				config:       config.PowerSaving{MaxLatency: powerSaving.PpMaximumLatency, MaxResponseTime: powerSaving.PpMaximumResponseTime},
+			}
+
+			// The scheduler requests END with the inverse of the transaction enabled flag
+			err := w.processDevice(context.Background(), "tx-1", device, nai, event.ActionEnd, !tt.enabled, "", nil, 1, models.SubscriptionRequest{})
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"end:in-progress", "end:success"}, db.updates)
+			assert.Equal(t, 1, client.writes)
+			assert.Equal(t, tt.wantWritten, client.written)
+		})
+	}
+}
+
+func TestProcessDeviceProfileSettings(t *testing.T) {
+	nai := "device@example.com"
+	device := models.Device{NetworkAccessIdentifier: &nai}
+	db := &stoppingDatabase{
+		transaction: &database.Transaction{
+			TransactionID: "tx-1",
+			Enabled:       true,
+			Status:        database.StatusStarting,
+			Devices: []*database.TransactionDevice{{
+				DeviceID:    nai,
+				Device:      device,
+				StartAction: &database.DeviceActionStatus{Status: "pending"},
+			}},
+		},
+	}
+	client := &ignoringClient{config: easyapi.DeviceConfig{PpMaximumLatency: "100", PpMaximumResponseTime: "200"}}
+	// The profile was changed since the transaction was requested
+	w := &ActuationWorker{
+		database:     db,
+		deviceClient: client,
+		config: config.PowerSaving{Profiles: map[string]config.Profile{
+			"deep": {MaxLatency: "60", MaxResponseTime: "60"},
+		}},
+	}
+
+	settings := &config.Profile{MaxLatency: "30", MaxResponseTime: "40"}
+	err := w.processDevice(context.Background(), "tx-1", device, nai, event.ActionStart, true, "deep", settings, 1, models.SubscriptionRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start:in-progress", "start:success"}, db.updates)
+	assert.Equal(t, easyapi.DeviceConfig{PpMaximumLatency: "30", PpMaximumResponseTime: "40"}, client.written)
+}
+
+func TestApplyDeviceConfig(t *testing.T) {
+	nai := "device@example.com"
+	device := models.Device{NetworkAccessIdentifier: &nai}
+	powerSaving := &easyapi.DeviceConfig{PpMaximumLatency: "20", PpMaximumResponseTime: "20"}
+
+	tests := []struct {
+		name      string
+		verify    bool
+		readBack  easyapi.DeviceConfig
+		wantReads int
+		wantCode  models.DeviceErrorCode
+	}{
+		{
+			name:     "does not read back without verification",
+			readBack: easyapi.DeviceConfig{PpMaximumLatency: "100", PpMaximumResponseTime: "200"},
+		},
+		{
+			name:      "accepts the applied values",
+			verify:    true,
+			readBack:  easyapi.DeviceConfig{PpMaximumLatency: "20", PpMaximumResponseTime: "020"},
+			wantReads: 1,
+		},
+		{
+			name:      "fails when the change did not take effect",
+			verify:    true,
+			readBack:  easyapi.DeviceConfig{PpMaximumLatency: "100", PpMaximumResponseTime: "20"},
+			wantReads: 1,
+			wantCode:  models.ErrorCodeVerificationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &ignoringClient{config: tt.readBack}
+			w := &ActuationWorker{deviceClient: client, verify: tt.verify}
+
+			err := w.applyDeviceConfig(context.Background(), device, powerSaving)
+
+			assert.Equal(t, tt.wantReads, client.reads)
+			if tt.wantCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			status := w.backendFailedStatus(err)
+			assert.Equal(t, "failed", status.Status)
+			assert.Equal(t, string(tt.wantCode), status.ErrorCode)
+		})
+	}
+}
+
+func TestRestoreDeviceDrift(t *testing.T) {
+	nai := "device@example.com"
+	device := models.Device{NetworkAccessIdentifier: &nai}
+	snapshot := &database.DeviceOriginalState{
+		DeviceID:                     nai,
+		TransactionID:                "tx-1",
+		PpMaximumLatency:             "100",
+		PpMaximumResponseTime:        "200",
+		AppliedPpMaximumLatency:      "20",
+		AppliedPpMaximumResponseTime: "20",
+	}
+	applied := easyapi.DeviceConfig{PpMaximumLatency: "20", PpMaximumResponseTime: "20"}
+	changed := easyapi.DeviceConfig{PpMaximumLatency: "50", PpMaximumResponseTime: "20"}
+
+	tests := []struct {
+		name          string
+		policy        string
+		transactionID string
+		current       easyapi.DeviceConfig
+		wantStatus    string
+		wantCode      string
+		wantDrift     models.DriftDecision
+		wantReads     int
+		wantWrites    int
+		wantRestored  bool
+	}{
+		{name: "restores without drift", policy: config.DriftFail, transactionID: "tx-1", current: applied, wantStatus: "success", wantReads: 1, wantWrites: 1, wantRestored: true},
+		{name: "restores anyway", policy: config.DriftRestore, transactionID: "tx-1", current: changed, wantStatus: "success", wantDrift: models.DriftRestored, wantReads: 1, wantWrites: 1, wantRestored: true},
+		{name: "skips the restore", policy: config.DriftSkip, transactionID: "tx-1", current: changed, wantStatus: "success", wantDrift: models.DriftSkipped, wantReads: 1, wantRestored: true},
+		{name: "fails on drift", policy: config.DriftFail, transactionID: "tx-1", current: changed, wantStatus: "failed", wantCode: string(models.ErrorCodeDriftDetected), wantDrift: models.DriftFailed, wantReads: 1},
+		{name: "does not check the snapshot of another transaction", policy: config.DriftFail, transactionID: "tx-2", current: changed, wantStatus: "success", wantWrites: 1, wantRestored: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &ignoringClient{config: tt.current}
+			db := &restoreDatabase{}
+			w := &ActuationWorker{database: db, deviceClient: client, config: config.PowerSaving{DriftPolicy: tt.policy}}
+
+			status := w.restoreDevice(context.Background(), device, nai, tt.transactionID, snapshot)
+
+			assert.Equal(t, tt.wantStatus, status.Status)
+			assert.Equal(t, tt.wantCode, status.ErrorCode)
+			assert.Equal(t, string(tt.wantDrift), status.Drift)
+			assert.Equal(t, tt.wantReads, client.reads)
+			assert.Equal(t, tt.wantWrites, client.writes)
+			assert.Equal(t, tt.wantRestored, len(db.restored) == 1)
+		})
+	}
+}
+
+// leaseDatabase keeps the leases in memory.
+type leaseDatabase struct {
+	database.Interface
+	mu      sync.Mutex
+	holders map[string]string
+}
+
+func (d *leaseDatabase) AcquireLease(ctx context.Context, name string, holder string, duration time.Duration) (*database.Lease, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if current, ok := d.holders[name]; ok && current != holder {
+		return nil, database.ErrLeaseHeld
+	}
+	d.holders[name] = holder
+	return &database.Lease{Name: name, Holder: holder, ExpiresAt: time.Now().Add(duration)}, nil
+}
+
+func (d *leaseDatabase) ReleaseLease(ctx context.Context, name string, holder string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.holders[name] == holder {
+		delete(d.holders, name)
+	}
+	return nil
+}
+
+func TestReserveDevice(t *testing.T) {
+	db := &leaseDatabase{holders: map[string]string{}}
+	w := New(db, nil, nil, nil, config.PowerSaving{}, config.EasyAPI{}, Limits{MaxConcurrency: 2, DeviceLeaseDuration: time.Minute, DeviceWait: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	_, release, err := w.reserveDevice(ctx, "device1@example.com")
+	require.NoError(t, err)
+
+	// Another actuation of the same device fails once it waited for the device, without being deferred
+	_, _, err = w.reserveDevice(ctx, "device1@example.com")
+	assert.ErrorIs(t, err, errDeviceBusy)
+	assert.NotErrorIs(t, err, receiver.ErrDeferred)
+
+	// A request cancelled while it waits is deferred
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, _, err = w.reserveDevice(timeoutCtx, "device1@example.com")
+	assert.ErrorIs(t, err, receiver.ErrDeferred)
+
+	// Other devices are actuated up to the maximum concurrency
+	_, releaseOther, err := w.reserveDevice(ctx, "device2@example.com")
+	require.NoError(t, err)
+	_, _, err = w.reserveDevice(timeoutCtx, "device3@example.com")
+	assert.ErrorIs(t, err, receiver.ErrDeferred)
+
+	// Releasing the device frees it and its slot
+	release()
+	releaseOther()
+	assert.Empty(t, db.holders)
+	_, release, err = w.reserveDevice(ctx, "device1@example.com")
+	require.NoError(t, err)
+	release()
+}
+
+func TestReserveDeviceWaitsForRelease(t *testing.T) {
+	db := &leaseDatabase{holders: map[string]string{}}
+	w := New(db, nil, nil, nil, config.PowerSaving{}, config.EasyAPI{}, Limits{MaxConcurrency: 2, DeviceLeaseDuration: time.Minute, DeviceWait: 5 * time.Second})
+	ctx := context.Background()
+
+	_, release, err := w.reserveDevice(ctx, "device1@example.com")
+	require.NoError(t, err)
+	time.AfterFunc(20*time.Millisecond, release)
+
+	_, release, err = w.reserveDevice(ctx, "device1@example.com")
+	require.NoError(t, err)
+	release()
+}
+
+func TestLostDeviceLeaseCancelsActuation(t *testing.T) {
+	db := &leaseDatabase{holders: map[string]string{}}
+	w := New(db, nil, nil, nil, config.PowerSaving{}, config.EasyAPI{}, Limits{MaxConcurrency: 2, DeviceLeaseDuration: 30 * time.Millisecond})
+
+	reserved, release, err := w.reserveDevice(context.Background(), "device1@example.com")
+	require.NoError(t, err)
+	defer release()
+
+	// Another replica takes the device over
+	db.mu.Lock()
+	db.holders[deviceLeaseName("device1@example.com")] = "other"
+	db.mu.Unlock()
+
+	select {
+	case <-reserved.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("actuation not cancelled after its device lease was lost")
+	}
+}
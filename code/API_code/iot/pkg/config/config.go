@@ -16,6 +16,7 @@ limitations under the License.
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync"
 
@@ -34,6 +35,8 @@ type API struct {
 type Database struct {
 	Uri  string `split_words:"true" default:"mongodb://localhost:27017"`
 	Name string `split_words:"true" default:"iot"`
+	// LegacyOwner is assigned to the transactions stored before they were scoped to an owner.
+	LegacyOwner string `split_words:"true" default:""`
 }
 
 // HTTP client configuration
@@ -43,11 +46,97 @@ type HTTP struct {
 
 type EasyAPI struct {
 	BaseURL string `split_words:"true" default:""`
+	// RetryMaxAttempts is how many times a failed EasyAPI call is made in total.
+	RetryMaxAttempts int `split_words:"true" default:"4"`
+	// RetryBaseDelay is the backoff before the first retry, doubled for each following one.
+	RetryBaseDelay string `split_words:"true" default:"200ms"`
+	// RetryMaxDelay caps the backoff between two retries.
+	RetryMaxDelay string `split_words:"true" default:"10s"`
+	// BreakerFailureThreshold is the number of consecutive failed calls opening the circuit breaker, 0 to disable.
+	BreakerFailureThreshold int `split_words:"true" default:"5"`
+	// BreakerErrorRate is the share of failed calls in a window opening the circuit breaker, 0 to disable.
+	BreakerErrorRate float64 `split_words:"true" default:"0.5"`
+	// BreakerMinRequests is the number of calls in a window before the error rate applies.
+	BreakerMinRequests int `split_words:"true" default:"20"`
+	// BreakerWindow is the period over which the error rate is measured.
+	BreakerWindow string `split_words:"true" default:"1m"`
+	// BreakerOpenDuration is how long the circuit breaker fails fast before probing the backend.
+	BreakerOpenDuration string `split_words:"true" default:"30s"`
+	// BreakerRequeue leaves the device actions pending while the circuit breaker is open, instead of failing them.
+	BreakerRequeue bool `split_words:"true" default:"false"`
+	// Verify reads the configuration of a device back after changing it, failing the action on mismatch.
+	Verify bool `split_words:"true" default:"false"`
 }
 
 type PowerSaving struct {
 	MaxLatency      string `split_words:"true" default:"1"`
 	MaxResponseTime string `split_words:"true" default:"1"`
+	// Profiles are the named power-saving levels a request can select.
+	Profiles Profiles `split_words:"true" default:""`
+	// DefaultProfile is applied to requests without a profile. When empty, they get MaxLatency and MaxResponseTime.
+	DefaultProfile string `split_words:"true" default:""`
+	// DriftPolicy is what a restore does when the configuration of the device changed since power-saving was applied.
+	DriftPolicy string `split_words:"true" default:"restore"`
+}
+
+// Drift policies, applied when the configuration of a device changed outside the service before its restore.
+const (
+	// DriftRestore restores the original configuration anyway.
+	DriftRestore = "restore"
+	// DriftSkip leaves the configuration of the device unchanged.
+	DriftSkip = "skip"
+	// DriftFail fails the device action with the DRIFT_DETECTED reason.
+	DriftFail = "fail"
+)
+
+// Profile is a power-saving level applied to the devices.
+type Profile struct {
+	MaxLatency      string `bson:"maxLatency" json:"maxLatency"`
+	MaxResponseTime string `bson:"maxResponseTime" json:"maxResponseTime"`
+}
+
+// Profiles maps profile names to their settings. It is read as a JSON object, e.g.
+// {"light":{"maxLatency":"10","maxResponseTime":"10"},"deep":{"maxLatency":"60","maxResponseTime":"60"}}.
+type Profiles map[string]Profile
+
+// Decode implements envconfig.Decoder.
+func (p *Profiles) Decode(value string) error {
+	return json.Unmarshal([]byte(value), (*map[string]Profile)(p))
+}
+
+// Profile returns the settings of the named profile. An empty name selects the default profile.
+func (p PowerSaving) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = p.DefaultProfile
+	}
+	if name == "" {
+		return Profile{MaxLatency: p.MaxLatency, MaxResponseTime: p.MaxResponseTime}, nil
+	}
+	profile, ok := p.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown power-saving profile %q", name)
+	}
+	return profile, nil
+}
+
+// CheckDriftPolicy returns an error when the drift policy is unknown.
+func (p PowerSaving) CheckDriftPolicy() error {
+	switch p.DriftPolicy {
+	case DriftRestore, DriftSkip, DriftFail:
+		return nil
+	default:
+		return fmt.Errorf("unknown drift policy %q", p.DriftPolicy)
+	}
+}
+
+// Worker configures how the actuation worker shares the devices with other worker replicas.
+type Worker struct {
+	// MaxConcurrency caps the device actuations processed at once by a replica.
+	MaxConcurrency int `split_words:"true" default:"10"`
+	// DeviceLeaseDuration is how long a device is reserved to the replica actuating it.
+	DeviceLeaseDuration string `split_words:"true" default:"2m"`
+	// DeviceWait is how long an actuation waits for a device reserved by another actuation.
+	DeviceWait string `split_words:"true" default:"1m"`
 }
 
 type Retention struct {
@@ -57,13 +146,82 @@ type Retention struct {
 	CleanupInterval string `split_words:"true" default:"1h"`
 }
 
+// Scheduler configures how scheduler replicas share the scheduled actions.
+type Scheduler struct {
+	// PollInterval is how often a replica looks for due actions.
+	PollInterval string `split_words:"true" default:"1s"`
+	// LeaseDuration is how long a claimed action is reserved to a replica before others may fire it.
+	LeaseDuration string `split_words:"true" default:"1m"`
+	// LeaderLeaseDuration is how long the leader keeps its role without renewing it.
+	LeaderLeaseDuration string `split_words:"true" default:"15s"`
+	// LeaderRenewInterval is how often replicas renew or try to acquire the leader lease.
+	LeaderRenewInterval string `split_words:"true" default:"5s"`
+	// FanoutRate caps the device actuation requests published per second, shared between the replicas,
+	// 0 for no cap.
+	FanoutRate float64 `split_words:"true" default:"0"`
+	// FanoutJitter spreads the device actuation requests of a transaction randomly over this window.
+	FanoutJitter string `split_words:"true" default:"0s"`
+	// FanoutBackendRates caps the device actuation requests published per second for each backend,
+	// identified by the realm of the device network access identifier, shared between the replicas.
+	FanoutBackendRates Rates `split_words:"true" default:""`
+	// WatchdogInterval is how often the leader looks for device actions left pending or in progress.
+	WatchdogInterval string `split_words:"true" default:"1m"`
+	// ActionTimeout is how long a device action may stay pending or in progress before it is published again.
+	ActionTimeout string `split_words:"true" default:"5m"`
+	// ActionMaxAttempts is how many times a device action is published before it is marked as failed.
+	ActionMaxAttempts int `split_words:"true" default:"3"`
+}
+
+// Rates maps names to events per second. It is read as a JSON object, e.g. {"example.com":50}.
+type Rates map[string]float64
+
+// Decode implements envconfig.Decoder.
+func (r *Rates) Decode(value string) error {
+	return json.Unmarshal([]byte(value), (*map[string]float64)(r))
+}
+
+// Metrics configures the HTTP endpoint exposing the service metrics.
+type Metrics struct {
+	// Address is the listen address of the metrics endpoint, which is disabled when empty.
+	Address string `split_words:"true" default:""`
+}
+
+// Auth configures the verification of the bearer tokens presented to the API.
+type Auth struct {
+	// JwksFile is a local JSON Web Key Set; it takes precedence over JwksUrl.
+	JwksFile string `split_words:"true" default:""`
+	// JwksUrl is fetched to obtain the JSON Web Key Set.
+	JwksUrl string `split_words:"true" default:""`
+	// JwksRefreshInterval is how often the key set is reloaded to pick up rotated keys.
+	JwksRefreshInterval string `split_words:"true" default:"1h"`
+	// Issuer and Audience are checked against the iss and aud claims when set.
+	Issuer   string `split_words:"true" default:""`
+	Audience string `split_words:"true" default:""`
+}
+
+type Idempotency struct {
+	// KeyTTL is how long an Idempotency-Key is remembered.
+	KeyTTL string `split_words:"true" default:"24h"`
+	// KeyLease is how long an Idempotency-Key is held by a request that has not been accepted.
+	KeyLease string `split_words:"true" default:"1m"`
+	// EventTTL is how long the services remember a processed CloudEvent, to acknowledge its redeliveries.
+	EventTTL string `split_words:"true" default:"24h"`
+	// EventLease is how long a CloudEvent is held by a delivery that has not completed.
+	EventLease string `split_words:"true" default:"5m"`
+}
+
 type Config struct {
 	API
+	Auth
 	Database
 	EasyAPI
 	HTTP
 	PowerSaving
 	Retention
+	Scheduler
+	Worker
+	Idempotency
+	Metrics
 	Log
 }
 
@@ -77,6 +235,9 @@ func GetConf() Config {
 	var api API
 	process("api", &api)
 
+	var auth Auth
+	process("auth", &auth)
+
 	var db Database
 	process("db", &db)
 
@@ -89,13 +250,25 @@ func GetConf() Config {
 	var retention Retention
 	process("retention", &retention)
 
+	var scheduler Scheduler
+	process("scheduler", &scheduler)
+
+	var worker Worker
+	process("worker", &worker)
+
+	var idempotency Idempotency
+	process("idempotency", &idempotency)
+
+	var metrics Metrics
+	process("metrics", &metrics)
+
 	var log Log
 	process("log", &log)
 
 	var http HTTP
 	process("http", &http)
 
-	return Config{api, db, easyAPI, http, powerSaving, retention, log}
+	return Config{api, auth, db, easyAPI, http, powerSaving, retention, scheduler, worker, idempotency, metrics, log}
 }
 
 var (
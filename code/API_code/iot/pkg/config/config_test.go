@@ -59,3 +59,41 @@ func TestGetLogConfig(t *testing.T) {
 		assert.Equal(t, "development", res.Format)
 	})
 }
+
+func TestPowerSavingProfiles(t *testing.T) {
+	t.Run("correctly parse power-saving profiles", func(t *testing.T) {
+		t.Setenv("POWERSAVING_PROFILES", `{"light":{"maxLatency":"10","maxResponseTime":"20"},"deep":{"maxLatency":"60","maxResponseTime":"120"}}`)
+		t.Setenv("POWERSAVING_DEFAULT_PROFILE", "light")
+		res := GetConf().PowerSaving
+		assert.Len(t, res.Profiles, 2)
+		assert.Equal(t, "light", res.DefaultProfile)
+
+		profile, err := res.Profile("deep")
+		assert.NoError(t, err)
+		assert.Equal(t, Profile{MaxLatency: "60", MaxResponseTime: "120"}, profile)
+
+		profile, err = res.Profile("")
+		assert.NoError(t, err)
+		assert.Equal(t, Profile{MaxLatency: "10", MaxResponseTime: "20"}, profile)
+
+		_, err = res.Profile("ultra")
+		assert.Error(t, err)
+	})
+	t.Run("check the drift policy", func(t *testing.T) {
+		res := GetConf().PowerSaving
+		assert.Equal(t, DriftRestore, res.DriftPolicy)
+		assert.NoError(t, res.CheckDriftPolicy())
+
+		t.Setenv("POWERSAVING_DRIFT_POLICY", "ignore")
+		assert.Error(t, GetConf().PowerSaving.CheckDriftPolicy())
+	})
+	t.Run("fall back to the global settings without a default profile", func(t *testing.T) {
+		t.Setenv("POWERSAVING_MAX_LATENCY", "5")
+		t.Setenv("POWERSAVING_MAX_RESPONSE_TIME", "6")
+		res := GetConf().PowerSaving
+
+		profile, err := res.Profile("")
+		assert.NoError(t, err)
+		assert.Equal(t, Profile{MaxLatency: "5", MaxResponseTime: "6"}, profile)
+	})
+}
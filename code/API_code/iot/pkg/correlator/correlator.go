@@ -0,0 +1,63 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package correlator
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// The x-correlator of a consumer request is carried across the API, the event pipeline
+// and the callback notifications so that a single request can be traced end to end.
+const (
+	// Header is the HTTP header carrying the correlator on requests, responses and callbacks.
+	Header = "x-correlator"
+	// Extension is the CloudEvents extension attribute carrying the correlator between services.
+	Extension = "xcorrelator"
+)
+
+// pattern is the XCorrelator pattern of the OpenAPI specification.
+var pattern = regexp.MustCompile(`^[a-zA-Z0-9_:;./<>{}-]{0,256}$`)
+
+type ctxKey struct{}
+
+// New generates a correlator for requests that do not provide one.
+func New() string {
+	return uuid.New().String()
+}
+
+// Valid reports whether a correlator provided by a consumer matches the pattern of the OpenAPI specification.
+func Valid(correlator string) bool {
+	return pattern.MatchString(correlator)
+}
+
+// NewContext returns a copy of ctx carrying the correlator. An empty correlator leaves ctx unchanged.
+func NewContext(ctx context.Context, correlator string) context.Context {
+	if correlator == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKey{}, correlator)
+}
+
+// FromContext returns the correlator carried by ctx, or an empty string.
+func FromContext(ctx context.Context) string {
+	if c, ok := ctx.Value(ctxKey{}).(string); ok {
+		return c
+	}
+	return ""
+}
@@ -0,0 +1,231 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package easyapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// ErrCircuitOpen is returned without calling the backend while the circuit breaker is open.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrBackendUnavailable)
+
+var _ Client = &Breaker{}
+
+// BreakerPolicy configures when a Breaker stops calling the backend.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit, 0 to disable.
+	FailureThreshold int
+	// ErrorRate is the share of failed calls in a window opening the circuit, 0 to disable.
+	// It applies once MinRequests calls have been made in the window.
+	ErrorRate   float64
+	MinRequests int
+	Window      time.Duration
+	// OpenDuration is how long the circuit stays open before a probe call is let through.
+	OpenDuration time.Duration
+}
+
+// Enabled reports whether the policy can open the circuit.
+func (p BreakerPolicy) Enabled() bool {
+	return p.FailureThreshold > 0 || p.ErrorRate > 0
+}
+
+type breakerState string
+
+const (
+	breakerClosed   breakerState = "closed"
+	breakerOpen     breakerState = "open"
+	breakerHalfOpen breakerState = "half-open"
+)
+
+// Breaker is a Client failing fast with ErrCircuitOpen while the backend is unavailable. Only unavailability
+// counts as a failure: an unknown device or a rejected request shows that the backend is up.
+//
+// The circuit opens on consecutive failures or on a high error rate. Once OpenDuration has passed, a single
+// probe call is let through: the circuit closes when it succeeds and opens again when it fails.
+type Breaker struct {
+	client Client
+	policy BreakerPolicy
+	now    func() time.Time
+
+	mu          sync.Mutex
+	state       breakerState
+	openedAt    time.Time
+	probing     bool
+	consecutive int
+	windowStart time.Time
+	requests    int
+	failures    int
+}
+
+// NewBreaker wraps client with a circuit breaker.
+func NewBreaker(client Client, policy BreakerPolicy) *Breaker {
+	return &Breaker{
+		client: client,
+		policy: policy,
+		now:    time.Now,
+		state:  breakerClosed,
+	}
+}
+
+// GetDeviceConfig retrieves the configuration of the device unless the circuit is open.
+func (b *Breaker) GetDeviceConfig(ctx context.Context, device models.Device) (*DeviceConfig, error) {
+	if err := b.allow(ctx); err != nil {
+		return nil, err
+	}
+	config, err := b.client.GetDeviceConfig(ctx, device)
+	b.record(ctx, err)
+	return config, err
+}
+
+// SetDeviceConfig applies the configuration to the device unless the circuit is open.
+func (b *Breaker) SetDeviceConfig(ctx context.Context, device models.Device, config *DeviceConfig) error {
+	if err := b.allow(ctx); err != nil {
+		return err
+	}
+	err := b.client.SetDeviceConfig(ctx, device, config)
+	b.record(ctx, err)
+	return err
+}
+
+// allow returns ErrCircuitOpen when a call must not reach the backend. Once the circuit has been open
+// for OpenDuration, the first call is let through as the probe.
+func (b *Breaker) allow(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.policy.OpenDuration {
+			return ErrCircuitOpen
+		}
+		b.setState(ctx, breakerHalfOpen)
+		b.probing = true
+		return nil
+	case breakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record updates the circuit with the result of a call.
+func (b *Breaker) record(ctx context.Context, err error) {
+	failed := unavailable(ctx, err)
+	if err != nil && !failed && ctx.Err() != nil {
+		// The caller gave up: the call says nothing about the backend, but a probe must be let through again
+		b.mu.Lock()
+		b.probing = false
+		b.mu.Unlock()
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.probing = false
+		if failed {
+			b.open(ctx)
+		} else {
+			b.setState(ctx, breakerClosed)
+			b.reset()
+		}
+		return
+	}
+	if b.state != breakerClosed {
+		return
+	}
+
+	now := b.now()
+	if now.Sub(b.windowStart) >= b.policy.Window {
+		b.windowStart = now
+		b.requests = 0
+		b.failures = 0
+	}
+	b.requests++
+	if !failed {
+		b.consecutive = 0
+		return
+	}
+	b.failures++
+	b.consecutive++
+
+	switch {
+	case b.policy.FailureThreshold > 0 && b.consecutive >= b.policy.FailureThreshold:
+		b.open(ctx)
+	case b.policy.ErrorRate > 0 && b.requests >= b.policy.MinRequests &&
+		float64(b.failures) >= b.policy.ErrorRate*float64(b.requests):
+		b.open(ctx)
+	}
+}
+
+// open opens the circuit from now on.
+func (b *Breaker) open(ctx context.Context) {
+	b.setState(ctx, breakerOpen)
+	b.openedAt = b.now()
+	b.reset()
+}
+
+// reset clears the failure counts.
+func (b *Breaker) reset() {
+	b.consecutive = 0
+	b.windowStart = b.now()
+	b.requests = 0
+	b.failures = 0
+}
+
+// setState changes the state of the circuit, logging the change.
+func (b *Breaker) setState(ctx context.Context, state breakerState) {
+	if b.state == state {
+		return
+	}
+	log := logger.FromContext(ctx)
+	fields := []zap.Field{zap.String("from", string(b.state)), zap.String("to", string(state))}
+	if state == breakerOpen {
+		log.Warn("EasyAPI circuit breaker opened", append(fields,
+			zap.Int("consecutiveFailures", b.consecutive),
+			zap.Int("failures", b.failures),
+			zap.Int("requests", b.requests))...)
+	} else {
+		log.Info("EasyAPI circuit breaker state changed", fields...)
+	}
+	b.state = state
+}
+
+// unavailable reports whether a call failed because the backend is unavailable.
+func unavailable(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrBackendUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		// A deadline of the caller passing while waiting for the backend still counts as a failure
+		return ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded)
+	}
+	return false
+}
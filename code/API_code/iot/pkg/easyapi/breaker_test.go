@@ -0,0 +1,133 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package easyapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+)
+
+// stubClient answers every call with err and counts the calls.
+type stubClient struct {
+	err   error
+	calls int
+}
+
+func (c *stubClient) GetDeviceConfig(ctx context.Context, device models.Device) (*DeviceConfig, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &DeviceConfig{}, nil
+}
+
+func (c *stubClient) SetDeviceConfig(ctx context.Context, device models.Device, config *DeviceConfig) error {
+	c.calls++
+	return c.err
+}
+
+func newTestBreaker(client Client, policy BreakerPolicy) (*Breaker, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker(client, policy)
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+func TestBreakerOpensOnConsecutiveFailures(t *testing.T) {
+	client := &stubClient{err: ErrBackendUnavailable}
+	breaker, now := newTestBreaker(client, BreakerPolicy{FailureThreshold: 3, OpenDuration: 30 * time.Second, Window: time.Minute})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, breaker.SetDeviceConfig(ctx, testDevice(), &DeviceConfig{}), ErrBackendUnavailable)
+	}
+	assert.Equal(t, 3, client.calls)
+
+	// Open: fails fast without calling the backend
+	err := breaker.SetDeviceConfig(ctx, testDevice(), &DeviceConfig{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.ErrorIs(t, err, ErrBackendUnavailable)
+	assert.Equal(t, 3, client.calls)
+
+	// Half-open: a failed probe opens the circuit again
+	*now = now.Add(30 * time.Second)
+	assert.NotErrorIs(t, breaker.SetDeviceConfig(ctx, testDevice(), &DeviceConfig{}), ErrCircuitOpen)
+	assert.Equal(t, 4, client.calls)
+	assert.ErrorIs(t, breaker.SetDeviceConfig(ctx, testDevice(), &DeviceConfig{}), ErrCircuitOpen)
+
+	// Half-open: a successful probe closes the circuit
+	*now = now.Add(30 * time.Second)
+	client.err = nil
+	assert.NoError(t, breaker.SetDeviceConfig(ctx, testDevice(), &DeviceConfig{}))
+	assert.NoError(t, breaker.SetDeviceConfig(ctx, testDevice(), &DeviceConfig{}))
+	assert.Equal(t, 6, client.calls)
+}
+
+func TestBreakerOpensOnErrorRate(t *testing.T) {
+	client := &stubClient{}
+	breaker, now := newTestBreaker(client, BreakerPolicy{ErrorRate: 0.5, MinRequests: 4, Window: time.Minute, OpenDuration: time.Minute})
+	ctx := context.Background()
+
+	// Failures alternating with successes never reach a consecutive threshold
+	for i := 0; i < 3; i++ {
+		client.err = nil
+		if i%2 == 1 {
+			client.err = ErrBackendUnavailable
+		}
+		_, _ = breaker.GetDeviceConfig(ctx, testDevice())
+	}
+	assert.Equal(t, breakerClosed, breaker.state)
+
+	// A new window starts the count again
+	*now = now.Add(time.Minute)
+	client.err = ErrBackendUnavailable
+	_, _ = breaker.GetDeviceConfig(ctx, testDevice())
+	client.err = nil
+	_, _ = breaker.GetDeviceConfig(ctx, testDevice())
+	_, _ = breaker.GetDeviceConfig(ctx, testDevice())
+	assert.Equal(t, breakerClosed, breaker.state)
+
+	client.err = ErrBackendUnavailable
+	_, _ = breaker.GetDeviceConfig(ctx, testDevice())
+	assert.Equal(t, breakerOpen, breaker.state)
+}
+
+func TestBreakerIgnoresDeviceErrors(t *testing.T) {
+	client := &stubClient{err: ErrDeviceNotFound}
+	breaker, _ := newTestBreaker(client, BreakerPolicy{FailureThreshold: 1, OpenDuration: time.Minute, Window: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		_, err := breaker.GetDeviceConfig(context.Background(), testDevice())
+		assert.ErrorIs(t, err, ErrDeviceNotFound)
+	}
+	assert.Equal(t, 3, client.calls)
+}
+
+func TestBreakerIgnoresCancelledCallers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := &stubClient{err: errors.Join(ErrBackendUnavailable, context.Canceled)}
+	breaker, _ := newTestBreaker(client, BreakerPolicy{FailureThreshold: 1, OpenDuration: time.Minute, Window: time.Minute})
+
+	_, _ = breaker.GetDeviceConfig(ctx, testDevice())
+	assert.Equal(t, breakerClosed, breaker.state)
+}
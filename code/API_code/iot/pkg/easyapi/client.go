@@ -37,15 +37,17 @@ var _ Client = &EasyApiClient{}
 type EasyApiClient struct {
 	baseURL    string
 	httpClient *http.Client
+	retry      RetryPolicy
 }
 
-// New creates a new EasyAPI client.
-func New(baseURL string) *EasyApiClient {
+// New creates a new EasyAPI client, retrying failed calls according to retry.
+func New(baseURL string, retry RetryPolicy) *EasyApiClient {
 	return &EasyApiClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		retry: retry,
 	}
 }
 
@@ -57,7 +59,7 @@ type AccessAndMobilitySubscriptionData struct {
 
 // GetDeviceConfig retrieves device configuration via GET /nudm-sdm/v2/{supi}/am-data.
 func (c *EasyApiClient) GetDeviceConfig(ctx context.Context, device models.Device) (*DeviceConfig, error) {
-	log := logger.Get()
+	log := logger.FromContext(ctx)
 
 	if device.NetworkAccessIdentifier == nil {
 		return nil, fmt.Errorf("networkAccessIdentifier is required")
@@ -70,39 +72,12 @@ func (c *EasyApiClient) GetDeviceConfig(ctx context.Context, device models.Devic
 		zap.String("url", url),
 		zap.String("supi", supi))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	body, err := c.do(ctx, http.MethodGet, url, nil, http.StatusOK)
 	if err != nil {
-		log.Error("Failed to create HTTP request",
+		log.Error("Failed to get device AM data",
 			zap.String("supi", supi),
 			zap.Error(err))
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		log.Error("Failed to execute HTTP request",
-			zap.String("supi", supi),
-			zap.Error(err))
-		return nil, fmt.Errorf("execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Error("Failed to read response body",
-			zap.String("supi", supi),
-			zap.Error(err))
-		return nil, fmt.Errorf("read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		log.Error("EasyAPI returned non-200 status",
-			zap.String("supi", supi),
-			zap.Int("statusCode", resp.StatusCode),
-			zap.String("body", string(body)))
-		return nil, fmt.Errorf("EasyAPI error: status %d", resp.StatusCode)
+		return nil, err
 	}
 
 	var amData AccessAndMobilitySubscriptionData
@@ -162,7 +137,7 @@ type CommunicationCharacteristicsUpdate struct {
 
 // SetDeviceConfig updates device configuration via PATCH /nudm-pp/v1/{ueId}/pp-data.
 func (c *EasyApiClient) SetDeviceConfig(ctx context.Context, device models.Device, config *DeviceConfig) error {
-	log := logger.Get()
+	log := logger.FromContext(ctx)
 
 	if device.NetworkAccessIdentifier == nil {
 		return fmt.Errorf("networkAccessIdentifier is required")
@@ -193,44 +168,98 @@ func (c *EasyApiClient) SetDeviceConfig(ctx context.Context, device models.Devic
 		return fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		log.Error("Failed to create HTTP request",
+	// The update sets absolute values, so making it again is safe
+	if _, err := c.do(ctx, http.MethodPatch, url, bodyBytes, http.StatusNoContent); err != nil {
+		log.Error("Failed to update device PP data",
 			zap.String("ueId", ueId),
 			zap.Error(err))
-		return fmt.Errorf("create request: %w", err)
+		return err
+	}
+
+	log.Info("EasyAPI: Device configuration updated successfully",
+		zap.String("ueId", ueId))
+
+	return nil
+}
+
+// do makes an idempotent request, made again on transport errors, timeouts, 429 and 5xx answers as allowed
+// by the retry policy, and returns the body of the answer when it has the expected status.
+func (c *EasyApiClient) do(ctx context.Context, method string, url string, body []byte, expected int) ([]byte, error) {
+	log := logger.FromContext(ctx)
+
+	for attempt := 1; ; attempt++ {
+		respBody, err := c.send(ctx, method, url, body, expected)
+		if err == nil {
+			return respBody, nil
+		}
+		if attempt >= c.retry.MaxAttempts || !retryable(ctx, err) {
+			return nil, err
+		}
+
+		delay, ok := c.retry.backoff(attempt, err)
+		if !ok {
+			log.Warn("EasyAPI request failed, backend asks to retry later than allowed",
+				zap.String("method", method),
+				zap.String("url", url),
+				zap.Int("attempt", attempt),
+				zap.Duration("retryAfter", delay),
+				zap.Duration("maxDelay", c.retry.MaxDelay),
+				zap.Error(err))
+			return nil, err
+		}
+		log.Warn("EasyAPI request failed, retrying",
+			zap.String("method", method),
+			zap.String("url", url),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+		if !sleep(ctx, delay) {
+			// The caller's deadline leaves no time for another attempt
+			return nil, err
+		}
+	}
+}
+
+// send makes a single request and returns the body of the answer when it has the expected status.
+func (c *EasyApiClient) send(ctx context.Context, method string, url string, body []byte, expected int) ([]byte, error) {
+	log := logger.FromContext(ctx)
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
+	countAttempt(ctx)
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		log.Error("Failed to execute HTTP request",
-			zap.String("ueId", ueId),
-			zap.Error(err))
-		return fmt.Errorf("execute request: %w", err)
+		return nil, fmt.Errorf("%w: execute request: %w", ErrBackendUnavailable, err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Error("Failed to read response body",
-			zap.String("ueId", ueId),
-			zap.Error(err))
-		return fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("%w: read response: %w", ErrBackendUnavailable, err)
 	}
 
-	if resp.StatusCode != http.StatusNoContent {
-		log.Error("EasyAPI returned non-success status",
-			zap.String("ueId", ueId),
+	if resp.StatusCode != expected {
+		log.Error("EasyAPI returned unexpected status",
+			zap.String("method", method),
+			zap.String("url", url),
 			zap.Int("statusCode", resp.StatusCode),
-			zap.String("body", string(body)))
-		return fmt.Errorf("EasyAPI error: status %d", resp.StatusCode)
+			zap.String("body", string(respBody)))
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
-
-	log.Info("EasyAPI: Device configuration updated successfully",
-		zap.String("ueId", ueId))
-
-	return nil
+	return respBody, nil
 }
@@ -0,0 +1,165 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package easyapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+)
+
+var testPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func testDevice() models.Device {
+	nai := "device@example.com"
+	return models.Device{NetworkAccessIdentifier: &nai}
+}
+
+// statusServer answers with the given statuses in turn, then with 204, and counts the requests.
+func statusServer(t *testing.T, requests *atomic.Int32, header http.Header, statuses ...int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if n <= len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSetDeviceConfigRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int32
+		wantErr      error
+	}{
+		{name: "retries a 503", statuses: []int{http.StatusServiceUnavailable}, wantRequests: 2},
+		{name: "retries a 429", statuses: []int{http.StatusTooManyRequests, http.StatusBadGateway}, wantRequests: 3},
+		{name: "gives up after the max attempts", statuses: []int{500, 500, 500, 500}, wantRequests: 3, wantErr: ErrBackendUnavailable},
+		{name: "does not retry a 404", statuses: []int{http.StatusNotFound}, wantRequests: 1, wantErr: ErrDeviceNotFound},
+		{name: "does not retry a 400", statuses: []int{http.StatusBadRequest}, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := statusServer(t, &requests, nil, tt.statuses...)
+			client := New(server.URL, testPolicy)
+
+			ctx, attempts := WithAttemptCounter(context.Background())
+			err := client.SetDeviceConfig(ctx, testDevice(), &DeviceConfig{PpMaximumLatency: "2", PpMaximumResponseTime: "2"})
+
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantRequests > int32(len(tt.statuses)):
+				assert.NoError(t, err)
+			default:
+				var statusErr *StatusError
+				assert.ErrorAs(t, err, &statusErr)
+			}
+			assert.Equal(t, tt.wantRequests, requests.Load())
+			assert.Equal(t, int(tt.wantRequests), attempts.Count())
+		})
+	}
+}
+
+func TestRetryAfterIsHonoured(t *testing.T) {
+	var requests atomic.Int32
+	server := statusServer(t, &requests, http.Header{"Retry-After": {"1"}}, http.StatusServiceUnavailable)
+	client := New(server.URL, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second})
+
+	started := time.Now()
+	err := client.SetDeviceConfig(context.Background(), testDevice(), &DeviceConfig{PpMaximumLatency: "2", PpMaximumResponseTime: "2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.GreaterOrEqual(t, time.Since(started), time.Second)
+}
+
+func TestRetryAfterAboveMaxDelay(t *testing.T) {
+	var requests atomic.Int32
+	server := statusServer(t, &requests, http.Header{"Retry-After": {"10"}}, http.StatusServiceUnavailable)
+	client := New(server.URL, testPolicy)
+
+	started := time.Now()
+	err := client.SetDeviceConfig(context.Background(), testDevice(), &DeviceConfig{PpMaximumLatency: "2", PpMaximumResponseTime: "2"})
+
+	assert.ErrorIs(t, err, ErrBackendUnavailable)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestRetriesBoundedByDeadline(t *testing.T) {
+	var requests atomic.Int32
+	server := statusServer(t, &requests, http.Header{"Retry-After": {"10"}}, http.StatusServiceUnavailable)
+	client := New(server.URL, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	started := time.Now()
+	err := client.SetDeviceConfig(ctx, testDevice(), &DeviceConfig{PpMaximumLatency: "2", PpMaximumResponseTime: "2"})
+
+	assert.ErrorIs(t, err, ErrBackendUnavailable)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	for retry := 1; retry <= 5; retry++ {
+		delay, ok := policy.backoff(retry, errors.New("failed"))
+		assert.True(t, ok)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, 300*time.Millisecond)
+	}
+	delay, _ := policy.backoff(1, errors.New("failed"))
+	assert.Less(t, delay, 100*time.Millisecond)
+
+	delay, ok := policy.backoff(1, &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 200 * time.Millisecond})
+	assert.True(t, ok)
+	assert.Equal(t, 200*time.Millisecond, delay)
+
+	// A Retry-After above the maximum delay is not waited for
+	_, ok = policy.backoff(1, &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second})
+	assert.False(t, ok)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter("", now))
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 
@@ -29,16 +30,20 @@ import (
 var _ Client = &DummyClient{}
 
 // DummyClient is a mock implementation of Client for testing.
-type DummyClient struct{}
+// It remembers the configurations applied, so that they can be read back.
+type DummyClient struct {
+	mu      sync.Mutex
+	configs map[string]DeviceConfig
+}
 
 // NewDummy creates a new DummyClient.
 func NewDummy() *DummyClient {
-	return &DummyClient{}
+	return &DummyClient{configs: make(map[string]DeviceConfig)}
 }
 
 // GetDeviceConfig returns simulated device performance profile configuration.
 func (d *DummyClient) GetDeviceConfig(ctx context.Context, device models.Device) (*DeviceConfig, error) {
-	log := logger.Get()
+	log := logger.FromContext(ctx)
 
 	deviceID := getDeviceIdentifier(device)
 	log.Info("EASYAPI: Getting device configuration",
@@ -49,6 +54,11 @@ func (d *DummyClient) GetDeviceConfig(ctx context.Context, device models.Device)
 		PpMaximumLatency:      "100", // default non-power-saving value
 		PpMaximumResponseTime: "200", // default non-power-saving value
 	}
+	d.mu.Lock()
+	if applied, ok := d.configs[deviceID]; ok {
+		*config = applied
+	}
+	d.mu.Unlock()
 
 	log.Info("EASYAPI: Retrieved device configuration",
 		zap.String("deviceId", deviceID),
@@ -59,7 +69,7 @@ func (d *DummyClient) GetDeviceConfig(ctx context.Context, device models.Device)
 
 // SetDeviceConfig simulates applying performance profile configuration.
 func (d *DummyClient) SetDeviceConfig(ctx context.Context, device models.Device, config *DeviceConfig) error {
-	log := logger.Get()
+	log := logger.FromContext(ctx)
 
 	deviceID := getDeviceIdentifier(device)
 
@@ -69,6 +79,10 @@ func (d *DummyClient) SetDeviceConfig(ctx context.Context, device models.Device,
 		zap.String("ppMaximumResponseTime", config.PpMaximumResponseTime),
 		zap.Any("device", device))
 
+	d.mu.Lock()
+	d.configs[deviceID] = *config
+	d.mu.Unlock()
+
 	log.Info("EASYAPI: Successfully applied device configuration",
 		zap.String("deviceId", deviceID))
 
@@ -17,10 +17,18 @@ package easyapi
 
 import (
 	"context"
+	"errors"
 
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 )
 
+var (
+	// ErrDeviceNotFound is returned when the backend does not know the device.
+	ErrDeviceNotFound = errors.New("device not found")
+	// ErrBackendUnavailable is returned when the backend cannot be reached, times out or is overloaded.
+	ErrBackendUnavailable = errors.New("backend unavailable")
+)
+
 // DeviceConfig holds the device performance profile configuration.
 type DeviceConfig struct {
 	PpMaximumLatency      string `json:"ppMaximumLatency"`
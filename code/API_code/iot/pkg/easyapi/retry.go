@@ -0,0 +1,154 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package easyapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy configures the retries of the calls to the backend. Only idempotent calls are retried,
+// on transport errors, timeouts, 429 and 5xx answers, and never past the deadline of the caller's context.
+type RetryPolicy struct {
+	// MaxAttempts is the number of requests made for a call, including the first one.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles for each retry up to MaxDelay.
+	// The actual delay is drawn at random up to the backoff, and is at least the Retry-After of the answer.
+	// A call whose answer asks to retry after more than MaxDelay is not retried.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// StatusError is returned when the backend answers with an unexpected status. It wraps ErrDeviceNotFound
+// or ErrBackendUnavailable when the status tells so.
+type StatusError struct {
+	StatusCode int
+	// RetryAfter is the delay requested by the backend through the Retry-After header, if any.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if err := e.Unwrap(); err != nil {
+		return fmt.Sprintf("%v: EasyAPI error: status %d", err, e.StatusCode)
+	}
+	return fmt.Sprintf("EasyAPI error: status %d", e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrDeviceNotFound
+	case e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError:
+		return ErrBackendUnavailable
+	default:
+		return nil
+	}
+}
+
+// AttemptCounter counts the requests made to the backend, retries included, for the calls sharing a context.
+type AttemptCounter struct {
+	n atomic.Int32
+}
+
+type attemptCounterKey struct{}
+
+// WithAttemptCounter returns a context counting the requests made with it.
+func WithAttemptCounter(ctx context.Context) (context.Context, *AttemptCounter) {
+	counter := &AttemptCounter{}
+	return context.WithValue(ctx, attemptCounterKey{}, counter), counter
+}
+
+// Count returns the number of requests made.
+func (c *AttemptCounter) Count() int {
+	return int(c.n.Load())
+}
+
+// countAttempt records a request made with ctx.
+func countAttempt(ctx context.Context) {
+	if counter, ok := ctx.Value(attemptCounterKey{}).(*AttemptCounter); ok {
+		counter.n.Add(1)
+	}
+}
+
+// retryable reports whether a failed request may succeed when made again.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return errors.Is(statusErr, ErrBackendUnavailable)
+	}
+	return errors.Is(err, ErrBackendUnavailable)
+}
+
+// backoff returns the delay before the given retry, from 1: a random delay up to the exponential backoff,
+// and at least the delay requested by the backend. It returns false when the backend asks to wait for more
+// than MaxDelay.
+func (p RetryPolicy) backoff(retry int, err error) (time.Duration, bool) {
+	ceiling := p.BaseDelay << (retry - 1)
+	if ceiling > p.MaxDelay || ceiling <= 0 {
+		ceiling = p.MaxDelay
+	}
+	var delay time.Duration
+	if ceiling > 0 {
+		delay = rand.N(ceiling)
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+		if statusErr.RetryAfter > p.MaxDelay {
+			return statusErr.RetryAfter, false
+		}
+		delay = statusErr.RetryAfter
+	}
+	return delay, true
+}
+
+// parseRetryAfter reads a Retry-After header, given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// sleep waits for delay, or returns false when ctx is done or its deadline would pass before.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -19,12 +19,14 @@ import (
 	"time"
 
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
 )
 
 // Action constants for device actuation.
 const (
-	ActionStart = "start" // Trigger at schedule start
-	ActionEnd   = "end"   // Trigger at schedule end
+	ActionStart  = "start"  // Trigger at schedule start
+	ActionEnd    = "end"    // Trigger at schedule end
+	ActionCancel = "cancel" // Restore devices of a cancelled transaction
 )
 
 // ScheduleRequestedData is the payload for schedule.requested events.
@@ -40,6 +42,13 @@ type PowerSavingPayload struct {
 	Enabled             bool                       `json:"enabled"`
 	SubscriptionRequest models.SubscriptionRequest `json:"subscriptionRequest"`
 	TransactionID       string                     `json:"transactionId"`
+	Owner               string                     `json:"owner,omitempty"` // JWT sub of the API caller
+	Recurrence          *models.Recurrence         `json:"recurrence,omitempty"`
+	Profile             string                     `json:"profile,omitempty"` // power-saving profile, empty for the built-in settings
+	ProfileSettings     *config.Profile            `json:"profileSettings,omitempty"`
+	FanOut              *models.FanOut             `json:"fanOut,omitempty"`
+	Canary              *models.Canary             `json:"canary,omitempty"`
+	Atomic              bool                       `json:"atomic,omitempty"` // roll back all devices when START fails on any
 }
 
 // DeviceActuationRequestData is the payload for device.actuation.request events.
@@ -47,18 +56,35 @@ type DeviceActuationRequestData struct {
 	Device              models.Device              `json:"device"`
 	Enabled             bool                       `json:"enabled"`
 	TransactionID       string                     `json:"transactionId"`
-	Action              string                     `json:"action"` // "start" or "end" (use ActionStart/ActionEnd constants)
+	Action              string                     `json:"action"` // "start", "end" or "cancel" (use the Action constants)
 	SubscriptionRequest models.SubscriptionRequest `json:"subscriptionRequest"`
+	Profile             string                     `json:"profile,omitempty"`
+	// ProfileSettings are the settings of the profile when the transaction was requested. Without them, e.g. for
+	// a request published by an earlier version, the worker resolves the profile from its own configuration.
+	ProfileSettings *config.Profile `json:"profileSettings,omitempty"`
+	// Attempt numbers the requests published for the same device action, from 2 when published again
+	Attempt int `json:"attempt,omitempty"`
+}
+
+// CancelRequestedData is the payload for cancel.requested events.
+type CancelRequestedData struct {
+	TransactionID string `json:"transactionId"`
 }
 
 // AllDevicesCompletedData is the payload for all-devices.completed events.
 type AllDevicesCompletedData struct {
 	TransactionID       string                     `json:"transactionId"`
-	Action              string                     `json:"action"` // "start" or "end"
+	Action              string                     `json:"action"` // "start", "end" or "cancel"
 	CompletedAt         time.Time                  `json:"completedAt"`
 	SubscriptionRequest models.SubscriptionRequest `json:"subscriptionRequest"`
 }
 
+// CanaryCompletedData is the payload for canary.completed events.
+type CanaryCompletedData struct {
+	TransactionID string    `json:"transactionId"`
+	CompletedAt   time.Time `json:"completedAt"`
+}
+
 // ErrorNotificationData is the payload for power-saving.error events.
 type ErrorNotificationData struct {
 	TransactionID       string                     `json:"transactionId"`
@@ -18,11 +18,15 @@ package event
 import (
 	"context"
 	"net"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
 
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/internal/receiver"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/correlator"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
 )
 
 // Receiver starts an HTTP server that delivers incoming CloudEvents to a handler.
@@ -53,6 +57,75 @@ func NewReceiver(conf config.API) (Receiver, error) {
 }
 
 // Start runs the server and delivers events to fn until ctx is done.
+// The x-correlator extension of incoming events is made available through the handler context.
 func (r *eventReceiver) Start(handler receiver.Handler) error {
-	return r.client.StartReceiver(context.TODO(), handler.Handle)
+	return r.client.StartReceiver(context.TODO(), func(ctx context.Context, e cloudevents.Event) (*cloudevents.Event, error) {
+		if c, ok := e.Extensions()[correlator.Extension].(string); ok {
+			ctx = correlator.NewContext(ctx, c)
+		}
+		return handler.Handle(ctx, e)
+	})
+}
+
+// wrappedReceiver hands the events to the handler through a decorator.
+type wrappedReceiver struct {
+	Receiver
+	wrap func(receiver.Handler) receiver.Handler
+}
+
+// Start runs the wrapped receiver, passing the events through the decorator before they reach the handler.
+func (r *wrappedReceiver) Start(handler receiver.Handler) error {
+	return r.Receiver.Start(r.wrap(handler))
+}
+
+// FilterReplays returns a receiver acknowledging the dead letters replayed for other consumers without handing
+// them to the handler.
+func FilterReplays(r Receiver, consumer Source) Receiver {
+	replays := receiver.Replays{Consumer: consumer.String()}
+	return &wrappedReceiver{Receiver: r, wrap: replays.Handler}
+}
+
+// Deduplicate returns a receiver handing each event to the handler once for the consumer, as recorded in
+// the ledger. Redeliveries of a processed event are acknowledged without reaching the handler.
+func Deduplicate(r Receiver, ledger receiver.Ledger, consumer Source, conf config.Idempotency) Receiver {
+	log := logger.Get()
+
+	ttl, err := time.ParseDuration(conf.EventTTL)
+	if err != nil {
+		log.Warn("Invalid processed event TTL, using default 24h",
+			zap.String("configured", conf.EventTTL),
+			zap.Error(err))
+		ttl = 24 * time.Hour
+	}
+
+	lease, err := time.ParseDuration(conf.EventLease)
+	if err != nil {
+		log.Warn("Invalid processed event lease, using default 5m",
+			zap.String("configured", conf.EventLease),
+			zap.Error(err))
+		lease = 5 * time.Minute
+	}
+
+	log.Info("Event deduplication enabled",
+		zap.String("consumer", consumer.String()),
+		zap.Duration("ttl", ttl),
+		zap.Duration("lease", lease))
+
+	dedup := receiver.Dedup{
+		Ledger:   ledger,
+		Consumer: consumer.String(),
+		TTL:      ttl,
+		Lease:    lease,
+	}
+	return &wrappedReceiver{Receiver: r, wrap: dedup.Handler}
+}
+
+// WithDeadLetters returns a receiver recording the events that the handler fails to process as dead letters
+// of the consumer.
+func WithDeadLetters(r Receiver, store receiver.DeadLetterStore, consumer Source) Receiver {
+	deadLetters := receiver.DeadLetters{
+		Store:    store,
+		Consumer: consumer.String(),
+	}
+	return &wrappedReceiver{Receiver: r, wrap: deadLetters.Handler}
 }
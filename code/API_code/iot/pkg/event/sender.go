@@ -23,6 +23,7 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"go.uber.org/zap"
 
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/correlator"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
 )
 
@@ -40,6 +41,11 @@ func WithSubject(sub string) Option {
 	return func(e *cloudevents.Event) { e.SetSubject(sub) }
 }
 
+// WithCorrelator sets the x-correlator extension on the event.
+func WithCorrelator(c string) Option {
+	return func(e *cloudevents.Event) { e.SetExtension(correlator.Extension, c) }
+}
+
 // NewSender creates a Sender using the K_SINK environment variable.
 func NewSender() (Sender, error) {
 	target := os.Getenv("K_SINK")
@@ -54,7 +60,7 @@ func NewSender() (Sender, error) {
 }
 
 func (s *sender) Send(ctx context.Context, id string, eventType EventType, source Source, data any, opts ...Option) (err error) {
-	log := logger.Get()
+	log := logger.FromContext(ctx)
 	log.With(
 		zap.String("event-id", id),
 		zap.String("event-type", eventType.String()),
@@ -67,6 +73,11 @@ func (s *sender) Send(ctx context.Context, id string, eventType EventType, sourc
 		}
 	}()
 
+	// Propagate the consumer correlator to the next service
+	if c := correlator.FromContext(ctx); c != "" {
+		opts = append(opts, WithCorrelator(c))
+	}
+
 	e, err := Event(id, eventType, source, data, opts...)
 	if err != nil {
 		return nil
@@ -78,3 +89,34 @@ func (s *sender) Send(ctx context.Context, id string, eventType EventType, sourc
 	log.Debug("Cloud event sent")
 	return nil
 }
+
+// Replayer publishes stored events again.
+type Replayer interface {
+	Replay(ctx context.Context, e cloudevents.Event) error
+}
+
+// NewReplayer creates a Replayer using the K_SINK environment variable.
+func NewReplayer() (Replayer, error) {
+	s, err := NewSender()
+	if err != nil {
+		return nil, err
+	}
+	return s.(*sender), nil
+}
+
+// Replay sends an event to the broker unchanged, keeping its ID and source, so that the consumers that have
+// already processed it acknowledge it as a duplicate.
+func (s *sender) Replay(ctx context.Context, e cloudevents.Event) error {
+	log := logger.FromContext(ctx).With(
+		zap.String("event-id", e.ID()),
+		zap.String("event-type", e.Type()),
+		zap.String("source", e.Source()))
+	log.Debug("Replaying cloud event")
+
+	if res := s.client.Send(ctx, e); cloudevents.IsUndelivered(res) {
+		log.With(zap.Error(res)).Error("Replay cloud event failed")
+		return res
+	}
+	log.Debug("Cloud event replayed")
+	return nil
+}
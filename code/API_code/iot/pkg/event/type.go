@@ -27,6 +27,12 @@ const (
 	// EventTypeAllDevicesCompleted is sent when all devices for a transaction have completed.
 	EventTypeAllDevicesCompleted EventType = "it.tim.iot.all-devices.completed"
 
+	// EventTypeCanaryCompleted is sent by the Worker when the canary devices of a transaction have completed START.
+	EventTypeCanaryCompleted EventType = "it.tim.iot.canary.completed"
+
+	// EventTypeCancelRequested is sent by the API to cancel an existing transaction.
+	EventTypeCancelRequested EventType = "it.tim.iot.cancel.requested"
+
 	// EventTypePowerSavingError is sent when a system-level error prevents processing.
 	EventTypePowerSavingError EventType = "it.tim.iot.notify.error.requested"
 )
@@ -16,6 +16,7 @@ limitations under the License.
 package logger
 
 import (
+	"context"
 	"log"
 	"os"
 	"runtime/debug"
@@ -25,6 +26,7 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/config"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/correlator"
 )
 
 var once sync.Once
@@ -86,6 +88,14 @@ func Get() *zap.Logger {
 	return logger
 }
 
+// FromContext returns the logger enriched with the request-scoped fields carried by ctx.
+func FromContext(ctx context.Context) *zap.Logger {
+	if c := correlator.FromContext(ctx); c != "" {
+		return Get().With(zap.String("xCorrelator", c))
+	}
+	return Get()
+}
+
 func IsDebug() bool {
 	return config.GetLogConfig().Level == "debug"
 }
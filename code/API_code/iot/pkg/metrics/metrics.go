@@ -0,0 +1,39 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package metrics exposes the metrics of a service, published as expvar variables, over HTTP.
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+	"time"
+)
+
+// Path is where the metrics are served, as a JSON object.
+const Path = "/debug/vars"
+
+// Serve exposes the expvar variables on Path at address. It returns when the server fails.
+func Serve(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, expvar.Handler())
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return server.ListenAndServe()
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/correlator"
+)
+
+// Correlator returns an Echo middleware that propagates the x-correlator header.
+// The value provided by the consumer (or a generated one, when it is missing or does not match
+// the pattern of the specification) is echoed on the response and stored in the request context
+// for logging and event propagation.
+func Correlator() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().URL.Path == "/healthz" {
+				return next(c)
+			}
+			id := c.Request().Header.Get(correlator.Header)
+			if id == "" || !correlator.Valid(id) {
+				id = correlator.New()
+			}
+			c.Response().Header().Set(correlator.Header, id)
+			ctx := correlator.NewContext(c.Request().Context(), id)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
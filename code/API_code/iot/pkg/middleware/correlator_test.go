@@ -0,0 +1,77 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/correlator"
+)
+
+func TestCorrelator(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		wantHeader string
+	}{
+		{
+			name:       "echoes the correlator provided by the consumer",
+			header:     "b4333c46-49c0-4f62-80d7-f0ef930f1c46",
+			wantHeader: "b4333c46-49c0-4f62-80d7-f0ef930f1c46",
+		},
+		{
+			name: "generates a correlator when none is provided",
+		},
+		{
+			name:   "replaces a correlator not matching the pattern",
+			header: "not a correlator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			var gotCtx string
+			h := func(c echo.Context) error {
+				gotCtx = correlator.FromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/test", Correlator()(h))
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(correlator.Header, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(correlator.Header)
+			assert.NotEmpty(t, got)
+			if tt.wantHeader != "" {
+				assert.Equal(t, tt.wantHeader, got)
+			} else {
+				assert.NotEqual(t, tt.header, got)
+			}
+			assert.Equal(t, got, gotCtx)
+		})
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+// minRefetchInterval limits how often an unknown kid can trigger a reload of the key set.
+const minRefetchInterval = 30 * time.Second
+
+// JWKS is a KeySource backed by a JSON Web Key Set read from a local file or fetched from a URL.
+// The set is reloaded every refresh interval and when a token references an unknown key ID,
+// so that rotated keys are picked up without a restart. Concurrent requests share a single reload.
+type JWKS struct {
+	file            string
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+	reloads         singleflight.Group
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// retryAt delays the next reload after a failed one
+	retryAt time.Time
+}
+
+var _ KeySource = &JWKS{}
+
+// NewJWKS loads the key set from file, or from url when file is empty.
+func NewJWKS(file, url string, refreshInterval time.Duration) (*JWKS, error) {
+	if file == "" && url == "" {
+		return nil, errors.New("no JWKS file or URL configured")
+	}
+	j := &JWKS{
+		file:            file,
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+	if err := j.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Key implements KeySource. A token without kid is accepted when the set holds a single key.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.RLock()
+	key, ok := j.lookup(kid)
+	age := time.Since(j.fetchedAt)
+	backingOff := time.Now().Before(j.retryAt)
+	j.mu.RUnlock()
+
+	if ok && (age < j.refreshInterval || backingOff) {
+		return key, nil
+	}
+	if !ok && (age < minRefetchInterval || backingOff) {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+
+	// The reload is shared by the requests waiting for it, and must not fail when the first of them is cancelled.
+	// A request arriving just after a reload completed uses its keys.
+	_, err, _ := j.reloads.Do("jwks", func() (any, error) {
+		j.mu.RLock()
+		reloaded := time.Since(j.fetchedAt) < minRefetchInterval
+		j.mu.RUnlock()
+		if reloaded {
+			return nil, nil
+		}
+		return nil, j.refresh(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		// Keep serving the last known keys if the source is temporarily unavailable
+		logger.FromContext(ctx).Warn("Failed to refresh JWKS", zap.Error(err))
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// lookup must be called with j.mu held.
+func (j *JWKS) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKS) refresh(ctx context.Context) error {
+	keys, err := j.load(ctx)
+	if err != nil {
+		j.mu.Lock()
+		// Back off before the next attempt, whether the set could not be read or is invalid
+		j.retryAt = time.Now().Add(minRefetchInterval)
+		j.mu.Unlock()
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+
+	logger.FromContext(ctx).Debug("JWKS loaded", zap.Int("keys", len(keys)))
+	return nil
+}
+
+// load reads and parses the key set.
+func (j *JWKS) load(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	raw, err := j.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseJWKS(raw)
+}
+
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if j.file != "" {
+		return os.ReadFile(j.file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the signature keys of a JWKS document indexed by key ID.
+// Keys with an unsupported type are skipped.
+func parseJWKS(raw []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			logger.Get().Warn("Skipping JWKS key", zap.String("kid", jwk.Kid), zap.Error(err))
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no usable signature key")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
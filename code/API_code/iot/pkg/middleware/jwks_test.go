@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jwksServer serves the key set of key, or an invalid document once broken is set, counting the requests.
+func jwksServer(t *testing.T, key *rsa.PrivateKey, requests *atomic.Int32, broken *atomic.Bool) *httptest.Server {
+	t.Helper()
+	raw, err := os.ReadFile(writeJWKS(t, key))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// Leave time for concurrent lookups to pile up
+		time.Sleep(20 * time.Millisecond)
+		if broken.Load() {
+			_, _ = w.Write([]byte("not a key set"))
+			return
+		}
+		_, _ = w.Write(raw)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJWKSRefresh(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	t.Run("shares a reload between concurrent lookups", func(t *testing.T) {
+		var requests atomic.Int32
+		var broken atomic.Bool
+		keys, err := NewJWKS("", jwksServer(t, key, &requests, &broken).URL, time.Hour)
+		require.NoError(t, err)
+
+		// Past the minimum interval, an unknown kid reloads the set
+		keys.fetchedAt = time.Now().Add(-time.Minute)
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := keys.Key(context.Background(), "rotated-key")
+				assert.Error(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("backs off after an invalid key set", func(t *testing.T) {
+		var requests atomic.Int32
+		var broken atomic.Bool
+		keys, err := NewJWKS("", jwksServer(t, key, &requests, &broken).URL, time.Hour)
+		require.NoError(t, err)
+
+		broken.Store(true)
+		keys.fetchedAt = time.Now().Add(-time.Hour)
+		for range 3 {
+			_, err := keys.Key(context.Background(), "rotated-key")
+			assert.Error(t, err)
+		}
+
+		// The last known keys are still served
+		assert.Equal(t, int32(2), requests.Load())
+		_, err = keys.Key(context.Background(), testKid)
+		assert.NoError(t, err)
+	})
+}
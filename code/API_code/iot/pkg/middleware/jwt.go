@@ -17,74 +17,123 @@ package middleware
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
+	"crypto"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
 	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
 )
 
 type CtxKey string
 
 const (
-	Sub CtxKey = "sub"
+	Sub    CtxKey = "sub"
+	Scopes CtxKey = "scopes"
 )
 
-func extractSubFromJWT(req *http.Request) (string, int, string) {
-	log := logger.Get()
+// KeySource resolves the public key used to verify a token signature.
+type KeySource interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// JWTConfig configures the JWT middleware.
+type JWTConfig struct {
+	Keys KeySource
+	// Issuer and Audience are checked when not empty.
+	Issuer   string
+	Audience string
+}
+
+// signingMethods lists the accepted asymmetric algorithms; HMAC is rejected as tokens are verified with public keys.
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+var errUnauthenticated = models.ErrorInfo{
+	Status:  http.StatusUnauthorized,
+	Code:    "UNAUTHENTICATED",
+	Message: "Request not authenticated due to missing, invalid, or expired credentials. A new authentication is required.",
+}
+
+func extractClaimsFromJWT(req *http.Request, conf JWTConfig) (string, []string, error) {
 	reqToken := req.Header.Get("Authorization")
 	splitToken := strings.Split(reqToken, "Bearer ")
 	if len(splitToken) != 2 {
-		msg := "invalid Bearer token in Authorization header"
-		log.Error(msg)
-		return "", http.StatusBadRequest, msg
+		return "", nil, errors.New("invalid Bearer token in Authorization header")
 	}
-	reqToken = splitToken[1]
 
-	parts := strings.Split(reqToken, ".")
-	if len(parts) < 2 {
-		msg := "invalid JWT token format"
-		log.Error(msg)
-		return "", http.StatusBadRequest, msg
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+	}
+	if conf.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(conf.Issuer))
+	}
+	if conf.Audience != "" {
+		opts = append(opts, jwt.WithAudience(conf.Audience))
 	}
 
-	payloadSegment := parts[1]
-	payloadBytes, err := base64.RawURLEncoding.DecodeString(payloadSegment)
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(splitToken[1], claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return conf.Keys.Key(req.Context(), kid)
+	}, opts...)
 	if err != nil {
-		msg := "failed to decode JWT payload"
-		log.With(zap.Error(err)).Error(msg)
-		return "", http.StatusBadRequest, msg
+		return "", nil, err
 	}
 
-	var claims map[string]interface{}
-	if err := json.Unmarshal(payloadBytes, &claims); err != nil {
-		msg := "failed to unmarshal JWT payload"
-		log.With(zap.Error(err)).Error(msg)
-		return "", http.StatusBadRequest, msg
+	sub, err := claims.GetSubject()
+	if err != nil || sub == "" {
+		return "", nil, errors.New("sub claim not found in JWT")
 	}
 
-	if sub, ok := claims["sub"].(string); ok {
-		return sub, 0, ""
-	}
+	return sub, scopesFromClaims(claims), nil
+}
 
-	return "", http.StatusBadRequest, "sub claim not found in JWT"
+// scopesFromClaims reads the granted scopes from the space-separated "scope" claim (RFC 8693)
+// or from the "scp" claim used by some authorization servers.
+func scopesFromClaims(claims jwt.MapClaims) []string {
+	if v, ok := claims["scope"].(string); ok {
+		return strings.Fields(v)
+	}
+	switch v := claims["scp"].(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		scopes := make([]string, 0, len(v))
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				scopes = append(scopes, str)
+			}
+		}
+		return scopes
+	}
+	return nil
 }
 
-func JWT() echo.MiddlewareFunc {
+// JWT returns a middleware that verifies the bearer token and stores its sub and scopes in the request context.
+func JWT(conf JWTConfig) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if c.Request().URL.Path == "/healthz" {
 				return next(c)
 			}
-			sub, status, msg := extractSubFromJWT(c.Request())
-			if status != 0 {
-				return c.String(status, msg)
+			sub, scopes, err := extractClaimsFromJWT(c.Request(), conf)
+			if err != nil {
+				logger.FromContext(c.Request().Context()).Warn("JWT validation failed", zap.Error(err))
+				return c.JSON(http.StatusUnauthorized, errUnauthenticated)
 			}
 			ctx := context.WithValue(c.Request().Context(), Sub, sub)
+			ctx = context.WithValue(ctx, Scopes, scopes)
 			c.SetRequest(c.Request().WithContext(ctx))
 			return next(c)
 		}
@@ -98,3 +147,8 @@ func CtxSub(ctx context.Context) string {
 	}
 	return sub
 }
+
+func CtxScopes(ctx context.Context) []string {
+	scopes, _ := ctx.Value(Scopes).([]string)
+	return scopes
+}
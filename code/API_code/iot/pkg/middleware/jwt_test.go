@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -16,20 +16,61 @@ limitations under the License.
 package middleware
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+const testKid = "test-key"
+
+// writeJWKS writes a JWKS file holding the public part of key and returns its path.
+func writeJWKS(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	raw, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+	return path
+}
+
+// makeSignedJWT creates an RS256 JWT with the given claims.
+func makeSignedJWT(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
 // makeUnsignedJWT creates an unsigned JWT with the given claims.
 func makeUnsignedJWT(claims map[string]interface{}) string {
 	header := map[string]interface{}{
-		"some": "header",
+		"alg": "none",
 	}
 	headerBytes, _ := json.Marshal(header)
 	payloadBytes, _ := json.Marshal(claims)
@@ -39,73 +80,161 @@ func makeUnsignedJWT(claims map[string]interface{}) string {
 }
 
 func TestMiddleware(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys, err := NewJWKS(writeJWKS(t, key), "", time.Hour)
+	require.NoError(t, err)
+	conf := JWTConfig{Keys: keys, Issuer: "https://auth.example.com", Audience: "iot-api"}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "tom",
+			"iss":   "https://auth.example.com",
+			"aud":   "iot-api",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "iot-management:power-saving:read iot-management:power-saving:write",
+		}
+	}
+
 	type test struct {
 		name        string
-		claims      map[string]interface{}
 		authHeader  string
 		wantSub     string
+		wantScopes  []string
 		wantErrCode int
 	}
 
+	withClaims := func(mutate func(jwt.MapClaims)) string {
+		claims := validClaims()
+		mutate(claims)
+		return "Bearer " + makeSignedJWT(t, key, claims)
+	}
+
 	tests := []test{
 		{
-			name: "sets sub on context when present in the claim",
-			claims: map[string]interface{}{
-				"sub": "tom",
-				"iat": 1234567890,
-			},
-			wantSub: "tom",
+			name:       "sets sub and scopes on context for a valid token",
+			authHeader: "Bearer " + makeSignedJWT(t, key, validClaims()),
+			wantSub:    "tom",
+			wantScopes: []string{"iot-management:power-saving:read", "iot-management:power-saving:write"},
 		},
 		{
 			name:        "fails when sub claim is missing",
-			claims:      map[string]interface{}{},
-			wantErrCode: http.StatusBadRequest,
+			authHeader:  withClaims(func(c jwt.MapClaims) { delete(c, "sub") }),
+			wantErrCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "fails when the token is expired",
+			authHeader:  withClaims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }),
+			wantErrCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "fails when the issuer does not match",
+			authHeader:  withClaims(func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }),
+			wantErrCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "fails when the audience does not match",
+			authHeader:  withClaims(func(c jwt.MapClaims) { c["aud"] = "other-api" }),
+			wantErrCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "fails when signed with an unknown key",
+			authHeader:  "Bearer " + makeSignedJWT(t, otherKey, validClaims()),
+			wantErrCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "fails when the token is unsigned",
+			authHeader:  "Bearer " + makeUnsignedJWT(validClaims()),
+			wantErrCode: http.StatusUnauthorized,
 		},
 		{
 			name:        "fails when the auth header is invalid",
 			authHeader:  "invalid",
-			wantErrCode: http.StatusBadRequest,
+			wantErrCode: http.StatusUnauthorized,
 		},
 		{
 			name:        "fails when JWT format is invalid",
 			authHeader:  "Bearer invalid.token.parts",
-			wantErrCode: http.StatusBadRequest,
-		},
-		{
-			name:        "fails when base64 payload is invalid",
-			authHeader:  "Bearer a.b.c",
-			wantErrCode: http.StatusBadRequest,
+			wantErrCode: http.StatusUnauthorized,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			authHeader := tt.authHeader
-			if authHeader == "" && tt.claims != nil {
-				tokenString := makeUnsignedJWT(tt.claims)
-				authHeader = "Bearer " + tokenString
-			}
-
 			e := echo.New()
 			var gotSub string
+			var gotScopes []string
 			h := func(c echo.Context) error {
 				gotSub = CtxSub(c.Request().Context())
+				gotScopes = CtxScopes(c.Request().Context())
 				return c.NoContent(http.StatusOK)
 			}
-			handler := JWT()(h)
+			handler := JWT(conf)(h)
 			e.POST("/test", handler)
 
 			req := httptest.NewRequest("POST", "/test", nil)
-			req.Header.Set("Authorization", authHeader)
+			req.Header.Set("Authorization", tt.authHeader)
 			rec := httptest.NewRecorder()
 
 			e.ServeHTTP(rec, req)
 
 			if tt.wantErrCode != 0 {
 				assert.Equal(t, tt.wantErrCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), "UNAUTHENTICATED")
 			} else {
 				assert.Equal(t, http.StatusOK, rec.Code)
 				assert.Equal(t, tt.wantSub, gotSub)
+				assert.Equal(t, tt.wantScopes, gotScopes)
+			}
+		})
+	}
+}
+
+func TestOAuth2Scopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required []string
+		wantErr  bool
+	}{
+		{
+			name:     "all required scopes granted",
+			granted:  []string{"iot-management:power-saving:read", "iot-management:power-saving:write"},
+			required: []string{"iot-management:power-saving:write"},
+		},
+		{
+			name:     "required scope missing",
+			granted:  []string{"iot-management:power-saving:read"},
+			required: []string{"iot-management:power-saving:write"},
+			wantErr:  true,
+		},
+		{
+			name:     "no scopes granted",
+			required: []string{"iot-management:power-saving:read"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req = req.WithContext(context.WithValue(req.Context(), Scopes, tt.granted))
+			input := &openapi3filter.AuthenticationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req},
+				SecuritySchemeName:     "oAuth2",
+				Scopes:                 tt.required,
+			}
+
+			err := OAuth2Scopes(req.Context(), input)
+			if tt.wantErr {
+				var httpErr *echo.HTTPError
+				require.ErrorAs(t, err, &httpErr)
+				assert.Equal(t, http.StatusForbidden, httpErr.Code)
+			} else {
+				assert.NoError(t, err)
 			}
 		})
 	}
@@ -28,7 +28,6 @@ import (
 // ZapLogger returns an Echo middleware that logs basic request information
 // (method, URI, status, user-agent, latency, and errors) using Zap.
 func ZapLogger() echo.MiddlewareFunc {
-	log := logger.Get()
 	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogMethod:    true,
 		LogURI:       true,
@@ -51,7 +50,7 @@ func ZapLogger() echo.MiddlewareFunc {
 			if values.Error != nil {
 				fields = append(fields, zap.Error(values.Error))
 			}
-			log.Debug("request", fields...)
+			logger.FromContext(c.Request().Context()).Debug("request", fields...)
 			return nil
 		},
 	},
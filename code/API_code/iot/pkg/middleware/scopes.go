@@ -0,0 +1,51 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/api/models"
+	"github.com/camaraproject/IoTNetworkOptimization_PI/code/API_code/iot/pkg/logger"
+)
+
+var errPermissionDenied = models.ErrorInfo{
+	Status:  http.StatusForbidden,
+	Code:    "PERMISSION_DENIED",
+	Message: "Client does not have sufficient permissions to perform this action.",
+}
+
+// OAuth2Scopes is an openapi3filter.AuthenticationFunc enforcing the OAuth2 scopes declared in the
+// OpenAPI security section against the scopes granted to the token verified by JWT.
+// The returned echo.HTTPError is rendered as the CAMARA 403 error body.
+func OAuth2Scopes(_ context.Context, input *openapi3filter.AuthenticationInput) error {
+	granted := CtxScopes(input.RequestValidationInput.Request.Context())
+	for _, scope := range input.Scopes {
+		if !slices.Contains(granted, scope) {
+			logger.FromContext(input.RequestValidationInput.Request.Context()).Warn("Missing OAuth2 scope",
+				zap.String("scheme", input.SecuritySchemeName),
+				zap.String("scope", scope))
+			return echo.NewHTTPError(http.StatusForbidden, errPermissionDenied)
+		}
+	}
+	return nil
+}
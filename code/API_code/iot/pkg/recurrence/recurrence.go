@@ -0,0 +1,203 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package recurrence
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // IANA time zones are resolved without relying on the container image
+)
+
+// searchHorizon bounds the search for the next occurrence, so that schedules that never match
+// (e.g. February 30th) are reported instead of looping forever.
+const searchHorizon = 5 * 366 // days
+
+// Rule is a recurrence: occurrences start at the times matching a cron schedule in a time zone
+// and last a number of minutes of wall-clock time in that time zone.
+type Rule struct {
+	minutes  []int
+	hours    []int
+	days     []bool // indexed by day of month
+	months   []bool // indexed by month
+	weekdays []bool // indexed by time.Weekday
+	// Standard cron semantics: when both day of month and day of week are restricted, a day
+	// matching either of them matches
+	anyDay      bool
+	location    *time.Location
+	durationMin int
+}
+
+// Parse builds a Rule from a standard 5-field cron expression (minute, hour, day of month, month,
+// day of week), an IANA time zone name and the duration of each occurrence in minutes.
+func Parse(schedule string, timeZone string, durationMinutes int) (*Rule, error) {
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule must have 5 fields, got %d", len(fields))
+	}
+	if durationMinutes <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil || timeZone == "" || timeZone == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", timeZone)
+	}
+
+	minutes, err := parseField(fields[0], 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	hours, err := parseField(fields[1], 0, 23)
+	if err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	days, err := parseField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	months, err := parseField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	// Both 0 and 7 stand for Sunday
+	weekdays, err := parseField(fields[4], 0, 7)
+	if err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	for i, day := range weekdays {
+		if day == 7 {
+			weekdays[i] = 0
+		}
+	}
+
+	return &Rule{
+		minutes:     minutes,
+		hours:       hours,
+		days:        toSet(days, 31),
+		months:      toSet(months, 12),
+		weekdays:    toSet(weekdays, 6),
+		anyDay:      !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*"),
+		location:    location,
+		durationMin: durationMinutes,
+	}, nil
+}
+
+// Next returns the first occurrence starting at or after from. The end of the occurrence is computed
+// on the wall clock of the time zone, so a 22:00 to 06:00 occurrence keeps its local times across
+// daylight saving changes. Start times falling in a daylight saving gap are moved forward by the
+// length of the gap, and those repeated when clocks go back occur once. It returns false when
+// the schedule has no occurrence in the next five years.
+func (r *Rule) Next(from time.Time) (start time.Time, end time.Time, ok bool) {
+	local := from.In(r.location)
+	year, month, day := local.Date()
+
+	for i := 0; i <= searchHorizon; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, r.location)
+		if !r.matchesDay(date) {
+			continue
+		}
+		// Times moved by a daylight saving change are not in order, pick the earliest
+		for _, hour := range r.hours {
+			for _, minute := range r.minutes {
+				candidate := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, r.location)
+				if !candidate.Before(from) && (start.IsZero() || candidate.Before(start)) {
+					start = candidate
+				}
+			}
+		}
+		if !start.IsZero() {
+			wall := start.In(r.location)
+			end = time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute()+r.durationMin, 0, 0, r.location)
+			return start, end, true
+		}
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// matchesDay reports whether occurrences can start on the given day.
+func (r *Rule) matchesDay(date time.Time) bool {
+	if !r.months[date.Month()] {
+		return false
+	}
+	dayMatch := r.days[date.Day()]
+	weekdayMatch := r.weekdays[date.Weekday()]
+	if r.anyDay {
+		return dayMatch || weekdayMatch
+	}
+	return dayMatch && weekdayMatch
+}
+
+// parseField parses a comma-separated list of values, ranges (a-b), wildcards (*) and steps (/n)
+// into the sorted list of values it matches.
+func parseField(field string, lowest, highest int) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(field, ",") {
+		expr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			expr = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		low, high := lowest, highest
+		switch {
+		case expr == "*":
+		case strings.Contains(expr, "-"):
+			bounds := strings.SplitN(expr, "-", 2)
+			var err1, err2 error
+			low, err1 = strconv.Atoi(bounds[0])
+			high, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid range %q", expr)
+			}
+		default:
+			value, err := strconv.Atoi(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", expr)
+			}
+			low = value
+			// A single value with a step (e.g. 5/15) runs up to the maximum
+			high = value
+			if step > 1 {
+				high = highest
+			}
+		}
+		if low < lowest || high > highest || low > high {
+			return nil, fmt.Errorf("%q out of range %d-%d", expr, lowest, highest)
+		}
+
+		for v := low; v <= high; v += step {
+			values = append(values, v)
+		}
+	}
+
+	slices.Sort(values)
+	return slices.Compact(values), nil
+}
+
+func toSet(values []int, highest int) []bool {
+	set := make([]bool, highest+1)
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (C) 2022-2025 Contributors | TIM S.p.A. to CAMARA a Series of LF Projects, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		timeZone string
+		duration int
+		wantErr  bool
+	}{
+		{name: "nightly", schedule: "0 22 * * *", timeZone: "Europe/Rome", duration: 480},
+		{name: "lists, ranges and steps", schedule: "*/15 8-18 1,15 1-12/2 1-5", timeZone: "UTC", duration: 10},
+		{name: "sunday as 7", schedule: "0 0 * * 7", timeZone: "UTC", duration: 60},
+		{name: "too few fields", schedule: "0 22 * *", timeZone: "UTC", duration: 60, wantErr: true},
+		{name: "minute out of range", schedule: "60 22 * * *", timeZone: "UTC", duration: 60, wantErr: true},
+		{name: "inverted range", schedule: "0 18-8 * * *", timeZone: "UTC", duration: 60, wantErr: true},
+		{name: "zero step", schedule: "*/0 * * * *", timeZone: "UTC", duration: 60, wantErr: true},
+		{name: "unknown time zone", schedule: "0 22 * * *", timeZone: "Mars/Olympus", duration: 60, wantErr: true},
+		{name: "empty time zone", schedule: "0 22 * * *", timeZone: "", duration: 60, wantErr: true},
+		{name: "zero duration", schedule: "0 22 * * *", timeZone: "UTC", duration: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.schedule, tt.timeZone, tt.duration)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedule  string
+		timeZone  string
+		duration  int
+		from      time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "nightly window in winter time",
+			schedule:  "0 22 * * *",
+			timeZone:  "Europe/Rome",
+			duration:  480,
+			from:      time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 1, 10, 21, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, 1, 11, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "from an exact occurrence",
+			schedule:  "0 22 * * *",
+			timeZone:  "Europe/Rome",
+			duration:  480,
+			from:      time.Date(2025, 1, 10, 21, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 1, 10, 21, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, 1, 11, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "nightly window shortened when clocks go forward",
+			schedule:  "0 22 * * *",
+			timeZone:  "Europe/Rome",
+			duration:  480,
+			from:      time.Date(2025, 3, 29, 12, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 3, 29, 21, 0, 0, 0, time.UTC), // 22:00 CET
+			wantEnd:   time.Date(2025, 3, 30, 4, 0, 0, 0, time.UTC),  // 06:00 CEST
+		},
+		{
+			name:      "nightly window lengthened when clocks go back",
+			schedule:  "0 22 * * *",
+			timeZone:  "Europe/Rome",
+			duration:  480,
+			from:      time.Date(2025, 10, 25, 12, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 10, 25, 20, 0, 0, 0, time.UTC), // 22:00 CEST
+			wantEnd:   time.Date(2025, 10, 26, 5, 0, 0, 0, time.UTC),  // 06:00 CET
+		},
+		{
+			name:      "start in the daylight saving gap is moved forward",
+			schedule:  "30 2 * * *",
+			timeZone:  "Europe/Rome",
+			duration:  60,
+			from:      time.Date(2025, 3, 29, 12, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 3, 30, 1, 30, 0, 0, time.UTC), // 03:30 CEST
+			wantEnd:   time.Date(2025, 3, 30, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "weekdays only",
+			schedule:  "0 9 * * 1-5",
+			timeZone:  "UTC",
+			duration:  60,
+			from:      time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), // Saturday
+			wantStart: time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "day of month or day of week",
+			schedule:  "0 0 15 * 1",
+			timeZone:  "UTC",
+			duration:  60,
+			from:      time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), // Tuesday
+			wantStart: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, 3, 15, 1, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.schedule, tt.timeZone, tt.duration)
+			require.NoError(t, err)
+
+			start, end, ok := rule.Next(tt.from)
+			require.True(t, ok)
+			assert.True(t, tt.wantStart.Equal(start), "start: want %s, got %s", tt.wantStart, start)
+			assert.True(t, tt.wantEnd.Equal(end), "end: want %s, got %s", tt.wantEnd, end)
+		})
+	}
+}
+
+func TestNextWithoutOccurrence(t *testing.T) {
+	rule, err := Parse("0 0 30 2 *", "UTC", 60)
+	require.NoError(t, err)
+
+	_, _, ok := rule.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+}